@@ -58,13 +58,13 @@ func runAgentContext(cmd *cobra.Command) error {
 		return err
 	}
 
-	switch outputFormat {
-	case "json", "yaml":
+	switch {
+	case !isTableOutput():
 		payload, err := adpt.JsonPayloadFromAny(doc, logger)
 		if err != nil {
 			return err
 		}
-		return adpt.ReplyPrinter(payload, outputFormat == "yaml")
+		return printReply(payload)
 	default:
 		if _, err := fmt.Fprint(os.Stdout, doc.Content); err != nil {
 			return err
@@ -124,23 +124,15 @@ var (
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			req := createListRequest()
-			if res, err := sdk.ListArtifactsRaw(context.Background(), req, CreateAdapter(true), logger); err == nil {
-				switch outputFormat {
-				case "json":
-					return a.ReplyPrinter(res, false)
-				case "yaml":
-					return a.ReplyPrinter(res, true)
-				default:
-					var list api.ListResponseBody
-					if err = res.AsType(&list); err != nil {
-						return fmt.Errorf("failed to parse response body: %w", err)
-					}
-					printArtifactTable(&list, false)
-				}
-				return nil
-			} else {
-				return err
-			}
+			adapter := CreateAdapter(true)
+			return printList(
+				func(ctxt context.Context) (a.Payload, error) {
+					return sdk.ListArtifactsRaw(ctxt, req, adapter, logger)
+				},
+				adapter,
+				func(list *api.ListResponseBody) *[]*api.ArtifactListItemResponseBody { return &list.Items },
+				func(list *api.ListResponseBody, next *string) { printArtifactTable(list, next, isWideOutput()) },
+			)
 		},
 	}
 
@@ -154,18 +146,18 @@ var (
 			req := &sdk.ReadArtifactRequest{Id: recordID}
 			adapter := CreateAdapter(true)
 
-			switch outputFormat {
-			case "json", "yaml":
-				res, err := sdk.ReadArtifactRaw(context.Background(), req, adapter, logger)
+			switch {
+			case !isTableOutput():
+				res, err := sdk.ReadArtifactRaw(RootContext(), req, adapter, logger)
 				if err != nil {
 					return err
 				}
-				return a.ReplyPrinter(res, outputFormat == "yaml")
+				return printReply(res)
 			default:
-				if artifact, err := sdk.ReadArtifact(context.Background(), req, adapter, logger); err == nil {
+				if artifact, err := sdk.ReadArtifact(RootContext(), req, adapter, logger); err == nil {
 					selector := sdk.AspectSelector{Entity: recordID}
-					if meta, _, err := sdk.ListAspect(context.Background(), selector, adapter, logger); err == nil {
-						printArtifact(artifact, meta, false)
+					if meta, _, err := sdk.ListAspect(RootContext(), selector, adapter, logger); err == nil {
+						printArtifact(artifact, meta, isWideOutput())
 					} else {
 						return err
 					}
@@ -204,7 +196,7 @@ var (
 			reader, contentType, size := getReader(fileName, contentType)
 			logger.Debug("upload artifact", log.String("content-type", contentType), log.String("file", fileName))
 			adapter := CreateAdapter(true)
-			ctxt := context.Background()
+			ctxt := RootContext()
 
 			offset := int64(0)
 
@@ -271,11 +263,11 @@ var (
 	// 				if m, err := res.AsObject(); err == nil {
 	// 					fmt.Printf("%s\n", m["record-id"])
 	// 				} else {
-	// 					cobra.CheckErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
+	// 					checkErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
 	// 				}
 	// 			} else {
 	// 				if err = a.ReplyPrinter(res, outputFormat == "yaml"); err != nil {
-	// 					cobra.CheckErr("print reply")
+	// 					checkErr("print reply")
 	// 					return
 	// 				}
 	// 			}
@@ -385,10 +377,10 @@ func uploadArtifact(
 		Collection: artifactCollection,
 		Policy:     policy,
 	}
-	ctxt := context.Background()
+	ctxt := RootContext()
 	resp, err := sdk.CreateArtifact(ctxt, req, contentType, size, nil, adapter, logger)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("while creating record for '%s'- %v", fileName, err))
+		checkErr(fmt.Errorf("while creating record for '%s'- %w", fileName, err))
 		return
 	}
 	artifactID = *resp.ID
@@ -397,11 +389,11 @@ func uploadArtifact(
 	}
 	path, err := (*adapter).GetPath(*resp.DataHref)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("while parsing API reply - %v", err))
+		checkErr(fmt.Sprintf("while parsing API reply - %v", err))
 		return
 	}
 	if err = upload(ctxt, reader, artifactID, path, size, 0, adapter); err != nil {
-		cobra.CheckErr(fmt.Sprintf("while upload - %v", err))
+		checkErr(fmt.Errorf("while upload - %w", err))
 		return
 	}
 	if silent {
@@ -412,7 +404,7 @@ func uploadArtifact(
 		m := fmt.Sprintf("%s|%s", fileHash, artifactID)
 		err = os.WriteFile(*metaFile, []byte(m), 0644) // #nosec G306 -- only includes the artifact ID
 		if err != nil {
-			cobra.CheckErr(fmt.Sprintf("saving information to metafile '%s' failed - %v", *metaFile, err))
+			checkErr(fmt.Sprintf("saving information to metafile '%s' failed - %v", *metaFile, err))
 		}
 	}
 	return
@@ -429,6 +421,9 @@ func upload(
 ) (err error) {
 	if err = sdk.UploadArtifact(ctxt, reader, size, offset, chunkSize, path, adapter, silent, logger); err != nil {
 		cobra.CompErrorln(fmt.Sprintf("while uploading data file '%s' - %v", fileName, err))
+		if ctxt.Err() != nil && fileName != "-" {
+			fmt.Fprintf(os.Stderr, "Upload interrupted. Resume with 'ivcap artifact upload %s -f %s'\n", artifactID, fileName)
+		}
 		return
 	}
 	if silent {
@@ -437,17 +432,17 @@ func upload(
 	fmt.Printf("Completed uploading '%s'\n", artifactID)
 	readReq := &sdk.ReadArtifactRequest{Id: artifactID}
 
-	switch outputFormat {
-	case "json", "yaml":
+	switch {
+	case !isTableOutput():
 		res, err := sdk.ReadArtifactRaw(ctxt, readReq, adapter, logger)
 		if err != nil {
 			return err
 		}
-		return a.ReplyPrinter(res, outputFormat == "yaml")
+		return printReply(res)
 	default:
 		var readResp *api.ReadResponseBody
 		if readResp, err = sdk.ReadArtifact(ctxt, readReq, adapter, logger); err == nil {
-			printArtifact(readResp, nil, false)
+			printArtifact(readResp, nil, isWideOutput())
 		} else {
 			cobra.CompErrorln(fmt.Sprintf("while getting a status update on '%s' - %v", artifactID, err))
 			return
@@ -460,14 +455,14 @@ func downloadArtifact(cmd *cobra.Command, args []string) error {
 	recordID := GetHistory(args[0])
 	req := &sdk.ReadArtifactRequest{Id: recordID}
 	adapter := CreateAdapter(true)
-	ctxt := context.Background()
+	ctxt := RootContext()
 	artifact, err := sdk.ReadArtifact(ctxt, req, adapter, logger)
 	if err != nil {
 		return err
 	}
 	data := artifact.DataHref
 	if data == nil { // } || data.Self == nil {
-		cobra.CheckErr("No data available")
+		checkErr("No data available")
 		return nil // should never get here, but linter complaints otherwise
 	}
 	url, err := url.ParseRequestURI(*data)
@@ -507,16 +502,27 @@ func downloadArtifact(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func printArtifactTable(list *api.ListResponseBody, wide bool) {
+func printArtifactTable(list *api.ListResponseBody, next *string, wide bool) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"ID", "Name", "Status", "Size", "MimeType"})
+	header := table.Row{"ID", "Name", "Status", "Size", "MimeType"}
+	if wide {
+		header = append(header, "Created At", "URN")
+	}
+	t.AppendHeader(header)
 	rows := make([]table.Row, len(list.Items))
 	for i, o := range list.Items {
-		rows[i] = table.Row{MakeHistory(o.ID), safeTruncString(o.Name), safeString(o.Status),
+		name := safeTruncString(o.Name)
+		if wide {
+			name = safeString(o.Name)
+		}
+		rows[i] = table.Row{MakeHistory(o.ID), name, safeString(o.Status),
 			safeBytes(o.Size), safeString(o.MimeType)}
+		if wide {
+			rows[i] = append(rows[i], safeDate(o.CreatedAt, false), safeString(o.ID))
+		}
 	}
-	rows = addNextPageRow(findNextArtifactPage(list.Links), rows)
+	rows = addNextPageRow(next, rows)
 	t.AppendRows(rows)
 	t.Render()
 }
@@ -555,7 +561,7 @@ func printArtifact(artifact *api.ReadResponseBody, meta *asapi.ListResponseBody,
 
 func getReader(fileName string, proposedFormat string) (reader io.Reader, format string, size int64) {
 	if fileName == "" {
-		cobra.CheckErr("Missing file name '-f'")
+		checkErr("Missing file name '-f'")
 	}
 	format = proposedFormat
 	var file *os.File
@@ -565,19 +571,19 @@ func getReader(fileName string, proposedFormat string) (reader io.Reader, format
 		file = os.Stdin
 	} else {
 		if file, err = os.Open(filepath.Clean(fileName)); err != nil {
-			cobra.CheckErr(fmt.Sprintf("while opening data file '%s' - %v", fileName, err))
+			checkErr(fmt.Sprintf("while opening data file '%s' - %v", fileName, err))
 		}
 		if info, err := file.Stat(); err == nil {
 			size = info.Size()
 		}
 		if proposedFormat == "" {
 			if format, err = getFileContentType(file); err != nil {
-				cobra.CheckErr(fmt.Sprintf("while checking content type of file '%s' - %v", fileName, err))
+				checkErr(fmt.Sprintf("while checking content type of file '%s' - %v", fileName, err))
 			}
 		}
 	}
 	if format == "" {
-		cobra.CheckErr("Missing content type [-t]")
+		checkErr("Missing content type [-t]")
 	}
 	reader = bufio.NewReader(file)
 	return
@@ -601,18 +607,6 @@ func getFileContentType(file *os.File) (contentType string, err error) {
 	return
 }
 
-func findNextArtifactPage(links []*api.LinkTResponseBody) *string {
-	if links == nil {
-		return nil
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return l.Href
-		}
-	}
-	return nil
-}
-
 func getArtifactMetaFileFor(fileName string) (fnp *string, fileExists bool) {
 	if fileName == "-" {
 		// from pipe, so don't know source
@@ -621,7 +615,7 @@ func getArtifactMetaFileFor(fileName string) (fnp *string, fileExists bool) {
 	var afn string
 	var err error
 	if afn, err = filepath.Abs(fileName); err != nil {
-		cobra.CheckErr(fmt.Sprintf("Can't obtain absolute path of '%s' - %v", fileName, err))
+		checkErr(fmt.Sprintf("Can't obtain absolute path of '%s' - %v", fileName, err))
 	}
 	fdir := filepath.Dir(afn)
 	fn := filepath.Join(fdir, fmt.Sprintf(".ivcap-%s.txt", filepath.Base(afn)))
@@ -653,14 +647,14 @@ func getArtifactIdFromMeta(fileName string, fileHash string) *string {
 func getFileHash(fileName string) string {
 	file, err := os.Open(fileName) // #nosec G304
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("while opening data file '%s' - %v", fileName, err))
+		checkErr(fmt.Sprintf("while opening data file '%s' - %v", fileName, err))
 		// never get here as cobra.CheckErr calls os.Exit
 	}
 	defer func() { _ = file.Close() }()
 
 	hash := md5.New() // #nosec G401
 	if _, err = io.Copy(hash, file); err != nil {
-		cobra.CheckErr(fmt.Sprintf("while reading data file '%s' - %v", fileName, err))
+		checkErr(fmt.Sprintf("while reading data file '%s' - %v", fileName, err))
 		// never get here as cobra.CheckErr calls os.Exit
 	}
 	sum := hash.Sum(nil)
@@ -0,0 +1,57 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	adpt "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(clearCacheCmd)
+}
+
+var (
+	cacheCmd = &cobra.Command{
+		Use:     "cache",
+		Short:   "Manage the cache of API replies",
+		GroupID: generalSupportGroupID,
+		Long: `Commands which repeatedly read the same, rarely changing resources (e.g.
+'service get', or the service names shown by 'order list') keep the
+replies in a cache below the config directory. Cached replies are
+revalidated with the deployment through their ETag, unless the deployment
+declared them fresh for a while. Use '--no-cache' to bypass the cache for
+a single command.`,
+	}
+
+	clearCacheCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Remove all cached replies, or only those of the context set with '--context'",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := getCacheDir(contextName)
+			if err := adpt.NewCache(dir).Clear(); err != nil {
+				return fmt.Errorf("cannot clear cache '%s' - %w", dir, err)
+			}
+			if !silent {
+				fmt.Printf("Cleared cache '%s'\n", dir)
+			}
+			return nil
+		},
+	}
+)
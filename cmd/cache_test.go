@@ -0,0 +1,59 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	log "go.uber.org/zap"
+)
+
+func TestHTTPCache_PerSubject(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Cache-Control", "max-age=3600")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	SetContext(&Context{Name: "dev", URL: srv.URL}, false)
+	contextName = "dev"
+	t.Cleanup(func() { contextName, accessToken = "", "" })
+
+	get := func(subject string) {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{Subject: subject})
+		accessToken, _ = token.SignedString([]byte("secret"))
+		if _, err := (*CreateCachedAdapter(true)).Get(RootContext(), "/1/services2/s1", logger); err != nil {
+			t.Fatal(err)
+		}
+	}
+	get("alice")
+	get("alice")
+	if calls != 1 {
+		t.Fatalf("expected second request to be served from cache, got %d calls", calls)
+	}
+	get("bob")
+	if calls != 2 {
+		t.Errorf("expected cached reply of another subject to be ignored, got %d calls", calls)
+	}
+}
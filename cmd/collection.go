@@ -96,18 +96,17 @@ var (
 				ListRequest:    *createListRequest(),
 				IncludeContent: false,
 			}
-			ctxt := context.Background()
-			if list, res, err := sdk.ListAspect(ctxt, selector, CreateAdapter(true), logger); err == nil {
-				switch outputFormat {
-				case "json", "yaml":
-					return a.ReplyPrinter(res, outputFormat == "yaml")
-				default:
-					printCollectionTable(list, false)
-				}
-				return nil
-			} else {
-				return err
-			}
+			adapter := CreateAdapter(true)
+			getPrinter().IDFunc = collectionRecordID
+			return printList(
+				func(ctxt context.Context) (a.Payload, error) {
+					_, res, err := sdk.ListAspect(ctxt, selector, adapter, logger)
+					return res, err
+				},
+				adapter,
+				func(list *api.ListResponseBody) *[]*api.AspectListItemRTResponseBody { return &list.Items },
+				func(list *api.ListResponseBody, next *string) { printCollectionTable(list, next, isWideOutput()) },
+			)
 		},
 	}
 
@@ -118,22 +117,22 @@ var (
 		Run: func(cmd *cobra.Command, args []string) {
 			id := GetHistory(args[0])
 			if !URN_CHECK.Match([]byte(id)) {
-				cobra.CheckErr(fmt.Sprintf("'%s' is not a URN", id))
+				checkErr(fmt.Sprintf("'%s' is not a URN", id))
 			}
 			if collectionDir == "" {
-				cobra.CheckErr("Missing '--dir' flag")
+				checkErr("Missing '--dir' flag")
 				return
 			}
 			entries, err := os.ReadDir(collectionDir)
 			if err != nil {
-				cobra.CheckErr(fmt.Sprintf("While reading directory '%s'", collectionDir))
+				checkErr(fmt.Sprintf("While reading directory '%s'", collectionDir))
 				return
 			}
 			id2name := make(map[string]string)
 			var aids []string
 			addAID := func(name string, aid string) {
 				if other, ok := id2name[aid]; ok {
-					cobra.CheckErr(fmt.Sprintf("'%s' is apparently uploaded with same URN as '%s'", name, other))
+					checkErr(fmt.Sprintf("'%s' is apparently uploaded with same URN as '%s'", name, other))
 				}
 				id2name[aid] = name
 				aids = append(aids, aid)
@@ -161,16 +160,16 @@ var (
 			}
 			var cb []byte
 			if cb, err = json.Marshal(content); err != nil {
-				cobra.CheckErr(fmt.Sprintf("while marshalling collection list - %v", err))
+				checkErr(fmt.Sprintf("while marshalling collection list - %v", err))
 			}
-			ctxt := context.Background()
+			ctxt := RootContext()
 			_, err = sdk.AddUpdateAspect(ctxt, false, id, CollectionSchema, policy, cb, CreateAdapter(true), logger)
 			if err != nil {
-				cobra.CheckErr(fmt.Sprintf("while creating/updating collection list - %v", err))
+				checkErr(fmt.Errorf("while creating/updating collection list - %w", err))
 			}
 			if !silent {
 				if err := getCollection(id); err != nil {
-					cobra.CheckErr(fmt.Sprintf("while printing collection details - %v", err))
+					checkErr(fmt.Sprintf("while printing collection details - %v", err))
 				}
 			}
 		},
@@ -230,7 +229,7 @@ var (
 // 		// Long:    `.....`,
 // 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 // 			if entityURN == "" && schemaPrefix == "" && page == "" {
-// 				cobra.CheckErr("Need at least one of '--schema', '--entity' or '--page'")
+// 				checkErr("Need at least one of '--schema', '--entity' or '--page'")
 // 			}
 // 			if entityURN != "" {
 // 				entityURN = GetHistory(entityURN)
@@ -276,25 +275,25 @@ func getCollection(collectionID string) (err error) {
 	if atTime != "" {
 		t, err := dateparse.ParseLocal(atTime)
 		if err != nil {
-			cobra.CheckErr(fmt.Sprintf("Can't parse '%s' into a date - %s", atTime, err))
+			checkErr(fmt.Sprintf("Can't parse '%s' into a date - %s", atTime, err))
 		}
 		selector.AtTime = &t
 	}
 
-	ctxt := context.Background()
+	ctxt := RootContext()
 	adapter := CreateAdapter(true)
 	var list *api.ListResponseBody
 	if list, _, err = sdk.ListAspect(ctxt, selector, adapter, logger); err != nil {
 		return
 	}
 	if len(list.Items) != 1 {
-		cobra.CheckErr("API Error: Check deployment - Collection is not well defined")
+		checkErr("API Error: Check deployment - Collection is not well defined")
 	}
 	aspectID := list.Items[0].ID
-	switch outputFormat {
-	case "json", "yaml":
+	switch {
+	case !isTableOutput():
 		if res, err := sdk.GetAspectRaw(ctxt, *aspectID, adapter, logger); err == nil {
-			return a.ReplyPrinter(res, outputFormat == "yaml")
+			return printReply(res)
 		} else {
 			return err
 		}
@@ -312,12 +311,12 @@ func getCollection(collectionID string) (err error) {
 // 	entity := args[0]
 // 	pyld, err := payloadFromFile(collectionFile, inputFormat)
 // 	if err != nil {
-// 		cobra.CheckErr(fmt.Sprintf("While reading collection file '%s' - %s", collectionFile, err))
+// 		checkErr(fmt.Sprintf("While reading collection file '%s' - %s", collectionFile, err))
 // 	}
 
 // 	collection, err := pyld.AsObject()
 // 	if err != nil {
-// 		cobra.CheckErr(fmt.Sprintf("Cannot parse collection file '%s' - %s", collectionFile, err))
+// 		checkErr(fmt.Sprintf("Cannot parse collection file '%s' - %s", collectionFile, err))
 // 	}
 // 	var schema string
 // 	schema = schemaURN
@@ -325,7 +324,7 @@ func getCollection(collectionID string) (err error) {
 // 		if s, ok := collection["$schema"]; ok {
 // 			schema = fmt.Sprintf("%s", s)
 // 		} else {
-// 			cobra.CheckErr("Missing schema name")
+// 			checkErr("Missing schema name")
 // 		}
 // 	}
 // 	logger.Debug("add/update collection", log.String("entity", entity), log.String("schema", schema), log.Reflect("pyld", collection))
@@ -338,7 +337,7 @@ func getCollection(collectionID string) (err error) {
 // 		if m, err := res.AsObject(); err == nil {
 // 			fmt.Printf("%s\n", m["record-id"])
 // 		} else {
-// 			cobra.CheckErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
+// 			checkErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
 // 		}
 // 	} else {
 // 		return a.ReplyPrinter(res, outputFormat == "yaml")
@@ -358,16 +357,16 @@ func printCollection(res *api.ReadResponseBody) {
 	// Retracter *string              `form:"retracter,omitempty"
 
 	if res.ContentType == nil || *res.ContentType != "application/json" {
-		cobra.CheckErr("Cannot find collection member list in reply - should never happen")
+		checkErr("Cannot find collection member list in reply - should never happen")
 	}
 	var cm map[string]any
 	var ok bool
 	if cm, ok = res.Content.(map[string]any); !ok {
-		cobra.CheckErr("Unexpected content type")
+		checkErr("Unexpected content type")
 	}
 	var list []any
 	if list, ok = cm["artifacts"].([]any); !ok {
-		cobra.CheckErr("Unexpected missing content - 'artifacts'")
+		checkErr("Unexpected missing content - 'artifacts'")
 	}
 	tw2 := table.NewWriter()
 	tw2.AppendHeader(table.Row{fmt.Sprintf("Artifacts (%d)", len(list))})
@@ -412,7 +411,18 @@ func printCollection(res *api.ReadResponseBody) {
 	fmt.Printf("\n%s\n\n", tw.Render())
 }
 
-func printCollectionTable(list *api.ListResponseBody, wide bool) {
+// collectionRecordID returns the collection ID of a collection aspect for
+// '--output ids'
+func collectionRecordID(record any) string {
+	if r, ok := record.(map[string]any); ok {
+		if id, ok := r["entity"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+func printCollectionTable(list *api.ListResponseBody, next *string, wide bool) {
 	tw2 := table.NewWriter()
 	tw2.AppendHeader(table.Row{"ID", "Last Updated"})
 	tw2.SetStyle(table.StyleLight)
@@ -440,20 +450,8 @@ func printCollectionTable(list *api.ListResponseBody, wide bool) {
 		p = append(p, table.Row{"At Time", safeDate(list.AtTime, false)})
 	}
 	p = append(p, table.Row{"Collections", tw2.Render()})
-	p = addNextPageRow(findNextCollectionPage(list.Links), p)
+	p = addNextPageRow(next, p)
 	tw.AppendRows(p)
 
 	fmt.Printf("\n%s\n\n", tw.Render())
 }
-
-func findNextCollectionPage(links []*api.LinkTResponseBody) *string {
-	if links == nil {
-		return nil
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return l.Href
-		}
-	}
-	return nil
-}
@@ -21,7 +21,6 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/araddon/dateparse"
@@ -34,7 +33,6 @@ import (
 // Names for config dir and file - stored in the os.UserConfigDir() directory
 const CONFIG_FILE_DIR = "ivcap-cli"
 const CONFIG_FILE_NAME = "config.yaml"
-const HISTORY_FILE_NAME = "history.yaml"
 const VERSION_CHECK_FILE_NAME = "vcheck.txt"
 const CHECK_VERSION_INTERVAL = time.Duration(24 * time.Hour)
 
@@ -88,6 +86,7 @@ func addListFlags(cmd *cobra.Command) {
 	fs.StringVar(&orderBy, "order-by", "", "feature to order list by (e.g. \"created-at,status\")")
 	fs.BoolVar(&orderDesc, "order-desc", false, "if set, order in descending order")
 	fs.StringVar(&atTime, "at-time", "", "query state at this time in the past")
+	addPagingFlags(cmd)
 }
 
 func addLimitFlag(cmd *cobra.Command) {
@@ -193,115 +192,13 @@ func createListRequest() (req *sdk.ListRequest) {
 	if atTime != "" {
 		t, err := dateparse.ParseLocal(atTime)
 		if err != nil {
-			cobra.CheckErr(fmt.Sprintf("Can't parse '%s' into a date - %s", atTime, err))
+			checkErr(fmt.Sprintf("Can't parse '%s' into a date - %s", atTime, err))
 		}
 		req.AtTime = &t
 	}
 	return
 }
 
-// ****** HISTORY ****
-
-var history map[string]string
-
-func MakeHistory(urn *string) string {
-	if urn == nil {
-		return "???"
-	}
-	if noHistory {
-		return *urn
-	}
-	if history == nil {
-		history = make(map[string]string)
-	}
-	token := fmt.Sprintf("@%d", len(history)+1)
-	history[token] = *urn
-	return token
-}
-
-// Check if argument is an IVCAP urn and if it
-// is, turn it into a history.
-func MakeMaybeHistory(sp *string) string {
-	if sp == nil {
-		return "???"
-	}
-	if noHistory {
-		return *sp
-	}
-	// HACK: Should go away in future IVCAP Core version
-	if strings.HasPrefix(*sp, "http://artifact.local/") {
-		u := *sp
-		u = u[len("http://artifact.local/"):]
-		sp = &u
-	}
-	// We assume, all IVCAP urns follow the pattern 'urn:ivcap:_service_:...
-	if !strings.HasPrefix(*sp, "urn:ivcap:") {
-		// no it's not
-		return *sp
-	}
-
-	if history == nil {
-		history = make(map[string]string)
-	}
-	token := fmt.Sprintf("@%d", len(history)+1)
-	history[token] = *sp
-	return fmt.Sprintf("%s (%s)", token, *sp)
-}
-
-func GetHistory(token string) (value string) {
-	if !strings.HasPrefix(token, "@") {
-		return token
-	}
-	var vp *string
-	path := getHistoryFilePath()
-	var data []byte
-	data, err := os.ReadFile(filepath.Clean(path))
-	var hm map[string]string
-	if err == nil {
-		if err := yaml.Unmarshal(data, &hm); err != nil {
-			cobra.CheckErr(fmt.Sprintf("problems parsing history file %s - %v", path, err))
-			return
-		}
-		if val, ok := hm[token]; ok {
-			vp = &val
-		}
-	} else {
-		// fail "normally" if file doesn't exist
-		if _, ok := err.(*os.PathError); !ok {
-			cobra.CheckErr("Error reading history file. Use full names instead.")
-			return
-		}
-	}
-	if vp == nil {
-		cobra.CheckErr(fmt.Sprintf("Unknown history '%s'.", token))
-		return
-	}
-	return *vp
-}
-
-func saveHistory() (err error) {
-	if history == nil {
-		return
-	}
-
-	b, err := yaml.Marshal(history)
-	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot marshall history - %v", err))
-		return
-	}
-
-	path := makeConfigFilePath(HISTORY_FILE_NAME)
-
-	if err = os.WriteFile(path, b, fs.FileMode(0600)); err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot write history to file %s - %v", path, err))
-	}
-	return
-}
-
-func getHistoryFilePath() (path string) {
-	return makeConfigFilePath(HISTORY_FILE_NAME)
-}
-
 // ****** CONTEXT ****
 
 func GetActiveContext() (ctxt *Context) {
@@ -312,7 +209,7 @@ func GetContext(name string, defaultToActiveContext bool) (ctxt *Context) {
 	var err error
 	ctxt, err = GetContextWithError(name, defaultToActiveContext)
 	if err != nil {
-		cobra.CheckErr(err)
+		checkErr(err)
 	}
 	return
 }
@@ -340,26 +237,42 @@ func GetContextWithError(name string, defaultToActiveContext bool) (ctxt *Contex
 	return
 }
 
+// SetContext stores `ctxt` in the config file, replacing any existing context
+// with the same name. Other contexts are left as they are currently stored,
+// even if they have been changed by another process in the meantime.
 func SetContext(ctxt *Context, failIfNotExist bool) {
-	config, _ := ReadConfigFile(true)
-	cxa := config.Contexts
-	for i, c := range cxa {
-		if c.Name == ctxt.Name {
-			config.Contexts[i] = *ctxt
-			WriteConfigFile(config)
+	UpdateConfigFile(true, func(config *Config) {
+		for i, c := range config.Contexts {
+			if c.Name == ctxt.Name {
+				config.Contexts[i] = *ctxt
+				return
+			}
+		}
+		if failIfNotExist {
+			checkErr(fmt.Sprintf("attempting to set/update non existing context '%s'", ctxt.Name))
 			return
 		}
-	}
-	if failIfNotExist {
-		cobra.CheckErr(fmt.Sprintf("attempting to set/update non existing context '%s'", ctxt.Name))
-	} else {
 		config.Contexts = append(config.Contexts, *ctxt)
 		if len(config.Contexts) == 1 {
 			// First context, make it the active/default one as well
 			config.ActiveContext = ctxt.Name
 		}
-		WriteConfigFile(config)
-	}
+	})
+}
+
+// UpdateContext applies `update` to the currently stored version of
+// context `name` and saves it. Use this instead of 'SetContext' to only
+// change some fields of a context.
+func UpdateContext(name string, update func(ctxt *Context)) {
+	UpdateConfigFile(false, func(config *Config) {
+		for i := range config.Contexts {
+			if config.Contexts[i].Name == name {
+				update(&config.Contexts[i])
+				return
+			}
+		}
+		checkErr(fmt.Sprintf("attempting to set/update non existing context '%s'", name))
+	})
 }
 
 // ****** CONFIG FILE ****
@@ -376,39 +289,78 @@ func ReadConfigFile(createIfNoConfig bool) (config *Config, configFile string) {
 				}
 				return
 			} else {
-				cobra.CheckErr("Config file does not exist. Please create the config file with the context command.")
+				checkErr("Config file does not exist. Please create the config file with the context command.")
 			}
 		} else {
-			cobra.CheckErr(fmt.Sprintf("Cannot read config file %s - %v", configFile, err))
+			checkErr(fmt.Sprintf("Cannot read config file %s - %v", configFile, err))
 		}
 	}
 	var cfg Config
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
-		cobra.CheckErr(fmt.Sprintf("problems parsing config file %s - %v", configFile, err))
+		checkErr(fmt.Sprintf("problems parsing config file %s - %v", configFile, err))
 		return
 	}
 	config = &cfg
 	return
 }
 
+// WriteConfigFile replaces the content of the config file with `config`.
+// Prefer 'UpdateConfigFile' which doesn't lose concurrent changes made by
+// other processes.
 func WriteConfigFile(config *Config) {
-	b, err := yaml.Marshal(config)
+	configFile := GetConfigFilePath()
+	unlock, err := lockFile(configFile)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot marshall content of config file - %v", err))
+		checkErr(fmt.Sprintf("cannot lock config file %s - %v", configFile, err))
 		return
 	}
+	defer unlock()
+	writeConfigFile(config, configFile)
+}
 
+// UpdateConfigFile reads the config file, applies `update` to it and writes
+// it back. The config file is locked for the whole time, so concurrent
+// updates by other 'ivcap' processes (e.g. refreshing tokens) are serialised
+// rather than overwriting each other.
+func UpdateConfigFile(createIfNoConfig bool, update func(config *Config)) {
 	configFile := GetConfigFilePath()
+	unlock, err := lockFile(configFile)
+	if err != nil {
+		checkErr(fmt.Sprintf("cannot lock config file %s - %v", configFile, err))
+		return
+	}
+	defer unlock()
+	config, _ := ReadConfigFile(createIfNoConfig)
+	update(config)
+	writeConfigFile(config, configFile)
+}
+
+// must be called while holding the lock on `configFile`
+func writeConfigFile(config *Config, configFile string) {
+	// credentials of contexts using a different store never go into the config file
+	cfg := *config
+	cfg.Contexts = make([]Context, len(config.Contexts))
+	for i, c := range config.Contexts {
+		if !usesPlaintextStore(&c) {
+			setCredentials(&c, &Credentials{})
+		}
+		cfg.Contexts[i] = c
+	}
+	b, err := yaml.Marshal(&cfg)
+	if err != nil {
+		checkErr(fmt.Sprintf("cannot marshall content of config file - %v", err))
+		return
+	}
 
-	if err = os.WriteFile(configFile, b, fs.FileMode(0600)); err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot write to config file %s - %v", configFile, err))
+	if err = writeFileAtomic(configFile, b, fs.FileMode(0600)); err != nil {
+		checkErr(fmt.Sprintf("cannot write to config file %s - %v", configFile, err))
 	}
 }
 
 func GetConfigDir(createIfNoExist bool) (configDir string) {
 	userConfigDir, err := os.UserConfigDir()
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("Cannot find the user configuration directory - %v", err))
+		checkErr(fmt.Sprintf("Cannot find the user configuration directory - %v", err))
 		return
 	}
 	configDir = userConfigDir + string(os.PathSeparator) + CONFIG_FILE_DIR
@@ -416,7 +368,7 @@ func GetConfigDir(createIfNoExist bool) (configDir string) {
 	if createIfNoExist {
 		err = os.MkdirAll(configDir, 0750)
 		if err != nil && !os.IsExist(err) {
-			cobra.CheckErr(fmt.Sprintf("Could not create configuration directory %s - %v", configDir, err))
+			checkErr(fmt.Sprintf("Could not create configuration directory %s - %v", configDir, err))
 			return
 		}
 	}
@@ -0,0 +1,185 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Where the value of a setting came from, in order of precedence
+const (
+	SOURCE_FLAG    = "flag"
+	SOURCE_ENV     = "env"
+	SOURCE_PROJECT = "project"
+	SOURCE_CONFIG  = "config"
+	SOURCE_DEFAULT = "default"
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(explainConfigCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:     "config",
+	Short:   "Inspect where settings are taken from",
+	GroupID: generalSupportGroupID,
+	Long: `Settings are taken from the following places, the first one providing a
+value wins:
+
+  1. flags on the command line
+  2. environment variables, '` + ENV_PREFIX + `_<FLAG>' for global flags, e.g.
+     '` + ENV_PREFIX + `_CONTEXT', and '` + ENV_PREFIX + `_<COMMAND>_<FLAG>' for the flags of a
+     command, e.g. '` + ENV_PREFIX + `_ARTIFACT_CHUNK_SIZE'. The variables of each
+     command are listed in its help
+  3. the project file '` + PROJECT_FILE_NAME + `' found in the working directory
+     or the closest of its parents
+  4. the global config file with the contexts and the active context, see
+     'ivcap context'
+  5. built-in defaults
+
+A project file can pin the context and the output format for all commands
+run below its directory, the policy and collection for commands having
+these flags, and defaults for the flags of specific commands:
+
+  context: prod
+  output: json
+  policy: urn:ivcap:policy:...
+  collection: urn:ivcap:collection:...
+  defaults:
+    job list:
+      limit: 50
+    artifact create:
+      name: results`,
+}
+
+var explainConfigCmd = &cobra.Command{
+	Use:   "explain [command...]",
+	Short: "Show the effective context and output format, and where they come from",
+	Long: `Shows the config and project file in use, the effective context and output
+format, and where each of them comes from. If a command is given, the values
+the environment and the project file provide for its flags are shown as well.`,
+	Example: `  ivcap config explain
+  ivcap config explain artifact create
+  ivcap --context dev config explain -o json`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		explanation := explainConfig()
+		if len(args) > 0 {
+			target, _, err := rootCmd.Find(args)
+			if err != nil || target == rootCmd {
+				return &UsageError{fmt.Errorf("unknown command '%s'", args)}
+			}
+			explanation.Command = target.CommandPath()
+			explanation.Settings = append(explanation.Settings, explainCommandFlags(target)...)
+		}
+		if !isTableOutput() {
+			return printValue(explanation)
+		}
+		t := table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendRow(table.Row{"Config File", explanation.ConfigFile})
+		projectFile := explanation.ProjectFile
+		if projectFile == "" {
+			projectFile = "none"
+		}
+		t.AppendRow(table.Row{"Project File", projectFile})
+		t.Render()
+		t = table.NewWriter()
+		t.SetOutputMirror(os.Stdout)
+		t.AppendHeader(table.Row{"Setting", "Value", "Source"})
+		for _, s := range explanation.Settings {
+			t.AppendRow(table.Row{s.Name, s.Value, s.Source})
+		}
+		t.Render()
+		return nil
+	},
+}
+
+type configExplanation struct {
+	ConfigFile  string          `json:"config-file"`
+	ProjectFile string          `json:"project-file,omitempty"`
+	Command     string          `json:"command,omitempty"`
+	Settings    []settingSource `json:"settings"`
+}
+
+type settingSource struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Source string `json:"source"`
+}
+
+func explainConfig() *configExplanation {
+	_, projectFile := getProjectConfig()
+	e := &configExplanation{ConfigFile: GetConfigFilePath(), ProjectFile: projectFile}
+
+	ctxt := settingSource{Name: "context", Value: contextName, Source: flagSource("context")}
+	if ctxt.Source == SOURCE_DEFAULT {
+		if config, _ := ReadConfigFile(true); config.ActiveContext != "" {
+			ctxt.Value, ctxt.Source = config.ActiveContext, SOURCE_CONFIG
+		}
+	}
+	output := settingSource{Name: "output", Value: outputFormat, Source: flagSource("output")}
+	if output.Value == "" {
+		output.Value = "table"
+	}
+	e.Settings = append(e.Settings, ctxt, output)
+	return e
+}
+
+// Returns the flags of `cmd` which get their value from the environment or
+// the project file. Environment variables take precedence, as they do when
+// running `cmd`.
+func explainCommandFlags(cmd *cobra.Command) []settingSource {
+	var defaults map[string]string
+	if pc, _ := getProjectConfig(); pc != nil {
+		defaults = pc.flagDefaults(cmd)
+	}
+	var settings []settingSource
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		if !ignoreEnvFlag(f) {
+			for _, name := range flagEnvVars(cmd, f) {
+				if value := os.Getenv(name); value != "" {
+					settings = append(settings, settingSource{Name: "--" + f.Name, Value: value, Source: SOURCE_ENV})
+					return
+				}
+			}
+		}
+		if value, ok := defaults[f.Name]; ok {
+			settings = append(settings, settingSource{Name: "--" + f.Name, Value: value, Source: SOURCE_PROJECT})
+		}
+	})
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+// Returns where the value of global flag `name` of the current command came from.
+func flagSource(name string) string {
+	switch {
+	case envFlags[name] != "":
+		return SOURCE_ENV
+	case projectFlags[name]:
+		return SOURCE_PROJECT
+	case rootCmd.PersistentFlags().Lookup(name).Changed:
+		return SOURCE_FLAG
+	default:
+		return SOURCE_DEFAULT
+	}
+}
@@ -15,12 +15,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 )
@@ -38,13 +42,45 @@ func init() {
 	// createContextCmd.Flags().StringVar(&providerID, "provider-id", "", "The account ID to use. Will most likely be set on login")
 	createContextCmd.Flags().StringVar(&hostName, "host-name", "", "optional host name if accessing API through SSH tunnel")
 	createContextCmd.Flags().IntVar(&ctxtApiVersion, "version", 1, "define API version")
+	createContextCmd.Flags().IntVar(&ctxtMaxRetries, "max-retries", -1,
+		fmt.Sprintf("max. number of retries for failed requests [%d]", a.DefaultMaxRetries))
+	createContextCmd.Flags().BoolVar(&ctxtIdempotencyKeys, "idempotency-keys", false,
+		"add an 'Idempotency-Key' header to POST requests so they can be retried safely")
+	createContextCmd.Flags().DurationVar(&ctxtHistoryRetention, "history-retention", HISTORY_DEFAULT_RETENTION,
+		"drop history tokens not shown or used for this long")
+	createContextCmd.Flags().IntVar(&ctxtHistoryMaxEntries, "history-max-entries", HISTORY_DEFAULT_MAX_ENTRIES,
+		"max. number of history tokens kept for each kind of resource")
+	addTransportFlags(createContextCmd)
+	addCredentialStoreFlags(createContextCmd)
 
 	// SET/USE
 	contextCmd.AddCommand(useContextCmd)
 
+	// UPDATE
+	contextCmd.AddCommand(updateContextCmd)
+	addTransportFlags(updateContextCmd)
+
 	// READ/GET
 	contextCmd.AddCommand(getContextCmd)
 	getContextCmd.Flags().BoolVar(&refreshToken, "refresh-token", false, "if set, refresh access token if expired")
+
+	// MIGRATE CREDENTIALS
+	contextCmd.AddCommand(migrateCredentialsCmd)
+	addCredentialStoreFlags(migrateCredentialsCmd)
+	migrateCredentialsCmd.Flags().BoolVar(&migrateAllContexts, "all", false, "migrate the credentials of all contexts")
+	checkErr(migrateCredentialsCmd.MarkFlagRequired("credential-store"))
+
+	// DELETE/RENAME/COPY
+	contextCmd.AddCommand(deleteContextCmd)
+	contextCmd.AddCommand(renameContextCmd)
+	contextCmd.AddCommand(copyContextCmd)
+
+	// EXPORT/IMPORT
+	contextCmd.AddCommand(exportContextCmd)
+	exportContextCmd.Flags().StringVarP(&ctxtBundleFile, "file", "f", "", "file to write the contexts to [stdout]")
+	contextCmd.AddCommand(importContextCmd)
+	importContextCmd.Flags().BoolVar(&ctxtImportOverwrite, "overwrite", false,
+		"replace the settings of existing contexts with the same name")
 }
 
 var (
@@ -52,6 +88,25 @@ var (
 	ctxtApiVersion int
 	hostName       string
 	refreshToken   bool
+
+	ctxtMaxRetries        int
+	ctxtHistoryRetention  time.Duration
+	ctxtHistoryMaxEntries int
+	ctxtIdempotencyKeys   bool
+
+	ctxtCAFile             string
+	ctxtClientCert         string
+	ctxtClientKey          string
+	ctxtInsecureSkipVerify bool
+	ctxtProxyURL           string
+	ctxtNoProxy            string
+
+	ctxtCredentialStore   string
+	ctxtCredentialKeyFile string
+	migrateAllContexts    bool
+
+	ctxtBundleFile      string
+	ctxtImportOverwrite bool
 )
 
 // contextCmd represents the config command
@@ -66,12 +121,12 @@ var createContextCmd = &cobra.Command{
 	Short: "Create a new context",
 	Args:  cobra.ExactArgs(2),
 	// Aliases: []string{"create"},
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
 		ctxtName = args[0]
 		ctxtUrl := strings.TrimRight(args[1], "/")
 		url, err := url.ParseRequestURI(ctxtUrl)
 		if err != nil || url.Host == "" {
-			cobra.CheckErr(fmt.Sprintf("url '%s' is not a valid URL", ctxtUrl))
+			checkErr(fmt.Sprintf("url '%s' is not a valid URL", ctxtUrl))
 		}
 
 		ctxt := &Context{
@@ -79,7 +134,23 @@ var createContextCmd = &cobra.Command{
 			Name:       ctxtName,
 			URL:        ctxtUrl,
 			Host:       hostName,
+
+			IdempotencyKeys: ctxtIdempotencyKeys,
+		}
+		if ctxtMaxRetries >= 0 {
+			ctxt.MaxRetries = &ctxtMaxRetries
+		}
+		if ctxtHistoryRetention <= 0 || ctxtHistoryMaxEntries <= 0 {
+			checkErr(&UsageError{errors.New("'--history-retention' and '--history-max-entries' need to be positive")})
+		}
+		if cmd.Flags().Changed("history-retention") {
+			ctxt.HistoryRetention = ctxtHistoryRetention.String()
 		}
+		if cmd.Flags().Changed("history-max-entries") {
+			ctxt.HistoryMaxEntries = ctxtHistoryMaxEntries
+		}
+		applyTransportFlags(cmd, ctxt)
+		applyCredentialStoreFlags(ctxt)
 		SetContext(ctxt, false)
 		fmt.Printf("Context '%s' created.\n", ctxtName)
 	},
@@ -91,6 +162,27 @@ var listContextCmd = &cobra.Command{
 	// Aliases: []string{"get-context", "list"},
 	Run: func(_ *cobra.Command, _ []string) {
 		config, _ := ReadConfigFile(true)
+		if config != nil && !isTableOutput() {
+			type contextItem struct {
+				Name      string `json:"name"`
+				Current   bool   `json:"current"`
+				AccountID string `json:"account-id,omitempty"`
+				URL       string `json:"url"`
+			}
+			items := make([]contextItem, len(config.Contexts))
+			for i, c := range config.Contexts {
+				items[i] = contextItem{Name: c.Name, Current: c.Name == config.ActiveContext, AccountID: c.AccountID, URL: c.URL}
+			}
+			getPrinter().IDFunc = func(record any) string {
+				if r, ok := record.(map[string]any); ok {
+					name, _ := r["name"].(string)
+					return name
+				}
+				return ""
+			}
+			checkErr(printValue(map[string]any{"items": items}))
+			return
+		}
 		if config != nil {
 			t := table.NewWriter()
 			t.SetOutputMirror(os.Stdout)
@@ -114,27 +206,109 @@ var useContextCmd = &cobra.Command{
 	Aliases: []string{"use"},
 	Run: func(_ *cobra.Command, args []string) {
 		if len(args) < 1 {
-			cobra.CheckErr("Missing 'name' arg")
+			checkErr("Missing 'name' arg")
 		}
 		ctxtName = args[0]
-		config, _ := ReadConfigFile(false)
-		ctxtExists := false
-		for _, c := range config.Contexts {
-			if c.Name == ctxtName {
-				ctxtExists = true
-				break
+		UpdateConfigFile(false, func(config *Config) {
+			if !slices.ContainsFunc(config.Contexts, func(c Context) bool { return c.Name == ctxtName }) {
+				checkErr(fmt.Sprintf("context '%s' is not defined", ctxtName))
 			}
-		}
-		if ctxtExists {
 			config.ActiveContext = ctxtName
-			WriteConfigFile(config)
-			fmt.Printf("Switched to context '%s'.\n", ctxtName)
-		} else {
-			cobra.CheckErr(fmt.Sprintf("context '%s' is not defined", ctxtName))
+		})
+		fmt.Printf("Switched to context '%s'.\n", ctxtName)
+		if pc, path := getProjectConfig(); pc != nil && pc.Context != "" && pc.Context != ctxtName {
+			fmt.Fprintf(os.Stderr, "WARNING: context '%s' is pinned by project file '%s' in this directory\n", pc.Context, path)
+		}
+	},
+}
+
+var updateContextCmd = &cobra.Command{
+	Use:   "update name [flags]",
+	Short: "Update the TLS and proxy settings of a context",
+	Long: `Changes the TLS and proxy settings of context 'name' provided as flags,
+and leaves all others as they are. An empty value clears a setting, e.g.
+'--proxy-url ""'. The active context is not changed.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		var err error
+		UpdateConfigFile(false, func(config *Config) {
+			idx := slices.IndexFunc(config.Contexts, func(c Context) bool { return c.Name == name })
+			if idx < 0 {
+				err = fmt.Errorf("context '%s' is not defined", name)
+			} else if !applyTransportFlags(cmd, &config.Contexts[idx]) {
+				err = &UsageError{errors.New("no settings to update, see '--help'")}
+			}
+		})
+		if err != nil {
+			return err
+		}
+		if !silent {
+			fmt.Printf("Context '%s' updated.\n", name)
 		}
+		return nil
 	},
 }
 
+func addTransportFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(&ctxtCAFile, "ca-file", "", "PEM file with additional CA certificates to trust")
+	fs.StringVar(&ctxtClientCert, "client-cert", "", "PEM file with client certificate for mutual TLS")
+	fs.StringVar(&ctxtClientKey, "client-key", "", "PEM file with the key of the client certificate")
+	fs.BoolVar(&ctxtInsecureSkipVerify, "insecure-skip-verify", false, "don't verify the deployment's certificate (insecure)")
+	fs.StringVar(&ctxtProxyURL, "proxy-url", "", "proxy for all requests [HTTPS_PROXY]")
+	fs.StringVar(&ctxtNoProxy, "no-proxy", "", "comma separated hosts and domains to connect to directly [NO_PROXY]")
+}
+
+// applyTransportFlags copies the TLS and proxy flags provided on the
+// command line to `ctxt` and returns true if there were any. An empty
+// value clears a setting.
+func applyTransportFlags(cmd *cobra.Command, ctxt *Context) (changed bool) {
+	fs := cmd.Flags()
+	setPath := func(flag string, value string, field *string) {
+		if !fs.Changed(flag) {
+			return
+		}
+		changed = true
+		*field = value
+		if value != "" {
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				checkErr(fmt.Sprintf("invalid path '%s' for '--%s' - %v", value, flag, err))
+			}
+			*field = abs
+		}
+	}
+	setPath("ca-file", ctxtCAFile, &ctxt.CAFile)
+	setPath("client-cert", ctxtClientCert, &ctxt.ClientCert)
+	setPath("client-key", ctxtClientKey, &ctxt.ClientKey)
+	if fs.Changed("insecure-skip-verify") {
+		changed = true
+		ctxt.InsecureSkipVerify = ctxtInsecureSkipVerify
+	}
+	if fs.Changed("proxy-url") {
+		changed = true
+		ctxt.ProxyURL = ctxtProxyURL
+	}
+	if fs.Changed("no-proxy") {
+		changed = true
+		ctxt.NoProxy = ctxtNoProxy
+	}
+	if changed {
+		if _, err := a.NewTransport(transportConfig(ctxt)); err != nil {
+			checkErr(&UsageError{err})
+		}
+		warnIfInsecure(ctxt)
+	}
+	return
+}
+
+func warnIfInsecure(ctxt *Context) {
+	if ctxt.InsecureSkipVerify {
+		fmt.Fprintf(os.Stderr, "WARNING: certificates of context '%s' will not be verified\n", ctxt.Name)
+	}
+}
+
 var getContextCmd = &cobra.Command{
 	Use:     "get [all|name|account-id|provider-id|url|access-token]",
 	Short:   "Display the current context",
@@ -178,17 +352,305 @@ var getContextCmd = &cobra.Command{
 				if accessTokenProvided {
 					isAuth = fmt.Sprintf("unknown, token provided via '--access-token' flag or environment variable '%s'", ACCESS_TOKEN_ENV)
 				} else {
+					if err := loadCredentials(context); err != nil {
+						checkErr(err)
+					}
 					isAuth = fmt.Sprintf("yes, refreshing after %s", context.AccessTokenExpiry.Format(time.RFC822))
 				}
 			}
 			t.AppendRow(table.Row{"Authorised", isAuth})
+			if context.ClientID != "" {
+				t.AppendRow(table.Row{"Client ID", context.ClientID})
+			}
+			if !usesPlaintextStore(context) {
+				t.AppendRow(table.Row{"Credential Store", context.CredentialStore})
+			}
+			if context.CredentialKeyFile != "" {
+				t.AppendRow(table.Row{"Credential Key File", context.CredentialKeyFile})
+			}
 			if context.Host != "" {
 				t.AppendRow(table.Row{"Host", context.Host})
 			}
+			if context.MaxRetries != nil {
+				t.AppendRow(table.Row{"Max Retries", *context.MaxRetries})
+			}
+			if context.IdempotencyKeys {
+				t.AppendRow(table.Row{"Idempotency Keys", "yes"})
+			}
+			if context.HistoryRetention != "" {
+				t.AppendRow(table.Row{"History Retention", context.HistoryRetention})
+			}
+			if context.HistoryMaxEntries > 0 {
+				t.AppendRow(table.Row{"History Max Entries", context.HistoryMaxEntries})
+			}
+			if context.CAFile != "" {
+				t.AppendRow(table.Row{"CA File", context.CAFile})
+			}
+			if context.ClientCert != "" {
+				t.AppendRow(table.Row{"Client Cert", context.ClientCert})
+				t.AppendRow(table.Row{"Client Key", context.ClientKey})
+			}
+			if context.InsecureSkipVerify {
+				t.AppendRow(table.Row{"Insecure Skip Verify", "yes"})
+			}
+			if context.ProxyURL != "" {
+				t.AppendRow(table.Row{"Proxy URL", context.ProxyURL})
+			}
+			if context.NoProxy != "" {
+				t.AppendRow(table.Row{"No Proxy", context.NoProxy})
+			}
 
 			t.Render()
 		default:
-			cobra.CheckErr(fmt.Sprintf("unknown context parameter '%s'", param))
+			checkErr(fmt.Sprintf("unknown context parameter '%s'", param))
 		}
 	},
 }
+
+var migrateCredentialsCmd = &cobra.Command{
+	Use:   "migrate-credentials [flags] [name]",
+	Short: "Move the credentials of a context to a different credential store",
+	Long: `Moves the access and refresh token of context 'name' (or the active
+context) into the credential store selected with '--credential-store', and
+removes them from the store they were kept in so far. Supported stores are:
+
+  keyring    the keychain of the OS (Secret Service on Linux, Keychain on
+             macOS, Credential Manager on Windows)
+  file       an age encrypted file in the config directory, using the age
+             identity in '--credential-key-file', or otherwise a passphrase
+             provided via env '` + CREDENTIALS_PASSPHRASE_ENV + `' or asked for
+  plaintext  the config file itself
+
+Example:
+  ivcap context migrate-credentials --all --credential-store keyring`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var names []string
+		if migrateAllContexts {
+			config, _ := ReadConfigFile(false)
+			for _, c := range config.Contexts {
+				names = append(names, c.Name)
+			}
+		} else {
+			name := ""
+			if len(args) == 1 {
+				name = args[0]
+			}
+			names = append(names, GetContext(name, true).Name)
+		}
+		for _, name := range names {
+			if err := migrateCredentials(GetContext(name, false)); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+func addCredentialStoreFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.StringVar(&ctxtCredentialStore, "credential-store", "",
+		fmt.Sprintf("where to keep access and refresh tokens [%s]", strings.Join(CREDENTIAL_STORES, ", ")))
+	fs.StringVar(&ctxtCredentialKeyFile, "credential-key-file", "",
+		"file with age identity to encrypt credentials with instead of a passphrase ('file' store only)")
+}
+
+// applyCredentialStoreFlags sets the credential store of `ctxt` from the
+// command line flags.
+func applyCredentialStoreFlags(ctxt *Context) {
+	ctxt.CredentialStore = ""
+	if ctxtCredentialStore != PLAINTEXT_CREDENTIAL_STORE {
+		ctxt.CredentialStore = ctxtCredentialStore
+	}
+	ctxt.CredentialKeyFile = ""
+	if ctxtCredentialKeyFile != "" {
+		if ctxtCredentialStore != FILE_CREDENTIAL_STORE {
+			checkErr(&UsageError{errors.New("'--credential-key-file' requires '--credential-store file'")})
+		}
+		path, err := filepath.Abs(ctxtCredentialKeyFile)
+		if err != nil {
+			checkErr(&UsageError{err})
+		}
+		ctxt.CredentialKeyFile = path
+	}
+	if _, err := getCredentialStore(ctxt.CredentialStore, ctxt); err != nil {
+		checkErr(&UsageError{err})
+	}
+}
+
+func migrateCredentials(ctxt *Context) error {
+	target := *ctxt
+	applyCredentialStoreFlags(&target)
+	if target.CredentialStore == ctxt.CredentialStore && target.CredentialKeyFile == ctxt.CredentialKeyFile {
+		fmt.Printf("Credentials of context '%s' are already in the '%s' store.\n", ctxt.Name, ctxtCredentialStore)
+		return nil
+	}
+	if err := loadCredentials(ctxt); err != nil {
+		return err
+	}
+	creds := getCredentials(ctxt)
+	if !usesPlaintextStore(&target) {
+		store, _ := getCredentialStore(target.CredentialStore, &target)
+		if err := store.Save(&target, creds); err != nil {
+			return fmt.Errorf("cannot save credentials of context '%s' - %w", ctxt.Name, err)
+		}
+	}
+	UpdateContext(ctxt.Name, func(stored *Context) {
+		stored.CredentialStore = target.CredentialStore
+		stored.CredentialKeyFile = target.CredentialKeyFile
+		// only kept in the config file if it is the new store
+		setCredentials(stored, creds)
+	})
+	// both use the same file, which has been replaced already
+	sameFile := ctxt.CredentialStore == FILE_CREDENTIAL_STORE && target.CredentialStore == FILE_CREDENTIAL_STORE
+	if !usesPlaintextStore(ctxt) && !sameFile {
+		store, _ := getCredentialStore(ctxt.CredentialStore, ctxt)
+		if err := store.Delete(ctxt); err != nil {
+			return fmt.Errorf("cannot remove credentials of context '%s' from '%s' store - %w",
+				ctxt.Name, ctxt.CredentialStore, err)
+		}
+	}
+	loadedCredentialsMu.Lock()
+	loadedCredentials[ctxt.Name] = creds
+	loadedCredentialsMu.Unlock()
+	fmt.Printf("Moved credentials of context '%s' to the '%s' store.\n", ctxt.Name, ctxtCredentialStore)
+	return nil
+}
+
+var deleteContextCmd = &cobra.Command{
+	Use:     "delete name...",
+	Short:   "Delete contexts and their credentials",
+	Aliases: []string{"rm"},
+	Args:    cobra.MinimumNArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		for _, name := range args {
+			if err := deleteContext(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// Removes context `name` from the config file, together with its stored
+// credentials and cached replies.
+func deleteContext(name string) error {
+	ctxt, err := GetContextWithError(name, false)
+	if err != nil {
+		return err
+	}
+	if err = deleteCredentials(ctxt); err != nil {
+		// don't keep a context around only because the keyring is unavailable
+		fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+	}
+	wasActive := false
+	UpdateConfigFile(false, func(config *Config) {
+		config.Contexts = slices.DeleteFunc(config.Contexts, func(c Context) bool { return c.Name == name })
+		if config.ActiveContext == name {
+			config.ActiveContext = ""
+			wasActive = true
+		}
+	})
+	_ = a.NewCache(getCacheDir(name)).Clear()
+	_ = os.Remove(getHistoryFilePath(name))
+	fmt.Printf("Context '%s' deleted.\n", name)
+	if wasActive {
+		fmt.Println("There is no active context now, select one with 'ivcap context set'.")
+	}
+	return nil
+}
+
+var renameContextCmd = &cobra.Command{
+	Use:     "rename old-name new-name",
+	Short:   "Rename a context",
+	Aliases: []string{"mv"},
+	Args:    cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		return renameContext(args[0], args[1])
+	},
+}
+
+// Renames context `oldName` to `newName`, moving its credentials along if
+// they are kept outside the config file.
+func renameContext(oldName, newName string) error {
+	ctxt, err := GetContextWithError(oldName, false)
+	if err != nil {
+		return err
+	}
+	if _, err = GetContextWithError(newName, false); err == nil {
+		return fmt.Errorf("context '%s' already exists", newName)
+	}
+	renamed := *ctxt
+	renamed.Name = newName
+	if !usesPlaintextStore(ctxt) {
+		if err = loadCredentials(ctxt); err != nil {
+			return err
+		}
+		setCredentials(&renamed, getCredentials(ctxt))
+		if err = saveCredentials(&renamed); err != nil {
+			return err
+		}
+	}
+	UpdateConfigFile(false, func(config *Config) {
+		for i := range config.Contexts {
+			if config.Contexts[i].Name == newName {
+				checkErr(fmt.Sprintf("context '%s' already exists", newName))
+			}
+		}
+		for i := range config.Contexts {
+			if config.Contexts[i].Name == oldName {
+				config.Contexts[i].Name = newName
+			}
+		}
+		if config.ActiveContext == oldName {
+			config.ActiveContext = newName
+		}
+	})
+	if !usesPlaintextStore(ctxt) {
+		if err = deleteCredentials(ctxt); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+		}
+	}
+	_ = a.NewCache(getCacheDir(oldName)).Clear()
+	_ = os.Rename(getHistoryFilePath(oldName), getHistoryFilePath(newName))
+	fmt.Printf("Context '%s' renamed to '%s'.\n", oldName, newName)
+	return nil
+}
+
+var copyContextCmd = &cobra.Command{
+	Use:   "copy name new-name",
+	Short: "Create a new context with the settings of an existing one",
+	Long: `Creates context 'new-name' with the same URL, API version, host, retry,
+TLS, proxy and credential store settings as context 'name'. The new context
+is not logged in.`,
+	Aliases: []string{"cp"},
+	Args:    cobra.ExactArgs(2),
+	RunE: func(_ *cobra.Command, args []string) error {
+		ctxt, err := GetContextWithError(args[0], false)
+		if err != nil {
+			return err
+		}
+		if _, err = GetContextWithError(args[1], false); err == nil {
+			return fmt.Errorf("context '%s' already exists", args[1])
+		}
+		cp := *ctxt
+		cp.Name = args[1]
+		clearLogin(&cp)
+		SetContext(&cp, false)
+		fmt.Printf("Context '%s' created.\n", cp.Name)
+		return nil
+	},
+}
+
+// clearLogin removes the credentials and account information obtained by
+// logging into `ctxt`.
+func clearLogin(ctxt *Context) {
+	setCredentials(ctxt, &Credentials{})
+	ctxt.ClientID = ""
+	ctxt.ClientSecretFile = ""
+	ctxt.AccountID = ""
+	ctxt.ProviderID = ""
+	ctxt.AccountName = ""
+	ctxt.AccountNickName = ""
+	ctxt.Email = ""
+}
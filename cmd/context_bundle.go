@@ -0,0 +1,270 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const CONTEXT_BUNDLE_VERSION = "v1"
+
+// contextBundle is the file written by 'context export'. It only holds the
+// settings needed to reach a deployment, and never any credentials or
+// account information, so it can be shared with others.
+type contextBundle struct {
+	Version  string          `yaml:"version"`
+	Contexts []bundleContext `yaml:"contexts"`
+}
+
+type bundleContext struct {
+	Name               string `yaml:"name"`
+	URL                string `yaml:"url"`
+	ApiVersion         int    `yaml:"api-version,omitempty"`
+	Host               string `yaml:"host,omitempty"`
+	MaxRetries         *int   `yaml:"max-retries,omitempty"`
+	IdempotencyKeys    bool   `yaml:"idempotency-keys,omitempty"`
+	CAFile             string `yaml:"ca-file,omitempty"`
+	ClientCert         string `yaml:"client-cert,omitempty"`
+	ClientKey          string `yaml:"client-key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+	ProxyURL           string `yaml:"proxy-url,omitempty"`
+	NoProxy            string `yaml:"no-proxy,omitempty"`
+	HistoryRetention   string `yaml:"history-retention,omitempty"`
+	HistoryMaxEntries  int    `yaml:"history-max-entries,omitempty"`
+}
+
+func toBundleContext(c *Context) bundleContext {
+	return bundleContext{
+		Name:               c.Name,
+		URL:                c.URL,
+		ApiVersion:         c.ApiVersion,
+		Host:               c.Host,
+		MaxRetries:         c.MaxRetries,
+		IdempotencyKeys:    c.IdempotencyKeys,
+		CAFile:             c.CAFile,
+		ClientCert:         c.ClientCert,
+		ClientKey:          c.ClientKey,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+		ProxyURL:           c.ProxyURL,
+		NoProxy:            c.NoProxy,
+		HistoryRetention:   c.HistoryRetention,
+		HistoryMaxEntries:  c.HistoryMaxEntries,
+	}
+}
+
+// Copies the settings of `b` into `c`, leaving its credentials and credential
+// store as they are.
+func (b *bundleContext) apply(c *Context) {
+	c.Name = b.Name
+	c.URL = b.URL
+	c.ApiVersion = b.ApiVersion
+	c.Host = b.Host
+	c.MaxRetries = b.MaxRetries
+	c.IdempotencyKeys = b.IdempotencyKeys
+	c.CAFile = b.CAFile
+	c.ClientCert = b.ClientCert
+	c.ClientKey = b.ClientKey
+	c.InsecureSkipVerify = b.InsecureSkipVerify
+	c.ProxyURL = b.ProxyURL
+	c.NoProxy = b.NoProxy
+	c.HistoryRetention = b.HistoryRetention
+	c.HistoryMaxEntries = b.HistoryMaxEntries
+}
+
+// Returns true if `c` reaches its deployment the same way as `b`, i.e.
+// through the same URL, proxy and TLS settings. Otherwise the credentials
+// of `c` must not be sent with the settings of `b`.
+func (b *bundleContext) sameTransport(c *Context) bool {
+	return c.URL == b.URL &&
+		c.CAFile == b.CAFile &&
+		c.ClientCert == b.ClientCert &&
+		c.ClientKey == b.ClientKey &&
+		c.InsecureSkipVerify == b.InsecureSkipVerify &&
+		c.ProxyURL == b.ProxyURL &&
+		c.NoProxy == b.NoProxy
+}
+
+func (b *bundleContext) validate() error {
+	if b.Name == "" {
+		return errors.New("context without a name")
+	}
+	if u, err := url.ParseRequestURI(b.URL); err != nil || u.Host == "" {
+		return fmt.Errorf("context '%s' has invalid url '%s'", b.Name, b.URL)
+	}
+	if _, err := time.ParseDuration(b.HistoryRetention); b.HistoryRetention != "" && err != nil {
+		return fmt.Errorf("context '%s' has invalid history-retention '%s'", b.Name, b.HistoryRetention)
+	}
+	if b.ApiVersion == 0 {
+		b.ApiVersion = 1
+	}
+	return nil
+}
+
+var exportContextCmd = &cobra.Command{
+	Use:   "export [name...]",
+	Short: "Write the settings of contexts to a file which can be shared",
+	Long: `Writes the settings of the named contexts (or of all contexts) as YAML to
+stdout or the file given with '--file'. Only the settings needed to reach a
+deployment are included - URL, API version, host, retry, TLS, proxy and
+history settings. Credentials, account information and the credential store are
+never exported. Use 'ivcap context import' to add the contexts to another
+config.
+
+Note that the paths of CA and client certificate files are exported as they
+are, and need to exist on the importing machine.`,
+	Example: `  ivcap context export -f team-contexts.yaml
+  ivcap context export prod dev > contexts.yaml`,
+	RunE: func(_ *cobra.Command, args []string) error {
+		config, _ := ReadConfigFile(false)
+		bundle := contextBundle{Version: CONTEXT_BUNDLE_VERSION, Contexts: []bundleContext{}}
+		if len(args) == 0 {
+			for i := range config.Contexts {
+				bundle.Contexts = append(bundle.Contexts, toBundleContext(&config.Contexts[i]))
+			}
+		}
+		for _, name := range args {
+			ctxt, err := GetContextWithError(name, false)
+			if err != nil {
+				return err
+			}
+			bundle.Contexts = append(bundle.Contexts, toBundleContext(ctxt))
+		}
+		data, err := yaml.Marshal(&bundle)
+		if err != nil {
+			return err
+		}
+		if ctxtBundleFile == "" {
+			_, err = os.Stdout.Write(data)
+			return err
+		}
+		if err = os.WriteFile(ctxtBundleFile, data, fs.FileMode(0600)); err != nil {
+			return err
+		}
+		if !silent {
+			fmt.Printf("Exported %d context(s) to '%s'.\n", len(bundle.Contexts), ctxtBundleFile)
+		}
+		return nil
+	},
+}
+
+var importContextCmd = &cobra.Command{
+	Use:   "import file",
+	Short: "Add the contexts of a file written by 'context export'",
+	Long: `Adds the contexts in 'file' (or stdin if '-') to the config. Contexts which
+already exist are skipped, unless '--overwrite' is set. In that case their
+settings are replaced, but they stay logged in - unless the context now
+points to a different URL, or uses different TLS or proxy settings.`,
+	Example: `  ivcap context import team-contexts.yaml`,
+	Args:    cobra.ExactArgs(1),
+	RunE: func(_ *cobra.Command, args []string) error {
+		bundle, err := readContextBundle(args[0])
+		if err != nil {
+			return err
+		}
+		importContexts(bundle, ctxtImportOverwrite)
+		return nil
+	},
+}
+
+func readContextBundle(fileName string) (*contextBundle, error) {
+	var data []byte
+	var err error
+	if fileName == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(filepath.Clean(fileName))
+	}
+	if err != nil {
+		return nil, err
+	}
+	var bundle contextBundle
+	if err = yaml.UnmarshalStrict(data, &bundle); err != nil {
+		return nil, fmt.Errorf("cannot parse context file '%s' - %w", fileName, err)
+	}
+	if bundle.Version != CONTEXT_BUNDLE_VERSION {
+		return nil, fmt.Errorf("unsupported version '%s' of context file '%s'", bundle.Version, fileName)
+	}
+	for i := range bundle.Contexts {
+		if err = bundle.Contexts[i].validate(); err != nil {
+			return nil, fmt.Errorf("context file '%s': %w", fileName, err)
+		}
+	}
+	return &bundle, nil
+}
+
+// Merges the contexts of `bundle` into the config file. Existing contexts
+// are only changed if `overwrite` is set.
+func importContexts(bundle *contextBundle, overwrite bool) {
+	var relogin, insecure []*Context
+	var messages []string
+	UpdateConfigFile(true, func(config *Config) {
+		for _, b := range bundle.Contexts {
+			i := slices.IndexFunc(config.Contexts, func(c Context) bool { return c.Name == b.Name })
+			switch {
+			case i < 0:
+				var c Context
+				b.apply(&c)
+				config.Contexts = append(config.Contexts, c)
+				insecure = append(insecure, &c)
+				messages = append(messages, fmt.Sprintf("Context '%s' imported.", b.Name))
+			case overwrite:
+				c := &config.Contexts[i]
+				msg := fmt.Sprintf("Context '%s' updated.", b.Name)
+				if !b.sameTransport(c) {
+					// don't send the credentials to a different deployment,
+					// or through a proxy or TLS setup the user hasn't chosen
+					prev := *c
+					relogin = append(relogin, &prev)
+					clearLogin(c)
+					msg = fmt.Sprintf("Context '%s' updated, please login again as its URL, TLS or proxy settings changed.", b.Name)
+				}
+				b.apply(c)
+				insecure = append(insecure, c)
+				messages = append(messages, msg)
+			default:
+				messages = append(messages, fmt.Sprintf("Context '%s' exists, skipped.", b.Name))
+			}
+		}
+		if config.ActiveContext == "" && len(config.Contexts) > 0 {
+			config.ActiveContext = config.Contexts[0].Name
+		}
+	})
+	for _, c := range relogin {
+		if !usesPlaintextStore(c) {
+			if err := deleteCredentials(c); err != nil {
+				fmt.Fprintf(os.Stderr, "WARNING: %v\n", err)
+			}
+		}
+	}
+	if !silent {
+		for _, m := range messages {
+			fmt.Println(m)
+		}
+	}
+	for _, c := range insecure {
+		warnIfInsecure(c)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+func TestContextBundle_IsCredentialFree(t *testing.T) {
+	retries := 2
+	b := toBundleContext(&Context{
+		Name: "prod", URL: "https://ivcap.example.com", ApiVersion: 1, Host: "api.internal", MaxRetries: &retries,
+		AccessToken: "secret-access", RefreshToken: "secret-refresh", IDToken: "secret-id",
+		AccountID: "urn:ivcap:account:1", Email: "me@example.com", ClientID: "svc", ClientSecretFile: "/secret",
+		CredentialStore: FILE_CREDENTIAL_STORE, CredentialKeyFile: "/key",
+		HistoryRetention: "24h0m0s", HistoryMaxEntries: 20,
+	})
+	data, err := yaml.Marshal(&contextBundle{Version: CONTEXT_BUNDLE_VERSION, Contexts: []bundleContext{b}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"secret", "account", "me@", "svc", "/key"} {
+		if strings.Contains(string(data), s) {
+			t.Errorf("expected no '%s' in exported context\n%s", s, data)
+		}
+	}
+	if !strings.Contains(string(data), "host: api.internal") || !strings.Contains(string(data), "max-retries: 2") ||
+		!strings.Contains(string(data), "history-retention: 24h0m0s") || !strings.Contains(string(data), "history-max-entries: 20") {
+		t.Errorf("expected deployment settings in exported context\n%s", data)
+	}
+}
+
+func TestImportContexts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetContext(&Context{Name: "keep", URL: "https://keep.example.com", AccessToken: "t1"}, false)
+	SetContext(&Context{Name: "moved", URL: "https://old.example.com", AccessToken: "t2", AccountID: "a2"}, false)
+	SetContext(&Context{Name: "proxied", URL: "https://proxied.example.com", AccessToken: "t3"}, false)
+
+	file := filepath.Join(t.TempDir(), "bundle.yaml")
+	if err := os.WriteFile(file, []byte(`version: v1
+contexts:
+- name: keep
+  url: https://keep.example.com
+  host: keep.internal
+- name: moved
+  url: https://new.example.com
+- name: proxied
+  url: https://proxied.example.com
+  proxy-url: http://proxy.example.com:3128
+- name: added
+  url: https://added.example.com
+`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	bundle, err := readContextBundle(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	importContexts(bundle, false)
+	if c := GetContext("keep", false); c.Host != "" {
+		t.Errorf("expected existing context to be left alone, got host '%s'", c.Host)
+	}
+	if c := GetContext("added", false); c.URL != "https://added.example.com" || c.ApiVersion != 1 {
+		t.Errorf("unexpected imported context %+v", c)
+	}
+
+	importContexts(bundle, true)
+	if c := GetContext("keep", false); c.Host != "keep.internal" || c.AccessToken != "t1" {
+		t.Errorf("expected updated settings and kept login, got %+v", c)
+	}
+	if c := GetContext("moved", false); c.URL != "https://new.example.com" || c.AccessToken != "" || c.AccountID != "" {
+		t.Errorf("expected login to be dropped for new URL, got %+v", c)
+	}
+	if c := GetContext("proxied", false); c.ProxyURL == "" || c.AccessToken != "" {
+		t.Errorf("expected login to be dropped for new proxy, got %+v", c)
+	}
+}
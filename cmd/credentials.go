@@ -0,0 +1,191 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Names of the supported credential stores
+const (
+	PLAINTEXT_CREDENTIAL_STORE = "plaintext" // in config.yaml, the default
+	KEYRING_CREDENTIAL_STORE   = "keyring"   // OS keychain, e.g. Secret Service on Linux
+	FILE_CREDENTIAL_STORE      = "file"      // age encrypted file per context
+)
+
+var CREDENTIAL_STORES = []string{PLAINTEXT_CREDENTIAL_STORE, KEYRING_CREDENTIAL_STORE, FILE_CREDENTIAL_STORE}
+
+// Directory below the config directory holding the encrypted credential files
+const CREDENTIALS_DIR_NAME = "credentials"
+
+// Credentials are the tokens obtained for a context when logging in.
+type Credentials struct {
+	AccessToken       string    `json:"access-token"`
+	AccessTokenExpiry time.Time `json:"access-token-expiry"`
+	RefreshToken      string    `json:"refresh-token"`
+	IDToken           string    `json:"id-token,omitempty"`
+}
+
+func (c *Credentials) IsEmpty() bool {
+	return c.AccessToken == "" && c.RefreshToken == ""
+}
+
+// CredentialStore keeps the credentials of contexts.
+type CredentialStore interface {
+	// Load returns the credentials stored for `ctxt`, or empty credentials
+	// if there are none.
+	Load(ctxt *Context) (*Credentials, error)
+	// Save replaces the credentials stored for `ctxt`.
+	Save(ctxt *Context, creds *Credentials) error
+	// Delete removes any credentials stored for `ctxt`.
+	Delete(ctxt *Context) error
+}
+
+// Returns the store named `name` for the credentials of `ctxt`.
+func getCredentialStore(name string, ctxt *Context) (CredentialStore, error) {
+	switch name {
+	case "", PLAINTEXT_CREDENTIAL_STORE:
+		return &plaintextStore{}, nil
+	case KEYRING_CREDENTIAL_STORE:
+		return &keyringStore{}, nil
+	case FILE_CREDENTIAL_STORE:
+		return &fileStore{keyFile: ctxt.CredentialKeyFile}, nil
+	default:
+		return nil, fmt.Errorf("unknown credential store '%s', expected one of '%s'",
+			name, strings.Join(CREDENTIAL_STORES, "', '"))
+	}
+}
+
+func usesPlaintextStore(ctxt *Context) bool {
+	return ctxt.CredentialStore == "" || ctxt.CredentialStore == PLAINTEXT_CREDENTIAL_STORE
+}
+
+// credentials already loaded by this process, indexed by context name
+var (
+	loadedCredentials   = map[string]*Credentials{}
+	loadedCredentialsMu sync.Mutex
+)
+
+// loadCredentials fills in the tokens of `ctxt` from its credential store.
+func loadCredentials(ctxt *Context) error {
+	loadedCredentialsMu.Lock()
+	defer loadedCredentialsMu.Unlock()
+	creds, ok := loadedCredentials[ctxt.Name]
+	if !ok {
+		store, err := getCredentialStore(ctxt.CredentialStore, ctxt)
+		if err != nil {
+			return err
+		}
+		if creds, err = store.Load(ctxt); err != nil {
+			return fmt.Errorf("cannot load credentials of context '%s' - %w", ctxt.Name, err)
+		}
+		loadedCredentials[ctxt.Name] = creds
+	}
+	setCredentials(ctxt, creds)
+	return nil
+}
+
+// saveCredentials writes the tokens of `ctxt` to its credential store.
+func saveCredentials(ctxt *Context) error {
+	store, err := getCredentialStore(ctxt.CredentialStore, ctxt)
+	if err != nil {
+		return err
+	}
+	creds := getCredentials(ctxt)
+	if err = store.Save(ctxt, creds); err != nil {
+		return fmt.Errorf("cannot save credentials of context '%s' - %w", ctxt.Name, err)
+	}
+	loadedCredentialsMu.Lock()
+	loadedCredentials[ctxt.Name] = creds
+	loadedCredentialsMu.Unlock()
+	return nil
+}
+
+// deleteCredentials removes the tokens of `ctxt` from its credential store.
+func deleteCredentials(ctxt *Context) error {
+	store, err := getCredentialStore(ctxt.CredentialStore, ctxt)
+	if err != nil {
+		return err
+	}
+	if err = store.Delete(ctxt); err != nil {
+		return fmt.Errorf("cannot delete credentials of context '%s' - %w", ctxt.Name, err)
+	}
+	setCredentials(ctxt, &Credentials{})
+	loadedCredentialsMu.Lock()
+	delete(loadedCredentials, ctxt.Name)
+	loadedCredentialsMu.Unlock()
+	return nil
+}
+
+func getCredentials(ctxt *Context) *Credentials {
+	return &Credentials{
+		AccessToken:       ctxt.AccessToken,
+		AccessTokenExpiry: ctxt.AccessTokenExpiry,
+		RefreshToken:      ctxt.RefreshToken,
+		IDToken:           ctxt.IDToken,
+	}
+}
+
+func setCredentials(ctxt *Context, creds *Credentials) {
+	ctxt.AccessToken = creds.AccessToken
+	ctxt.AccessTokenExpiry = creds.AccessTokenExpiry
+	ctxt.RefreshToken = creds.RefreshToken
+	ctxt.IDToken = creds.IDToken
+}
+
+// Returns the directory for credential files, creating it if necessary.
+func getCredentialsDir() (string, error) {
+	dir := filepath.Join(GetConfigDir(true), CREDENTIALS_DIR_NAME)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// Returns a file name for context `name` which is safe to use on any platform.
+func credentialsFileName(name string) string {
+	return url.QueryEscape(name)
+}
+
+// ****** PLAINTEXT ****
+
+// plaintextStore keeps the credentials in the context entry of the config file.
+type plaintextStore struct{}
+
+func (s *plaintextStore) Load(ctxt *Context) (*Credentials, error) {
+	// always read the current version, it may have been refreshed by another process
+	stored, err := GetContextWithError(ctxt.Name, false)
+	if err != nil {
+		return nil, err
+	}
+	return getCredentials(stored), nil
+}
+
+func (s *plaintextStore) Save(ctxt *Context, creds *Credentials) error {
+	UpdateContext(ctxt.Name, func(stored *Context) {
+		setCredentials(stored, creds)
+	})
+	return nil
+}
+
+func (s *plaintextStore) Delete(ctxt *Context) error {
+	return s.Save(ctxt, &Credentials{})
+}
@@ -0,0 +1,199 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"filippo.io/age"
+	"golang.org/x/term"
+)
+
+var CREDENTIALS_PASSPHRASE_ENV = ENV_PREFIX + "_CREDENTIALS_PASSPHRASE"
+
+// fileStore keeps the credentials of each context in an age encrypted file
+// below the config directory. The file is either encrypted with the age
+// identity in `keyFile`, or with a passphrase taken from the environment or
+// asked for on the terminal.
+type fileStore struct {
+	keyFile string
+}
+
+// the passphrase entered on the terminal, only asked for once per process
+var (
+	credentialsPassphrase   string
+	credentialsPassphraseMu sync.Mutex
+)
+
+func (s *fileStore) Load(ctxt *Context) (*Credentials, error) {
+	path, err := s.path(ctxt)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Credentials{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	identities, err := s.identities()
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identities...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decrypt '%s' - %w", path, err)
+	}
+	var creds Credentials
+	if err = json.NewDecoder(r).Decode(&creds); err != nil {
+		return nil, fmt.Errorf("cannot parse '%s' - %w", path, err)
+	}
+	return &creds, nil
+}
+
+func (s *fileStore) Save(ctxt *Context, creds *Credentials) error {
+	if creds.IsEmpty() {
+		return s.Delete(ctxt)
+	}
+	path, err := s.path(ctxt)
+	if err != nil {
+		return err
+	}
+	recipients, err := s.recipients()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipients...)
+	if err != nil {
+		return err
+	}
+	if err = json.NewEncoder(w).Encode(creds); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return writeFileLocked(path, buf.Bytes(), fs.FileMode(0600))
+}
+
+func (s *fileStore) Delete(ctxt *Context) error {
+	path, err := s.path(ctxt)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *fileStore) path(ctxt *Context) (string, error) {
+	dir, err := getCredentialsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, credentialsFileName(ctxt.Name)+".age"), nil
+}
+
+func (s *fileStore) identities() ([]age.Identity, error) {
+	if s.keyFile != "" {
+		f, err := os.Open(filepath.Clean(s.keyFile))
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		identities, err := age.ParseIdentities(f)
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse age key file '%s' - %w", s.keyFile, err)
+		}
+		return identities, nil
+	}
+	passphrase, err := getCredentialsPassphrase()
+	if err != nil {
+		return nil, err
+	}
+	id, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return []age.Identity{id}, nil
+}
+
+func (s *fileStore) recipients() ([]age.Recipient, error) {
+	if s.keyFile == "" {
+		passphrase, err := getCredentialsPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		r, err := age.NewScryptRecipient(passphrase)
+		if err != nil {
+			return nil, err
+		}
+		return []age.Recipient{r}, nil
+	}
+	identities, err := s.identities()
+	if err != nil {
+		return nil, err
+	}
+	recipients := make([]age.Recipient, 0, len(identities))
+	for _, id := range identities {
+		switch i := id.(type) {
+		case *age.X25519Identity:
+			recipients = append(recipients, i.Recipient())
+		case *age.HybridIdentity:
+			recipients = append(recipients, i.Recipient())
+		}
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no usable age identity in key file '%s'", s.keyFile)
+	}
+	return recipients, nil
+}
+
+// Returns the passphrase for the credential files from the environment, or
+// asks for it on the terminal.
+func getCredentialsPassphrase() (string, error) {
+	if p := os.Getenv(CREDENTIALS_PASSPHRASE_ENV); p != "" {
+		return p, nil
+	}
+	credentialsPassphraseMu.Lock()
+	defer credentialsPassphraseMu.Unlock()
+	if credentialsPassphrase != "" {
+		return credentialsPassphrase, nil
+	}
+	fd := int(os.Stdin.Fd()) // #nosec G115
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("credentials are encrypted, provide the passphrase via env '%s'", CREDENTIALS_PASSPHRASE_ENV)
+	}
+	fmt.Fprint(os.Stderr, "Passphrase for credentials: ")
+	p, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if len(p) == 0 {
+		return "", errors.New("empty passphrase")
+	}
+	credentialsPassphrase = string(p)
+	return credentialsPassphrase, nil
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Service name under which credentials are kept in the OS keychain
+const KEYRING_SERVICE = "ivcap-cli"
+
+// keyringStore keeps the credentials in the keychain of the OS, which is
+// the Secret Service (e.g. GNOME Keyring, KWallet) on Linux, the Keychain on
+// macOS, and the Credential Manager on Windows.
+type keyringStore struct{}
+
+func (s *keyringStore) Load(ctxt *Context) (*Credentials, error) {
+	secret, err := keyring.Get(KEYRING_SERVICE, ctxt.Name)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return &Credentials{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var creds Credentials
+	if err = json.Unmarshal([]byte(secret), &creds); err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+func (s *keyringStore) Save(ctxt *Context, creds *Credentials) error {
+	if creds.IsEmpty() {
+		return s.Delete(ctxt)
+	}
+	secret, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	return keyring.Set(KEYRING_SERVICE, ctxt.Name, string(secret))
+}
+
+func (s *keyringStore) Delete(ctxt *Context) error {
+	if err := keyring.Delete(KEYRING_SERVICE, ctxt.Name); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"filippo.io/age"
+	"github.com/zalando/go-keyring"
+)
+
+func TestCredentialStores_RoundTrip(t *testing.T) {
+	keyring.MockInit()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(CREDENTIALS_PASSPHRASE_ENV, "secret")
+
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyFile := filepath.Join(t.TempDir(), "key.txt")
+	if err = os.WriteFile(keyFile, []byte(id.String()+"\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	creds := &Credentials{AccessToken: "at", RefreshToken: "rt", AccessTokenExpiry: time.Now().Add(time.Hour).UTC()}
+	for _, ctxt := range []*Context{
+		{Name: "plain"},
+		{Name: "keyring", CredentialStore: KEYRING_CREDENTIAL_STORE},
+		{Name: "passphrase", CredentialStore: FILE_CREDENTIAL_STORE},
+		{Name: "age/key", CredentialStore: FILE_CREDENTIAL_STORE, CredentialKeyFile: keyFile},
+	} {
+		SetContext(ctxt, false)
+		store, err := getCredentialStore(ctxt.CredentialStore, ctxt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = store.Save(ctxt, creds); err != nil {
+			t.Fatalf("%s: cannot save - %v", ctxt.Name, err)
+		}
+		got, err := store.Load(ctxt)
+		if err != nil {
+			t.Fatalf("%s: cannot load - %v", ctxt.Name, err)
+		}
+		if *got != *creds {
+			t.Errorf("%s: expected %+v, got %+v", ctxt.Name, creds, got)
+		}
+		if err = store.Delete(ctxt); err != nil {
+			t.Fatalf("%s: cannot delete - %v", ctxt.Name, err)
+		}
+		if got, err = store.Load(ctxt); err != nil || !got.IsEmpty() {
+			t.Errorf("%s: expected no credentials after delete, got %+v (%v)", ctxt.Name, got, err)
+		}
+	}
+}
+
+func TestWriteConfigFile_OmitsStoredCredentials(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	WriteConfigFile(&Config{Version: "v1", Contexts: []Context{
+		{Name: "plain", AccessToken: "plain-token"},
+		{Name: "keyring", CredentialStore: KEYRING_CREDENTIAL_STORE, AccessToken: "keyring-token"},
+	}})
+	data, err := os.ReadFile(GetConfigFilePath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "plain-token") || strings.Contains(string(data), "keyring-token") {
+		t.Errorf("expected only the plaintext token in config file, got\n%s", data)
+	}
+}
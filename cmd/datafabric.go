@@ -110,7 +110,7 @@ var (
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			aspectID := GetHistory(args[0])
-			ctxt := context.Background()
+			ctxt := RootContext()
 			_, err = sdk.RetractAspect(ctxt, aspectID, CreateAdapter(true), logger)
 			return
 		},
@@ -123,7 +123,7 @@ var (
 		// Long:    `.....`,
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			if entityURN == "" && schemaPrefix == "" && page == "" {
-				cobra.CheckErr("Need at least one of '--schema-prefix', '--entity' or '--page'")
+				checkErr("Need at least one of '--schema-prefix', '--entity' or '--page'")
 			}
 			if entityURN != "" {
 				entityURN = GetHistory(entityURN)
@@ -139,44 +139,44 @@ var (
 				selector.JsonFilter = &aspectJsonFilter
 			}
 
-			ctxt := context.Background()
-			if list, res, err := sdk.ListAspect(ctxt, selector, CreateAdapter(true), logger); err == nil {
-				if aspectGetIfOne && len(list.Items) == 1 {
-					return getAspect(*list.Items[0].ID)
+			adapter := CreateAdapter(true)
+			first := func(ctxt context.Context) (a.Payload, error) {
+				_, res, err := sdk.ListAspect(ctxt, selector, adapter, logger)
+				return res, err
+			}
+			if aspectGetIfOne {
+				list, res, err := sdk.ListAspect(RootContext(), selector, adapter, logger)
+				if err != nil {
+					return err
 				}
-				switch outputFormat {
-				case "json":
-					return a.ReplyPrinter(res, false)
-				case "yaml":
-					return a.ReplyPrinter(res, true)
-				default:
-					printAspectTable(list, false)
+				if len(list.Items) == 1 {
+					return getAspect(*list.Items[0].ID)
 				}
-				return nil
-			} else {
-				return err
+				// don't fetch the first page twice
+				first = func(context.Context) (a.Payload, error) { return res, nil }
 			}
+			return printList(
+				first,
+				adapter,
+				func(list *api.ListResponseBody) *[]*api.AspectListItemRTResponseBody { return &list.Items },
+				func(list *api.ListResponseBody, next *string) { printAspectTable(list, next, isWideOutput()) },
+			)
 		},
 	}
 )
 
 func getAspect(aspectID string) error {
-	ctxt := context.Background()
+	ctxt := RootContext()
 	if aspectContentOnly && outputFormat == "" {
 		outputFormat = "yaml"
 	}
-	switch outputFormat {
-	case "json", "yaml":
+	switch {
+	case !isTableOutput():
 		if res, err := sdk.GetAspectRaw(ctxt, aspectID, CreateAdapter(true), logger); err == nil {
 			if aspectContentOnly {
 				if o, err := res.AsObject(); err == nil {
 					if c, ok := o["content"]; ok {
-						if s, err := a.ToString(c, outputFormat == "yaml"); err == nil {
-							fmt.Printf("%s\n", s)
-							return nil
-						} else {
-							return err
-						}
+						return printValue(c)
 					} else {
 						return fmt.Errorf("aspect does not contain a 'Content' field")
 					}
@@ -184,7 +184,7 @@ func getAspect(aspectID string) error {
 					return err
 				}
 			}
-			return a.ReplyPrinter(res, outputFormat == "yaml")
+			return printReply(res)
 		} else {
 			return err
 		}
@@ -202,12 +202,12 @@ func addAspectUpdateCmd(isAdd bool, cmd *cobra.Command, args []string) (err erro
 	entity := args[0]
 	pyld, err := payloadFromFile(aspectFile, inputFormat)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("While reading aspect file '%s' - %s", aspectFile, err))
+		checkErr(fmt.Sprintf("While reading aspect file '%s' - %s", aspectFile, err))
 	}
 
 	aspect, err := pyld.AsObject()
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("Cannot parse aspect file '%s' - %s", aspectFile, err))
+		checkErr(fmt.Sprintf("Cannot parse aspect file '%s' - %s", aspectFile, err))
 	}
 	var schema string
 	schema = schemaURN
@@ -215,11 +215,11 @@ func addAspectUpdateCmd(isAdd bool, cmd *cobra.Command, args []string) (err erro
 		if s, ok := aspect["$schema"]; ok {
 			schema = fmt.Sprintf("%s", s)
 		} else {
-			cobra.CheckErr("Missing schema name")
+			checkErr("Missing schema name")
 		}
 	}
 	logger.Debug("add/update aspect", log.String("entity", entity), log.String("schema", schema), log.Reflect("pyld", aspect))
-	ctxt := context.Background()
+	ctxt := RootContext()
 	res, err := sdk.AddUpdateAspect(ctxt, isAdd, entity, schema, policy, pyld.AsBytes(), CreateAdapter(true), logger)
 	if err != nil {
 		return err
@@ -228,10 +228,10 @@ func addAspectUpdateCmd(isAdd bool, cmd *cobra.Command, args []string) (err erro
 		if m, err := res.AsObject(); err == nil {
 			fmt.Printf("%s\n", m["id"])
 		} else {
-			cobra.CheckErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
+			checkErr(fmt.Sprintf("Parsing reply: %s", res.AsBytes()))
 		}
 	} else {
-		return a.ReplyPrinter(res, outputFormat == "yaml")
+		return printReply(res)
 	}
 	return nil
 }
@@ -287,7 +287,7 @@ func printAspectDetail(res *api.ReadResponseBody) {
 	fmt.Printf("\n%s\n\n", tw.Render())
 }
 
-func printAspectTable(list *api.ListResponseBody, wide bool) {
+func printAspectTable(list *api.ListResponseBody, next *string, wide bool) {
 	tw2 := table.NewWriter()
 	tw2.AppendHeader(table.Row{"ID", "Entity", "Schema"})
 	tw2.SetStyle(table.StyleLight)
@@ -318,20 +318,8 @@ func printAspectTable(list *api.ListResponseBody, wide bool) {
 		p = append(p, table.Row{"At Time", safeDate(list.AtTime, false)})
 	}
 	p = append(p, table.Row{"Records", tw2.Render()})
-	p = addNextPageRow(findNextAspectPage(list.Links), p)
+	p = addNextPageRow(next, p)
 	tw.AppendRows(p)
 
 	fmt.Printf("\n%s\n\n", tw.Render())
 }
-
-func findNextAspectPage(links []*api.LinkTResponseBody) *string {
-	if links == nil {
-		return nil
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return l.Href
-		}
-	}
-	return nil
-}
@@ -0,0 +1,537 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	dockerclient "github.com/docker/docker/client"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+	adpt "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	mcppkg "github.com/ivcap-works/ivcap-cli/pkg/mcp"
+)
+
+// Results of a single check of 'doctor'
+const (
+	CHECK_PASS = "pass"
+	CHECK_WARN = "warn"
+	CHECK_FAIL = "fail"
+	CHECK_SKIP = "skip" // a check it depends on failed
+)
+
+// Clock skew against the deployment above which tokens may be rejected
+// (warn), or are very likely to be (fail)
+const DOCTOR_CLOCK_SKEW_WARN = 30 * time.Second
+const DOCTOR_CLOCK_SKEW_FAIL = 5 * time.Minute
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+var doctorCmd = &cobra.Command{
+	Use:     "doctor",
+	Short:   "Check the local setup and the connection to the deployment",
+	GroupID: generalSupportGroupID,
+	Long: `Runs a number of checks on the local setup and the active context, and
+reports each as passed, warning or failed, with a hint on how to fix it:
+
+  config      the config file is readable, valid and not readable by others
+  context     there is an active context with a valid URL
+  transport   the TLS and proxy settings of the context
+  deployment  the deployment returns its '/1/authinfo.yaml'
+  clock       the local clock agrees with the deployment's 'Date' header
+  token       there is an access token, refreshed if it has expired, which
+              is accepted by the deployment
+  docker      the docker daemon used by 'package push' is running
+  registry    the docker registry of the deployment is reachable
+  mcp         'ivcap mcp' can connect to the deployment without a login
+  version     this is the latest release
+
+The command fails if any check fails.`,
+	Example: `  ivcap doctor
+  ivcap --context prod doctor -o json`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		report := runDoctor()
+		if !isTableOutput() {
+			if err := printValue(report); err != nil {
+				return err
+			}
+		} else {
+			printDoctorReport(report)
+		}
+		if report.Failures > 0 {
+			return fmt.Errorf("%d of %d checks failed", report.Failures, len(report.Checks))
+		}
+		return nil
+	},
+}
+
+type doctorCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"` // one of CHECK_...
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type doctorReport struct {
+	Context  string        `json:"context,omitempty"`
+	Checks   []doctorCheck `json:"checks"`
+	Passed   int           `json:"passed"`
+	Warnings int           `json:"warnings"`
+	Failures int           `json:"failures"`
+}
+
+type doctor struct {
+	report doctorReport
+	ctxt   *Context
+}
+
+func (d *doctor) add(name, status, message, hint string) {
+	d.report.Checks = append(d.report.Checks, doctorCheck{Name: name, Status: status, Message: message, Hint: hint})
+	switch status {
+	case CHECK_PASS:
+		d.report.Passed++
+	case CHECK_WARN:
+		d.report.Warnings++
+	case CHECK_FAIL:
+		d.report.Failures++
+	}
+}
+
+func (d *doctor) skip(message string, names ...string) {
+	for _, name := range names {
+		d.add(name, CHECK_SKIP, message, "")
+	}
+}
+
+func runDoctor() *doctorReport {
+	d := &doctor{}
+	configOK := d.checkConfig()
+	if !configOK {
+		d.skip("no usable config file", "context")
+	}
+	if configOK && d.checkContext() {
+		d.report.Context = d.ctxt.Name
+		if d.checkTransport() {
+			if pyld := d.checkDeployment(); pyld != nil {
+				d.checkClock(pyld)
+			} else {
+				d.skip("deployment is unreachable", "clock")
+			}
+			d.checkToken()
+			d.checkDocker()
+			d.checkRegistry()
+			d.checkMCP()
+		} else {
+			d.skip("TLS or proxy settings are invalid", "deployment", "clock", "token", "docker", "registry", "mcp")
+		}
+	} else {
+		d.skip("no usable context", "transport", "deployment", "clock", "token", "docker", "registry", "mcp")
+	}
+	d.checkVersion()
+	return &d.report
+}
+
+func (d *doctor) checkConfig() bool {
+	const name = "config"
+	dir := GetConfigDir(false)
+	path := filepath.Join(dir, CONFIG_FILE_NAME)
+	data, err := os.ReadFile(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("there is no config file '%s'", path),
+			"create a context with 'ivcap context create NAME URL'")
+		return false
+	} else if err != nil {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("cannot read config file '%s' - %v", path, err),
+			"check the ownership and permissions of the config file")
+		return false
+	}
+	var config Config
+	if err = yaml.Unmarshal(data, &config); err != nil {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("cannot parse config file '%s' - %v", path, err),
+			"fix the file, or move it away and create the contexts again")
+		return false
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err == nil && info.Mode().Perm()&0077 != 0 {
+			d.add(name, CHECK_WARN, fmt.Sprintf("config file '%s' is accessible by other users (%s)", path, info.Mode().Perm()),
+				fmt.Sprintf("restrict access with 'chmod 600 %s'", path))
+			return true
+		}
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("'%s' with %d context(s)", path, len(config.Contexts)), "")
+	return true
+}
+
+func (d *doctor) checkContext() bool {
+	const name = "context"
+	ctxt, err := GetContextWithError(contextName, true)
+	if err != nil {
+		d.add(name, CHECK_FAIL, err.Error(), "select a context with 'ivcap context set NAME' or '--context NAME'")
+		return false
+	}
+	d.ctxt = ctxt
+	u, err := url.ParseRequestURI(ctxt.URL)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("context '%s' has invalid URL '%s'", ctxt.Name, ctxt.URL),
+			fmt.Sprintf("recreate it with 'ivcap context create %s https://...'", ctxt.Name))
+		return false
+	}
+	if u.Scheme == "http" && !isLoopback(u.Hostname()) {
+		d.add(name, CHECK_WARN, fmt.Sprintf("context '%s' uses plain HTTP for '%s', credentials are sent unencrypted", ctxt.Name, ctxt.URL),
+			"use the 'https' URL of the deployment")
+		return true
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("'%s' at %s", ctxt.Name, ctxt.URL), "")
+	return true
+}
+
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (d *doctor) checkTransport() bool {
+	const name = "transport"
+	cfg := transportConfig(d.ctxt)
+	t, err := adpt.NewTransport(cfg)
+	if err != nil {
+		d.add(name, CHECK_FAIL, err.Error(),
+			"fix the settings with 'ivcap context update NAME --ca-file, --client-cert, --client-key or --proxy-url'")
+		return false
+	}
+	var details []string
+	if cfg.CAFile != "" {
+		details = append(details, fmt.Sprintf("CA file '%s'", cfg.CAFile))
+	}
+	if cfg.ClientCert != "" {
+		details = append(details, fmt.Sprintf("client certificate '%s'", cfg.ClientCert))
+	}
+	req, _ := http.NewRequest(http.MethodGet, d.ctxt.URL, nil)
+	if proxy, err := t.Proxy(req); err != nil {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("invalid proxy - %v", err), "check the HTTPS_PROXY environment variable")
+		return false
+	} else if proxy != nil {
+		details = append(details, fmt.Sprintf("proxy '%s'", proxy.Redacted()))
+	} else {
+		details = append(details, "no proxy")
+	}
+	message := strings.Join(details, ", ")
+	if cfg.InsecureSkipVerify {
+		d.add(name, CHECK_WARN, message+", certificate verification disabled",
+			fmt.Sprintf("provide the deployment's CA with 'ivcap context update %s --ca-file ... --insecure-skip-verify=false'", d.ctxt.Name))
+		return true
+	}
+	d.add(name, CHECK_PASS, message, "")
+	return true
+}
+
+// Returns an adapter for the deployment of the current context, which uses
+// `token` as is and doesn't retry failed requests.
+func (d *doctor) adapter(token string) (*adpt.Adapter, error) {
+	t, err := adpt.NewTransport(transportConfig(d.ctxt))
+	if err != nil {
+		return nil, err
+	}
+	policy := retryPolicy(d.ctxt)
+	policy.MaxRetries = 0
+	var headers *map[string]string
+	if d.ctxt.Host != "" {
+		headers = &(map[string]string{"Host": d.ctxt.Host})
+	}
+	return NewAdapter(d.ctxt.URL, token, timeout, headers, adpt.WithRetryPolicy(policy), adpt.WithTransport(t))
+}
+
+func (d *doctor) checkDeployment() adpt.Payload {
+	const name = "deployment"
+	adapter, err := d.adapter("")
+	if err != nil {
+		d.add(name, CHECK_FAIL, err.Error(), "")
+		return nil
+	}
+	ctx, cancel := NewTimeoutContext()
+	defer cancel()
+	start := time.Now()
+	pyld, err := (*adapter).Get(ctx, "/1/authinfo.yaml", logger)
+	if err != nil {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("cannot get '/1/authinfo.yaml' - %v", err),
+			"check the context's URL, your network connection and proxy settings")
+		return nil
+	}
+	elapsed := time.Since(start).Round(time.Millisecond)
+	var ai AuthInfo
+	if err = yaml.Unmarshal(pyld.AsBytes(), &ai); err != nil {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("cannot parse '/1/authinfo.yaml' - %v", err),
+			"check that the context's URL is the API of an IVCAP deployment")
+		return pyld
+	}
+	if ai.Version != 1 {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("unsupported authinfo version %d", ai.Version),
+			"upgrade ivcap, e.g. with 'brew upgrade ivcap'")
+		return pyld
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("replied in %s with %d auth provider(s)", elapsed, len(ai.ProviderList.AuthProviders)), "")
+	return pyld
+}
+
+func (d *doctor) checkClock(pyld adpt.Payload) {
+	const name = "clock"
+	date := pyld.Header("Date")
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		d.add(name, CHECK_WARN, "deployment didn't return a valid 'Date' header", "")
+		return
+	}
+	// 'Date' has a resolution of a second
+	skew := time.Since(serverTime).Round(time.Second)
+	abs := max(skew, -skew)
+	hint := "synchronise the clock of this machine, e.g. through NTP"
+	switch {
+	case abs > DOCTOR_CLOCK_SKEW_FAIL:
+		d.add(name, CHECK_FAIL, fmt.Sprintf("local clock is off by %s, tokens will be rejected", skew), hint)
+	case abs > DOCTOR_CLOCK_SKEW_WARN:
+		d.add(name, CHECK_WARN, fmt.Sprintf("local clock is off by %s", skew), hint)
+	default:
+		d.add(name, CHECK_PASS, fmt.Sprintf("local clock is off by %s", skew), "")
+	}
+}
+
+func (d *doctor) checkToken() {
+	const name = "token"
+	token, source := accessTokenF, "'--access-token'"
+	if token == "" {
+		token, source = os.Getenv(ACCESS_TOKEN_ENV), fmt.Sprintf("'%s'", ACCESS_TOKEN_ENV)
+	}
+	if envFlags["access-token"] != "" {
+		source = fmt.Sprintf("'%s'", envFlags["access-token"])
+	}
+	var expiry time.Time
+	if token != "" {
+		claims := jwt.RegisteredClaims{}
+		if _, err := decodeToken(token, &claims); err != nil {
+			d.add(name, CHECK_WARN, fmt.Sprintf("cannot decode token from %s - %v", source, err), "")
+		} else if claims.ExpiresAt != nil {
+			expiry = claims.ExpiresAt.Time
+		}
+	} else {
+		source = "context"
+		if err := loadCredentials(d.ctxt); err != nil {
+			d.add(name, CHECK_FAIL, err.Error(), "check the credential store of the context, see 'ivcap context get'")
+			return
+		}
+		if d.ctxt.AccessToken == "" && d.ctxt.RefreshToken == "" && d.ctxt.ClientID == "" {
+			d.add(name, CHECK_FAIL, fmt.Sprintf("not logged into context '%s'", d.ctxt.Name),
+				"log in with 'ivcap context login'")
+			return
+		}
+		token, expiry = d.ctxt.AccessToken, d.ctxt.AccessTokenExpiry
+		if token == "" || (!expiry.IsZero() && time.Now().After(expiry)) {
+			state := "there is no access token"
+			if token != "" {
+				state = fmt.Sprintf("access token expired %s", expiry.Local().Format(time.RFC822))
+			}
+			if d.ctxt.RefreshToken == "" && d.ctxt.ClientID == "" {
+				d.add(name, CHECK_FAIL, state+" and cannot be refreshed", "log in again with 'ivcap context login'")
+				return
+			}
+			refreshed, err := d.refreshToken()
+			if err != nil {
+				d.add(name, CHECK_FAIL, fmt.Sprintf("%s and refreshing it failed - %v", state, err),
+					"log in again with 'ivcap context login'")
+				return
+			}
+			token, expiry, source = refreshed.AccessToken, refreshed.Expiry, "context (refreshed)"
+		}
+	}
+	if !expiry.IsZero() && time.Now().After(expiry) {
+		d.add(name, CHECK_FAIL, fmt.Sprintf("token from %s expired %s", source, expiry.Local().Format(time.RFC822)),
+			"provide a current token")
+		return
+	}
+
+	// check that the deployment accepts it
+	adapter, err := d.adapter(token)
+	if err != nil {
+		d.add(name, CHECK_FAIL, err.Error(), "")
+		return
+	}
+	ctx, cancel := NewTimeoutContext()
+	defer cancel()
+	if _, err = sdk.ListServicesRaw(ctx, &sdk.ListRequest{Limit: 1}, adapter, logger); err != nil {
+		if exitCode(err) == EXIT_UNAUTHORIZED {
+			d.add(name, CHECK_FAIL, fmt.Sprintf("token from %s is rejected by the deployment", source),
+				"log in again with 'ivcap context login'")
+		} else {
+			d.add(name, CHECK_WARN, fmt.Sprintf("cannot verify token from %s - %v", source, err), "")
+		}
+		return
+	}
+	message := fmt.Sprintf("token from %s is accepted", source)
+	if !expiry.IsZero() {
+		message += fmt.Sprintf(", valid until %s", expiry.Local().Format(time.RFC822))
+	}
+	d.add(name, CHECK_PASS, message, "")
+}
+
+// Obtains a new access token for the current context. A token obtained
+// with client credentials is not saved, but one obtained with the refresh
+// token is, as the identity provider may have rotated the refresh token.
+func (d *doctor) refreshToken() (*adpt.Token, error) {
+	if d.ctxt.ClientID != "" {
+		return requestClientCredentialsToken(d.ctxt)
+	}
+	ctx, cancel := NewTimeoutContext()
+	defer cancel()
+	return refreshStoredContextToken(ctx, d.ctxt, contextToken(d.ctxt))
+}
+
+func (d *doctor) checkDocker() {
+	const name = "docker"
+	hint := "'package push' needs a running docker daemon, start it or set DOCKER_HOST"
+	client, err := dockerclient.NewClientWithOpts(dockerclient.WithAPIVersionNegotiation(), dockerclient.FromEnv)
+	if err != nil {
+		d.add(name, CHECK_WARN, fmt.Sprintf("cannot create docker client - %v", err), hint)
+		return
+	}
+	defer func() { _ = client.Close() }()
+	ctx, cancel := NewTimeoutContext()
+	defer cancel()
+	v, err := client.ServerVersion(ctx)
+	if err != nil {
+		d.add(name, CHECK_WARN, fmt.Sprintf("cannot connect to docker daemon - %v", err), hint)
+		return
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("docker %s (API %s) at %s", v.Version, v.APIVersion, client.DaemonHost()), "")
+}
+
+func (d *doctor) checkRegistry() {
+	const name = "registry"
+	host, err := sdk.DockerRegistryHost(d.ctxt.URL)
+	if err != nil {
+		d.add(name, CHECK_WARN, err.Error(), "")
+		return
+	}
+	t, err := adpt.NewTransport(transportConfig(d.ctxt))
+	if err != nil {
+		d.add(name, CHECK_WARN, err.Error(), "")
+		return
+	}
+	ctx, cancel := NewTimeoutContext()
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+host+"/v2/", nil)
+	if err != nil {
+		d.add(name, CHECK_WARN, err.Error(), "")
+		return
+	}
+	resp, err := (&http.Client{Transport: t}).Do(req)
+	if err != nil {
+		d.add(name, CHECK_WARN, fmt.Sprintf("cannot reach registry '%s' - %v", host, err),
+			"'package push' and 'pull' need the registry, check your network and proxy settings")
+		return
+	}
+	_ = resp.Body.Close()
+	// the registry asks for credentials, which is good enough
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		d.add(name, CHECK_WARN, fmt.Sprintf("registry '%s' replied with '%s'", host, resp.Status), "")
+		return
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("registry '%s' is reachable", host), "")
+}
+
+func (d *doctor) checkMCP() {
+	const name = "mcp"
+	if _, err := createMCPAdapter(timeout); err != nil {
+		hint := ""
+		if errors.Is(err, mcppkg.ErrLoginRequired) {
+			hint = "the MCP server cannot log in by itself, log in with 'ivcap context login' first"
+		}
+		d.add(name, CHECK_WARN, fmt.Sprintf("'ivcap mcp' cannot connect - %v", err), hint)
+		return
+	}
+	if accessTokenF == "" && os.Getenv(ACCESS_TOKEN_ENV) == "" && d.ctxt.RefreshToken == "" && d.ctxt.ClientID == "" {
+		when := ""
+		if !d.ctxt.AccessTokenExpiry.IsZero() {
+			when = " " + d.ctxt.AccessTokenExpiry.Local().Format(time.RFC822)
+		}
+		d.add(name, CHECK_WARN, fmt.Sprintf("'ivcap mcp' will stop working when the access token expires%s", when),
+			"log in with 'ivcap context login' to get a refresh token, or with '--client-id' for unattended use")
+		return
+	}
+	d.add(name, CHECK_PASS, "'ivcap mcp' can connect to the deployment", "")
+}
+
+func (d *doctor) checkVersion() {
+	const name = "version"
+	current := currentVersion()
+	if getUpdateConfig().Disabled {
+		d.add(name, CHECK_SKIP, fmt.Sprintf("'v%s' is installed, checks for new releases are disabled", current), "")
+		return
+	}
+	latest, err := latestVersion()
+	if err != nil {
+		d.add(name, CHECK_WARN, fmt.Sprintf("cannot find latest release - %v", err),
+			"set a reachable mirror with 'ivcap config updates --url', or disable the check with '--check=false'")
+		return
+	}
+	if current != latest {
+		d.add(name, CHECK_WARN, fmt.Sprintf("'v%s' is installed, but 'v%s' is available", current, latest),
+			"upgrade with 'ivcap self-update' or 'brew upgrade ivcap'")
+		return
+	}
+	d.add(name, CHECK_PASS, fmt.Sprintf("'v%s' is the latest release", current), "")
+}
+
+func printDoctorReport(report *doctorReport) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Check", "Result", "Details"})
+	for _, c := range report.Checks {
+		t.AppendRow(table.Row{c.Name, strings.ToUpper(c.Status), c.Message})
+	}
+	t.SetStyle(table.StyleLight)
+	t.Render()
+	first := true
+	for _, c := range report.Checks {
+		if c.Hint == "" || c.Status == CHECK_PASS {
+			continue
+		}
+		if first {
+			fmt.Println("\nHints:")
+			first = false
+		}
+		fmt.Printf("  %-10s %s\n", c.Name, c.Hint)
+	}
+	fmt.Printf("\n%d passed, %d warnings, %d failed\n", report.Passed, report.Warnings, report.Failures)
+}
@@ -0,0 +1,130 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	log "go.uber.org/zap"
+)
+
+func TestDoctor_CheckConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	d := &doctor{}
+	if d.checkConfig() || d.report.Failures != 1 {
+		t.Fatalf("expected missing config file to fail, got %+v", d.report.Checks)
+	}
+
+	SetContext(&Context{Name: "dev", URL: "https://dev.example.com"}, false)
+	d = &doctor{}
+	if !d.checkConfig() || d.report.Passed != 1 {
+		t.Fatalf("expected config file to pass, got %+v", d.report.Checks)
+	}
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if err := os.Chmod(GetConfigFilePath(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	d = &doctor{}
+	if !d.checkConfig() || d.report.Warnings != 1 {
+		t.Fatalf("expected warning for world readable config file, got %+v", d.report.Checks)
+	}
+}
+
+func TestDoctor_DeploymentAndClock(t *testing.T) {
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	serverTime := time.Now().Add(-2 * time.Minute)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1/authinfo.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Date", serverTime.UTC().Format(http.TimeFormat))
+		_, _ = w.Write([]byte("version: 1\nauth:\n  providers:\n    p1: {}\n"))
+	}))
+	defer srv.Close()
+
+	d := &doctor{ctxt: &Context{Name: "test", URL: srv.URL}}
+	pyld := d.checkDeployment()
+	if pyld == nil || d.report.Passed != 1 {
+		t.Fatalf("expected deployment check to pass, got %+v", d.report.Checks)
+	}
+	d.checkClock(pyld)
+	if c := d.report.Checks[1]; c.Status != CHECK_WARN {
+		t.Errorf("expected warning for clock skew, got %+v", c)
+	}
+}
+
+func TestDoctor_CheckToken(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/authinfo.yaml":
+			_, _ = w.Write([]byte("version: 1\nauth:\n  providers:\n    p1:\n      login-url: " + srv.URL + "/login\n" +
+				"      token-url: " + srv.URL + "/token\n      code-url: " + srv.URL + "/code\n" +
+				"      jwks-url: " + srv.URL + "/jwks\n      audience: test\n"))
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "new-token", "token_type": "Bearer", "expires_in": 3600}`))
+		case "/1/services2":
+			if r.Header.Get("Authorization") != "Bearer new-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"items": []}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(secretFile, []byte("secret"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	ctxt := &Context{Name: "test", URL: srv.URL, ClientID: "client", ClientSecretFile: secretFile,
+		AccessToken: "old-token", AccessTokenExpiry: time.Now().Add(-time.Hour)}
+	SetContext(ctxt, false)
+	contextName = "test"
+	t.Cleanup(func() { contextName = "" })
+
+	d := &doctor{ctxt: ctxt}
+	d.checkToken()
+	if c := d.report.Checks[0]; c.Status != CHECK_PASS || !strings.Contains(c.Message, "refreshed") {
+		t.Errorf("expected expired token to be refreshed and accepted, got %+v", c)
+	}
+
+	// a token without expiry is checked against the deployment as is
+	SetContext(&Context{Name: "no-expiry", URL: srv.URL, AccessToken: "new-token"}, false)
+	d = &doctor{ctxt: &Context{Name: "no-expiry", URL: srv.URL}}
+	d.checkToken()
+	if c := d.report.Checks[0]; c.Status != CHECK_PASS || strings.Contains(c.Message, "expired") {
+		t.Errorf("expected token without expiry to be accepted, got %+v", c)
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Shown in the usage of every command, after the flags
+const envUsageTemplate = `{{with flagEnvUsages .}}
+
+Environment Variables:
+{{. | trimTrailingWhitespaces}}{{end}}`
+
+// flags which were set from the environment, and the variable they came from
+var envFlags = map[string]string{}
+
+func init() {
+	cobra.AddTemplateFunc("flagEnvUsages", flagEnvUsages)
+	rootCmd.SetUsageTemplate(addEnvUsage(rootCmd.UsageTemplate()))
+}
+
+// Adds the environment variables to the usage template `tmpl` after the
+// flags, or at its end if a new cobra version changed the template.
+func addEnvUsage(tmpl string) string {
+	if i := strings.Index(tmpl, "{{if .HasHelpSubCommands}}"); i >= 0 {
+		return tmpl[:i] + envUsageTemplate + tmpl[i:]
+	}
+	return strings.TrimRight(tmpl, "\n") + envUsageTemplate + "\n"
+}
+
+// Returns the environment variables which set flag `f` of `cmd`, the most
+// specific one first. Global flags are set by 'IVCAP_<FLAG>', e.g.
+// 'IVCAP_NO_HISTORY'. The flags of a command can be set for the command, or
+// any of its parents, e.g. 'IVCAP_ARTIFACT_CREATE_CHUNK_SIZE' or
+// 'IVCAP_ARTIFACT_CHUNK_SIZE'.
+func flagEnvVars(cmd *cobra.Command, f *pflag.Flag) []string {
+	name := envName(f.Name)
+	if cmd.Root().PersistentFlags().Lookup(f.Name) == f {
+		return []string{ENV_PREFIX + "_" + name}
+	}
+	path := strings.Fields(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	vars := make([]string, 0, len(path))
+	for i := len(path); i > 0; i-- {
+		vars = append(vars, ENV_PREFIX+"_"+envName(strings.Join(path[:i], "_"))+"_"+name)
+	}
+	return vars
+}
+
+func envName(s string) string {
+	return strings.ToUpper(strings.ReplaceAll(s, "-", "_"))
+}
+
+// Returns true if flag `f` can't be set from the environment. Only the
+// root's '--version' is skipped, so 'IVCAP_SELF_UPDATE_VERSION' still works.
+func ignoreEnvFlag(f *pflag.Flag) bool {
+	return f.Hidden || f.Name == "help" || f == rootCmd.Flags().Lookup("version")
+}
+
+// Sets all flags in `flags` of `cmd` which haven't been provided on the
+// command line from their environment variables.
+func applyEnvFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Changed || ignoreEnvFlag(f) {
+			return
+		}
+		for _, name := range flagEnvVars(cmd, f) {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			if err := flags.Set(f.Name, value); err != nil {
+				checkErr(&UsageError{fmt.Errorf("invalid value for '--%s' in env '%s' - %w", f.Name, name, err)})
+			}
+			envFlags[f.Name] = name
+			return
+		}
+	})
+}
+
+// Returns the environment variables for the flags of `cmd`, one line per
+// flag, for its usage.
+func flagEnvUsages(cmd *cobra.Command) string {
+	var flags []*pflag.Flag
+	width := 0
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if !ignoreEnvFlag(f) {
+			flags = append(flags, f)
+			width = max(width, len(f.Name))
+		}
+	})
+	var b strings.Builder
+	for _, f := range flags {
+		fmt.Fprintf(&b, "  --%-*s   %s\n", width, f.Name, strings.Join(flagEnvVars(cmd, f), ", "))
+	}
+	if cmd.HasParent() && cmd.HasAvailableInheritedFlags() {
+		fmt.Fprintf(&b, "  Global flags are set with %s_<FLAG>, e.g. %s_CONTEXT or %s_NO_HISTORY\n",
+			ENV_PREFIX, ENV_PREFIX, ENV_PREFIX)
+	}
+	return b.String()
+}
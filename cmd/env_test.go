@@ -0,0 +1,100 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFlagEnvVars(t *testing.T) {
+	root := &cobra.Command{Use: "ivcap"}
+	root.PersistentFlags().Bool("no-history", false, "")
+	parent := &cobra.Command{Use: "artifact"}
+	create := &cobra.Command{Use: "create"}
+	create.Flags().Int64("chunk-size", 0, "")
+	root.AddCommand(parent)
+	parent.AddCommand(create)
+
+	if got := flagEnvVars(create, create.Flags().Lookup("chunk-size")); !slices.Equal(got,
+		[]string{"IVCAP_ARTIFACT_CREATE_CHUNK_SIZE", "IVCAP_ARTIFACT_CHUNK_SIZE"}) {
+		t.Errorf("unexpected env vars for local flag %v", got)
+	}
+	if got := flagEnvVars(create, create.InheritedFlags().Lookup("no-history")); !slices.Equal(got,
+		[]string{"IVCAP_NO_HISTORY"}) {
+		t.Errorf("unexpected env vars for global flag %v", got)
+	}
+}
+
+func TestApplyEnvFlags(t *testing.T) {
+	root := &cobra.Command{Use: "ivcap"}
+	parent := &cobra.Command{Use: "job"}
+	list := &cobra.Command{Use: "list"}
+	var limit int
+	var filter, search string
+	list.Flags().IntVar(&limit, "limit", 10, "")
+	list.Flags().StringVar(&filter, "filter", "", "")
+	list.Flags().StringVar(&search, "search", "", "")
+	root.AddCommand(parent)
+	parent.AddCommand(list)
+	checkErr(list.Flags().Set("search", "cli"))
+
+	t.Setenv("IVCAP_JOB_LIMIT", "5")
+	t.Setenv("IVCAP_JOB_LIST_LIMIT", "7")
+	t.Setenv("IVCAP_JOB_FILTER", "a")
+	t.Setenv("IVCAP_JOB_LIST_SEARCH", "env")
+	applyEnvFlags(list, list.Flags())
+
+	if limit != 7 || filter != "a" || search != "cli" {
+		t.Errorf("expected limit 7, filter 'a' and search 'cli', got %d, '%s' and '%s'", limit, filter, search)
+	}
+	if envFlags["limit"] != "IVCAP_JOB_LIST_LIMIT" || envFlags["search"] != "" {
+		t.Errorf("unexpected flags set from env %v", envFlags)
+	}
+}
+
+func TestApplyEnvFlags_Version(t *testing.T) {
+	if rootCmd.Version == "" {
+		rootCmd.Version = "0.0.1"
+		t.Cleanup(func() { rootCmd.Version = "" })
+	}
+	rootCmd.InitDefaultVersionFlag()
+	t.Cleanup(func() {
+		updateVersion = ""
+		selfUpdateCmd.Flags().Lookup("version").Changed = false
+	})
+	t.Setenv("IVCAP_SELF_UPDATE_VERSION", "v0.42.1")
+	t.Setenv("IVCAP_VERSION", "true")
+	applyEnvFlags(rootCmd, rootCmd.Flags())
+	applyEnvFlags(selfUpdateCmd, selfUpdateCmd.Flags())
+
+	if updateVersion != "v0.42.1" {
+		t.Errorf("expected 'self-update --version' to be set from env, got '%s'", updateVersion)
+	}
+	if f := rootCmd.Flags().Lookup("version"); f == nil || f.Changed {
+		t.Error("expected the root's '--version' to not be set from env")
+	}
+}
+
+func TestAddEnvUsage(t *testing.T) {
+	if tmpl := addEnvUsage("Flags:\n{{if .HasHelpSubCommands}}Help{{end}}\n"); tmpl != "Flags:\n"+envUsageTemplate+"{{if .HasHelpSubCommands}}Help{{end}}\n" {
+		t.Errorf("expected environment variables after the flags, got %q", tmpl)
+	}
+	if tmpl := addEnvUsage("Usage:\n"); tmpl != "Usage:"+envUsageTemplate+"\n" {
+		t.Errorf("expected environment variables at the end of an unknown template, got %q", tmpl)
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	adpt "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+)
+
+// Exit codes of the CLI. Scripts can rely on these not to change.
+const (
+	EXIT_OK           = 0
+	EXIT_ERROR        = 1 // any error not listed below
+	EXIT_USAGE        = 2 // invalid flags or arguments
+	EXIT_UNAUTHORIZED = 3 // missing, expired or rejected credentials
+	EXIT_FORBIDDEN    = 4 // authenticated, but not allowed
+	EXIT_NOT_FOUND    = 5 // requested resource doesn't exist
+	EXIT_INVALID      = 6 // request rejected as invalid, e.g. a bad parameter
+	EXIT_CONFLICT     = 7 // resource already exists or is in a conflicting state
+	EXIT_UNAVAILABLE  = 8 // deployment unreachable, overloaded or failing
+	EXIT_TIMEOUT      = 9 // no reply within the requested time
+
+	EXIT_INTERRUPTED = 130 // cancelled by Ctrl-C or SIGTERM
+)
+
+// errorKinds names each exit code in JSON error reports
+var errorKinds = map[int]string{
+	EXIT_ERROR:        "error",
+	EXIT_USAGE:        "usage",
+	EXIT_UNAUTHORIZED: "unauthorized",
+	EXIT_FORBIDDEN:    "forbidden",
+	EXIT_NOT_FOUND:    "not-found",
+	EXIT_INVALID:      "invalid",
+	EXIT_CONFLICT:     "conflict",
+	EXIT_UNAVAILABLE:  "unavailable",
+	EXIT_TIMEOUT:      "timeout",
+	EXIT_INTERRUPTED:  "interrupted",
+}
+
+// UsageError reports invalid flags or arguments
+type UsageError struct {
+	err error
+}
+
+func (e *UsageError) Error() string { return e.err.Error() }
+
+func (e *UsageError) Unwrap() error { return e.err }
+
+// LoginError reports that there are no usable credentials for the context,
+// e.g. it's not logged in or its refresh token was rejected
+type LoginError struct {
+	err error
+}
+
+func (e *LoginError) Error() string { return e.err.Error() }
+
+func (e *LoginError) Unwrap() error { return e.err }
+
+// ErrorReport is written to stderr instead of a plain error message when
+// '--output json' is set.
+type ErrorReport struct {
+	Error      string `json:"error"` // one of the 'errorKinds'
+	ExitCode   int    `json:"exit-code"`
+	Message    string `json:"message"`
+	StatusCode int    `json:"status-code,omitempty"`
+	Path       string `json:"path,omitempty"`
+	ID         string `json:"id,omitempty"`
+	Parameter  string `json:"parameter,omitempty"`
+	Value      string `json:"value,omitempty"`
+}
+
+// exitCode maps `err` to one of the EXIT_... codes
+func exitCode(err error) int {
+	if err == nil {
+		return EXIT_OK
+	}
+	var usageErr *UsageError
+	var notFoundErr *adpt.ResourceNotFoundError
+	var unauthErr *adpt.UnauthorizedError
+	var tokenErr *adpt.TokenSourceError
+	var apiErr *adpt.ApiError
+	var netErr net.Error
+	var loginErr *LoginError
+	switch {
+	case errors.As(err, &usageErr):
+		return EXIT_USAGE
+	case errors.As(err, &notFoundErr):
+		return EXIT_NOT_FOUND
+	case errors.As(err, &unauthErr), errors.As(err, &tokenErr):
+		return EXIT_UNAUTHORIZED
+	case errors.As(err, &apiErr):
+		return statusExitCode(apiErr.StatusCode)
+	case errors.Is(err, context.Canceled):
+		return EXIT_INTERRUPTED
+	case errors.Is(err, context.DeadlineExceeded):
+		return EXIT_TIMEOUT
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return EXIT_TIMEOUT
+		}
+		return EXIT_UNAVAILABLE
+	case errors.As(err, &loginErr):
+		// after the above, as refreshing may fail for other reasons
+		return EXIT_UNAUTHORIZED
+	}
+	return EXIT_ERROR
+}
+
+func statusExitCode(statusCode int) int {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return EXIT_UNAUTHORIZED
+	case http.StatusForbidden:
+		return EXIT_FORBIDDEN
+	case http.StatusNotFound, http.StatusGone:
+		return EXIT_NOT_FOUND
+	case http.StatusBadRequest, http.StatusUnprocessableEntity, http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType, http.StatusMethodNotAllowed:
+		return EXIT_INVALID
+	case http.StatusConflict, http.StatusPreconditionFailed:
+		return EXIT_CONFLICT
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return EXIT_TIMEOUT
+	case http.StatusTooManyRequests:
+		return EXIT_UNAVAILABLE
+	}
+	if statusCode >= 500 {
+		return EXIT_UNAVAILABLE
+	}
+	return EXIT_ERROR
+}
+
+func newErrorReport(err error) *ErrorReport {
+	code := exitCode(err)
+	r := &ErrorReport{Error: errorKinds[code], ExitCode: code, Message: err.Error()}
+	var details *adpt.ErrorDetails
+	var notFoundErr *adpt.ResourceNotFoundError
+	var apiErr *adpt.ApiError
+	if errors.As(err, &notFoundErr) {
+		r.StatusCode = http.StatusNotFound
+		r.Path = notFoundErr.Path()
+		details = notFoundErr.Details
+	} else if errors.As(err, &apiErr) {
+		r.StatusCode = apiErr.StatusCode
+		r.Path = apiErr.Path()
+		details = apiErr.Details
+	}
+	if details != nil {
+		r.ID = details.ID
+		r.Parameter = details.Name
+		r.Value = details.Value
+	}
+	return r
+}
+
+// reportError writes `err` to stderr, as JSON if '--output json' is set
+func reportError(err error) {
+	if outputFormat == "json" {
+		if b, jerr := json.Marshal(newErrorReport(err)); jerr == nil {
+			fmt.Fprintln(os.Stderr, string(b))
+			return
+		}
+	}
+	fmt.Fprintln(os.Stderr, "Error:", err)
+}
+
+// checkErr replaces `cobra.CheckErr`. It reports `msg` (an error or
+// anything printable), flushes telemetry, cassette and trace (see
+// `finishCommand`) and exits with the matching exit code.
+func checkErr(msg any) {
+	if msg == nil {
+		return
+	}
+	err, ok := msg.(error)
+	if !ok {
+		err = errors.New(fmt.Sprint(msg))
+	}
+	finishCommand(err)
+	reportError(err)
+	os.Exit(exitCode(err))
+}
+
+func flagErrorFunc(_ *cobra.Command, err error) error {
+	return &UsageError{err}
+}
@@ -0,0 +1,37 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestExitCode_LoginError(t *testing.T) {
+	err := &LoginError{fmt.Errorf("refresh failed - %w", errors.New("invalid_grant"))}
+	if code := exitCode(err); code != EXIT_UNAUTHORIZED {
+		t.Errorf("expected %d, got %d", EXIT_UNAUTHORIZED, code)
+	}
+	if r := newErrorReport(err); r.Error != "unauthorized" {
+		t.Errorf("expected 'unauthorized' in report, got %+v", r)
+	}
+	// a refresh failing because the provider can't be reached isn't a login problem
+	err = &LoginError{fmt.Errorf("refresh failed - %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")})}
+	if code := exitCode(err); code != EXIT_UNAVAILABLE {
+		t.Errorf("expected %d, got %d", EXIT_UNAVAILABLE, code)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// How long to wait for another process to release the lock on a config
+// file. This needs to cover a token refresh, which happens while holding
+// the lock on the config file.
+const FILE_LOCK_TIMEOUT = 30 * time.Second
+const FILE_LOCK_RETRY_INTERVAL = 20 * time.Millisecond
+
+// Returned by 'tryLock' if the lock is held by someone else
+var errLocked = errors.New("file is locked")
+
+// lockFile acquires an exclusive lock for `path`, waiting for up to
+// FILE_LOCK_TIMEOUT if it is held by another process. The lock is taken on
+// a separate '.lock' file as `path` itself gets replaced on every write.
+// The returned function releases the lock again.
+func lockFile(path string) (release func(), err error) {
+	lockPath := path + ".lock"
+	f, err := os.OpenFile(filepath.Clean(lockPath), os.O_CREATE|os.O_RDWR, fs.FileMode(0600))
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(FILE_LOCK_TIMEOUT)
+	for {
+		if err = tryLock(f); err == nil {
+			break
+		}
+		if !errors.Is(err, errLocked) || time.Now().After(deadline) {
+			_ = f.Close()
+			if errors.Is(err, errLocked) {
+				err = fmt.Errorf("timed out waiting for lock '%s'", lockPath)
+			}
+			return nil, err
+		}
+		time.Sleep(FILE_LOCK_RETRY_INTERVAL)
+	}
+	return func() {
+		_ = unlock(f)
+		_ = f.Close()
+	}, nil
+}
+
+// writeFileAtomic replaces `path` with `data` by first writing to a
+// temporary file in the same directory and then renaming it. Readers will
+// therefore either see the old or the new content, but never a partial file.
+func writeFileAtomic(path string, data []byte, perm fs.FileMode) (err error) {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(f.Name())
+		}
+	}()
+	if _, err = f.Write(data); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Chmod(f.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// writeFileLocked atomically replaces `path` with `data` while holding
+// the lock for `path`.
+func writeFileLocked(path string, data []byte, perm fs.FileMode) error {
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return writeFileAtomic(path, data, perm)
+}
@@ -0,0 +1,66 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWriteFileLocked_SerialisesUpdates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "counter.txt")
+	if err := writeFileAtomic(path, []byte("0"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// every writer increments the counter while holding the lock
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			release, err := lockFile(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n, _ := strconv.Atoi(string(data))
+			if err = writeFileAtomic(path, fmt.Appendf(nil, "%d", n+1), 0600); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if data, _ := os.ReadFile(path); string(data) != "10" {
+		t.Errorf("expected no lost updates, got '%s'", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("expected only file and its lock file to remain, got %v", entries)
+	}
+	if fi, _ := os.Stat(path); fi.Mode().Perm() != 0600 {
+		t.Errorf("unexpected file mode %v", fi.Mode())
+	}
+}
@@ -0,0 +1,611 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// History tokens are kept per context in '<config dir>/history/<context>.yaml'
+const HISTORY_DIR_NAME = "history"
+const HISTORY_FILE_VERSION = "v2"
+
+// Tokens not shown or used for this long are dropped, unless the context
+// sets 'history-retention'
+const HISTORY_DEFAULT_RETENTION = 7 * 24 * time.Hour
+
+// Max. number of tokens kept for each kind of resource, unless the context
+// sets 'history-max-entries'
+const HISTORY_DEFAULT_MAX_ENTRIES = 100
+
+// Kind of history entries which aren't IVCAP URNs, like page cursors
+const HISTORY_OTHER_KIND = "other"
+
+var HISTORY_ALIAS_NAME = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9._-]*$`)
+
+// Tokens are numbered separately for each kind, e.g. '@job:3'
+var HISTORY_TOKEN = regexp.MustCompile(`^@([^:@]+):([0-9]+)$`)
+var HISTORY_SHORT_TOKEN = regexp.MustCompile(`^@[0-9]+$`)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.AddCommand(listHistoryCmd)
+	listHistoryCmd.Flags().StringVar(&historyKindF, "kind", "", "only list tokens for this kind of resource, e.g. 'job'")
+
+	historyCmd.AddCommand(aliasHistoryCmd)
+	historyCmd.AddCommand(unaliasHistoryCmd)
+
+	historyCmd.AddCommand(clearHistoryCmd)
+	clearHistoryCmd.Flags().StringVar(&historyKindF, "kind", "", "only remove tokens for this kind of resource, e.g. 'job'")
+	clearHistoryCmd.Flags().DurationVar(&historyOlderThan, "older-than", 0, "only remove tokens not used for this long, e.g. '24h'")
+	clearHistoryCmd.Flags().BoolVar(&historyClearAliases, "aliases", false, "remove aliases as well")
+}
+
+var (
+	historyKindF        string
+	historyOlderThan    time.Duration
+	historyClearAliases bool
+
+	// history of the current context, loaded on first use
+	history        *historyFile
+	historyContext *Context
+	historyLoaded  bool
+	// tokens created or used by the current command, merged into the
+	// history file by 'saveHistory'
+	historyChanged = map[string]*historyEntry{}
+	// numbers reserved in the history file for new tokens of each kind
+	historyReserved = map[string]*historyReservation{}
+)
+
+type historyReservation struct {
+	next int // next number to hand out
+	last int // last number reserved
+	size int // number of numbers reserved last
+}
+
+type historyFile struct {
+	Version string                   `yaml:"version"`
+	Tokens  map[string]*historyEntry `yaml:"tokens,omitempty"`  // indexed by token, e.g. '@job:3'
+	Aliases map[string]*historyEntry `yaml:"aliases,omitempty"` // indexed by name, never expire
+	// last number handed out for each kind, numbers are never reused
+	Next map[string]int `yaml:"next,omitempty"`
+}
+
+type historyEntry struct {
+	ID   string    `yaml:"id"`
+	Kind string    `yaml:"kind"`
+	Used time.Time `yaml:"used"` // when last shown or used, or created for aliases
+}
+
+var (
+	historyCmd = &cobra.Command{
+		Use:     "history",
+		Short:   "Manage history tokens like '@job:1' and their aliases",
+		GroupID: generalSupportGroupID,
+		Long: `Most commands show IDs as short history tokens like '@job:1', which can be
+used instead of the ID in later commands. Tokens are kept for each context,
+so they never refer to resources of another deployment. Showing an ID again
+reuses its token, and each kind of resource is numbered separately, so
+'@job:3' from 'job list' stays valid while running 'service list' in
+another terminal. A token may be shortened to '@3' as long as only one kind
+has a token with that number.
+
+Tokens not shown or used for a week are dropped, as are the least recently
+used ones once there are more than 100 of a kind. The number of a dropped
+token is never given to another resource. Both limits can be set
+for a context with 'ivcap context create --history-retention ...
+--history-max-entries ...'.
+
+Aliases give a token a name which is kept until it's removed, and which is
+used like a token, e.g. 'ivcap service get @my-model'.`,
+	}
+
+	listHistoryCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the history tokens and aliases of the current context",
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			h := getHistory()
+			if h == nil {
+				return errors.New("cannot find suitable context. Use '--context' or set default via 'context' command")
+			}
+			items := h.items(historyKindF)
+			if !isTableOutput() {
+				return printValue(items)
+			}
+			t := table.NewWriter()
+			t.SetOutputMirror(os.Stdout)
+			t.AppendHeader(table.Row{"Token", "Kind", "ID", "Last Used"})
+			for _, i := range items {
+				used := i.Used.Local().Format(time.RFC822)
+				if i.Alias {
+					used = "alias"
+				}
+				t.AppendRow(table.Row{i.Token, i.Kind, i.ID, used})
+			}
+			t.SetStyle(table.StyleLight)
+			t.Render()
+			return nil
+		},
+	}
+
+	aliasHistoryCmd = &cobra.Command{
+		Use:   "alias token|id name",
+		Short: "Give a history token or ID a name which can be used as '@name'",
+		Example: `  ivcap service list
+  ivcap history alias @service:2 my-model
+  ivcap service get @my-model`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := strings.TrimPrefix(args[1], "@")
+			if !HISTORY_ALIAS_NAME.MatchString(name) {
+				return &UsageError{fmt.Errorf("invalid alias '%s', it needs to start with a letter followed by letters, digits, '.', '_' or '-'", name)}
+			}
+			if getHistory() == nil {
+				return errors.New("cannot find suitable context. Use '--context' or set default via 'context' command")
+			}
+			id := GetHistory(args[0])
+			err := updateHistoryFile(historyContext, func(h *historyFile) {
+				h.Aliases[name] = &historyEntry{ID: id, Kind: historyKind(id), Used: time.Now()}
+			})
+			if err != nil {
+				return err
+			}
+			if !silent {
+				fmt.Printf("'@%s' now refers to '%s'\n", name, id)
+			}
+			return nil
+		},
+	}
+
+	unaliasHistoryCmd = &cobra.Command{
+		Use:   "unalias name",
+		Short: "Remove an alias",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			name := strings.TrimPrefix(args[0], "@")
+			if getHistory() == nil {
+				return errors.New("cannot find suitable context. Use '--context' or set default via 'context' command")
+			}
+			found := false
+			err := updateHistoryFile(historyContext, func(h *historyFile) {
+				_, found = h.Aliases[name]
+				delete(h.Aliases, name)
+			})
+			if err != nil {
+				return err
+			}
+			if !found {
+				return fmt.Errorf("unknown alias '@%s'", name)
+			}
+			if !silent {
+				fmt.Printf("Removed alias '@%s'\n", name)
+			}
+			return nil
+		},
+	}
+
+	clearHistoryCmd = &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the history tokens of the current context",
+		Example: `  ivcap history clear
+  ivcap history clear --kind job --older-than 24h
+  ivcap history clear --aliases`,
+		Args: cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if getHistory() == nil {
+				return errors.New("cannot find suitable context. Use '--context' or set default via 'context' command")
+			}
+			var removed int
+			err := updateHistoryFile(historyContext, func(h *historyFile) {
+				removed = h.clear(historyKindF, historyOlderThan, historyClearAliases, time.Now())
+			})
+			if err != nil {
+				return err
+			}
+			// don't bring back the tokens used to run this command
+			historyChanged = map[string]*historyEntry{}
+			if !silent {
+				fmt.Printf("Removed %d history entries\n", removed)
+			}
+			return nil
+		},
+	}
+)
+
+// ****** TOKENS ****
+
+// MakeHistory returns the history token for `urn`, or `urn` itself if
+// history is disabled.
+func MakeHistory(urn *string) string {
+	if urn == nil {
+		return "???"
+	}
+	if noHistory {
+		return *urn
+	}
+	if token := addHistory(*urn); token != "" {
+		return token
+	}
+	return *urn
+}
+
+// Check if argument is an IVCAP urn and if it
+// is, turn it into a history.
+func MakeMaybeHistory(sp *string) string {
+	if sp == nil {
+		return "???"
+	}
+	if noHistory {
+		return *sp
+	}
+	// HACK: Should go away in future IVCAP Core version
+	if strings.HasPrefix(*sp, "http://artifact.local/") {
+		u := *sp
+		u = u[len("http://artifact.local/"):]
+		sp = &u
+	}
+	// We assume, all IVCAP urns follow the pattern 'urn:ivcap:_service_:...
+	if !strings.HasPrefix(*sp, "urn:ivcap:") {
+		// no it's not
+		return *sp
+	}
+	token := addHistory(*sp)
+	if token == "" {
+		return *sp
+	}
+	return fmt.Sprintf("%s (%s)", token, *sp)
+}
+
+// GetHistory returns the ID a history token like '@job:3', its short form
+// '@3', or an alias like '@my-model' refers to. Anything else is returned
+// as is.
+func GetHistory(token string) (value string) {
+	if !strings.HasPrefix(token, "@") {
+		return token
+	}
+	h := getHistory()
+	if h != nil {
+		if HISTORY_SHORT_TOKEN.MatchString(token) {
+			matches := h.withNumber(token[1:])
+			if len(matches) > 1 {
+				checkErr(fmt.Sprintf("Ambiguous history '%s', use one of '%s'.", token, strings.Join(matches, "', '")))
+			}
+			if len(matches) == 1 {
+				token = matches[0]
+			}
+		}
+		if e, ok := h.Tokens[token]; ok {
+			// keep it from expiring
+			e.Used = time.Now()
+			historyChanged[token] = e
+			return e.ID
+		}
+		if e, ok := h.Aliases[token[1:]]; ok {
+			return e.ID
+		}
+	}
+	checkErr(fmt.Sprintf("Unknown history '%s'.", token))
+	return
+}
+
+// Returns the token for `id`, reusing an existing one, or "" if there is
+// no context to keep the history for.
+func addHistory(id string) string {
+	h := getHistory()
+	if h == nil {
+		return ""
+	}
+	token := h.tokenFor(id)
+	if token == "" {
+		kind := historyKind(id)
+		n, err := reserveHistoryNumber(kind)
+		if err != nil {
+			// show the ID instead
+			return ""
+		}
+		token = fmt.Sprintf("@%s:%d", kind, n)
+	}
+	e := &historyEntry{ID: id, Kind: historyKind(id), Used: time.Now()}
+	h.Tokens[token] = e
+	historyChanged[token] = e
+	return token
+}
+
+// Returns the next number for a new token of `kind`. Numbers are reserved
+// in the history file in batches, starting with as many as a kind keeps
+// and doubling each time, so commands running in other terminals never
+// hand out the same token, and even long listings only rewrite the file a
+// few times.
+func reserveHistoryNumber(kind string) (int, error) {
+	r := historyReserved[kind]
+	if r == nil || r.next > r.last {
+		_, size := historyLimits(historyContext)
+		if r != nil {
+			size = 2 * r.size
+		}
+		err := updateHistoryFile(historyContext, func(f *historyFile) {
+			r = &historyReservation{next: f.Next[kind] + 1, last: f.Next[kind] + size, size: size}
+			f.Next[kind] = r.last
+		})
+		if err != nil {
+			return 0, err
+		}
+		historyReserved[kind] = r
+	}
+	n := r.next
+	r.next++
+	return n, nil
+}
+
+// Returns the history of the current context, or nil if there is no
+// current context.
+func getHistory() *historyFile {
+	if historyLoaded {
+		return history
+	}
+	historyLoaded = true
+	ctxt, err := GetContextWithError(contextName, true)
+	if err != nil {
+		return nil
+	}
+	path := getHistoryFilePath(ctxt.Name)
+	h, err := readHistoryFile(path)
+	if err != nil {
+		checkErr(fmt.Sprintf("problems reading history file %s - %v", path, err))
+		return nil
+	}
+	h.expire(ctxt, time.Now())
+	history, historyContext = h, ctxt
+	return history
+}
+
+// Merges the tokens created or used by the current command into the
+// history file of the current context.
+func saveHistory() error {
+	if len(historyChanged) == 0 && len(historyReserved) == 0 {
+		return nil
+	}
+	return updateHistoryFile(historyContext, func(h *historyFile) {
+		for token, e := range historyChanged {
+			if o, ok := h.Tokens[token]; !ok || o.ID == e.ID {
+				h.Tokens[token] = e
+			}
+		}
+		for kind, r := range historyReserved {
+			// hand back the numbers not used, unless another command
+			// reserved some after them
+			if h.Next[kind] == r.last {
+				h.Next[kind] = r.next - 1
+			}
+		}
+	})
+}
+
+// Applies `update` to the history file of `ctxt` while holding its lock.
+func updateHistoryFile(ctxt *Context, update func(h *historyFile)) error {
+	path := getHistoryFilePath(ctxt.Name)
+	if err := os.MkdirAll(filepath.Dir(path), fs.FileMode(0700)); err != nil {
+		return fmt.Errorf("cannot create history directory - %w", err)
+	}
+	unlock, err := lockFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot lock history file %s - %w", path, err)
+	}
+	defer unlock()
+	h, err := readHistoryFile(path)
+	if err != nil {
+		return fmt.Errorf("problems reading history file %s - %w", path, err)
+	}
+	update(h)
+	h.expire(ctxt, time.Now())
+	b, err := yaml.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("cannot marshall history - %w", err)
+	}
+	if err = writeFileAtomic(path, b, fs.FileMode(0600)); err != nil {
+		return fmt.Errorf("cannot write history to file %s - %w", path, err)
+	}
+	return nil
+}
+
+func readHistoryFile(path string) (*historyFile, error) {
+	h := &historyFile{}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, h); err != nil {
+		return nil, err
+	}
+	if h.Version != HISTORY_FILE_VERSION {
+		// tokens used to be numbered across kinds
+		h.Tokens = nil
+	}
+	h.Version = HISTORY_FILE_VERSION
+	if h.Tokens == nil {
+		h.Tokens = map[string]*historyEntry{}
+	}
+	if h.Aliases == nil {
+		h.Aliases = map[string]*historyEntry{}
+	}
+	if h.Next == nil {
+		h.Next = map[string]int{}
+	}
+	for token := range h.Tokens {
+		if kind, n := parseHistoryToken(token); kind != "" {
+			h.Next[kind] = max(h.Next[kind], n)
+		}
+	}
+	return h, nil
+}
+
+func getHistoryFilePath(ctxtName string) string {
+	return filepath.Join(GetConfigDir(true), HISTORY_DIR_NAME, credentialsFileName(ctxtName)+".yaml")
+}
+
+// Returns the kind of resource `id` refers to, e.g. 'job' for
+// 'urn:ivcap:job:...'.
+func historyKind(id string) string {
+	if p := strings.SplitN(id, ":", 4); len(p) == 4 && p[0] == "urn" && p[2] != "" {
+		return p[2]
+	}
+	return HISTORY_OTHER_KIND
+}
+
+// Returns the retention period and the max. number of tokens per kind for
+// the history of `ctxt`.
+func historyLimits(ctxt *Context) (time.Duration, int) {
+	retention, maxEntries := HISTORY_DEFAULT_RETENTION, HISTORY_DEFAULT_MAX_ENTRIES
+	if ctxt.HistoryRetention != "" {
+		if d, err := time.ParseDuration(ctxt.HistoryRetention); err == nil {
+			retention = d
+		}
+	}
+	if ctxt.HistoryMaxEntries > 0 {
+		maxEntries = ctxt.HistoryMaxEntries
+	}
+	return retention, maxEntries
+}
+
+// Drops the tokens which haven't been used within the retention period of
+// `ctxt`, and the least recently used ones of each kind beyond its limit.
+func (h *historyFile) expire(ctxt *Context, now time.Time) {
+	retention, maxEntries := historyLimits(ctxt)
+	byKind := map[string][]string{}
+	for token, e := range h.Tokens {
+		if e.Used.Before(now.Add(-retention)) {
+			delete(h.Tokens, token)
+			continue
+		}
+		byKind[e.Kind] = append(byKind[e.Kind], token)
+	}
+	for _, tokens := range byKind {
+		if len(tokens) <= maxEntries {
+			continue
+		}
+		sort.Slice(tokens, func(i, j int) bool { return h.Tokens[tokens[i]].Used.After(h.Tokens[tokens[j]].Used) })
+		for _, token := range tokens[maxEntries:] {
+			delete(h.Tokens, token)
+		}
+	}
+}
+
+func (h *historyFile) tokenFor(id string) string {
+	for token, e := range h.Tokens {
+		if e.ID == id {
+			return token
+		}
+	}
+	return ""
+}
+
+// Returns the tokens of any kind numbered `n`, in order.
+func (h *historyFile) withNumber(n string) []string {
+	var tokens []string
+	for token := range h.Tokens {
+		if m := HISTORY_TOKEN.FindStringSubmatch(token); m != nil && m[2] == n {
+			tokens = append(tokens, token)
+		}
+	}
+	sort.Strings(tokens)
+	return tokens
+}
+
+// Returns the kind and number of `token`
+func parseHistoryToken(token string) (string, int) {
+	m := HISTORY_TOKEN.FindStringSubmatch(token)
+	if m == nil {
+		return "", 0
+	}
+	n, _ := strconv.Atoi(m[2])
+	return m[1], n
+}
+
+// Removes the tokens, and optionally aliases, of `kind` (or all if "")
+// not used since `olderThan` before `now`, and returns their number.
+func (h *historyFile) clear(kind string, olderThan time.Duration, aliases bool, now time.Time) int {
+	removed := 0
+	remove := func(entries map[string]*historyEntry) {
+		for k, e := range entries {
+			if (kind == "" || e.Kind == kind) && !e.Used.After(now.Add(-olderThan)) {
+				delete(entries, k)
+				removed++
+			}
+		}
+	}
+	remove(h.Tokens)
+	if aliases {
+		remove(h.Aliases)
+	}
+	return removed
+}
+
+type historyItem struct {
+	Token string    `json:"token"`
+	Kind  string    `json:"kind"`
+	ID    string    `json:"id"`
+	Used  time.Time `json:"used"`
+	Alias bool      `json:"alias,omitempty"`
+}
+
+// Returns the tokens, ordered by kind and number, followed by the aliases,
+// ordered by name, restricted to `kind` unless "".
+func (h *historyFile) items(kind string) []historyItem {
+	items := []historyItem{}
+	add := func(token string, e *historyEntry, alias bool) {
+		if kind == "" || e.Kind == kind {
+			items = append(items, historyItem{Token: token, Kind: e.Kind, ID: e.ID, Used: e.Used, Alias: alias})
+		}
+	}
+	tokens := make([]string, 0, len(h.Tokens))
+	for token := range h.Tokens {
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		ka, a := parseHistoryToken(tokens[i])
+		kb, b := parseHistoryToken(tokens[j])
+		if ka != kb {
+			return ka < kb
+		}
+		return a < b
+	})
+	for _, token := range tokens {
+		add(token, h.Tokens[token], false)
+	}
+	names := make([]string, 0, len(h.Aliases))
+	for name := range h.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add("@"+name, h.Aliases[name], true)
+	}
+	return items
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+// Simulates running a command: the history is loaded on first use and saved
+// at the end.
+func runWithHistory(t *testing.T, ctxtName string, run func()) {
+	t.Helper()
+	contextName = ctxtName
+	history, historyContext, historyLoaded = nil, nil, false
+	historyChanged, historyReserved = map[string]*historyEntry{}, map[string]*historyReservation{}
+	t.Cleanup(func() {
+		contextName = ""
+		history, historyContext, historyLoaded = nil, nil, false
+		historyChanged, historyReserved = map[string]*historyEntry{}, map[string]*historyReservation{}
+	})
+	run()
+	if err := saveHistory(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHistory_KindsAndContexts(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetContext(&Context{Name: "dev", URL: "https://dev.example.com"}, false)
+	SetContext(&Context{Name: "prod", URL: "https://prod.example.com"}, false)
+
+	job1, job2, svc := "urn:ivcap:job:1", "urn:ivcap:job:2", "urn:ivcap:service:1"
+	runWithHistory(t, "dev", func() {
+		if tok := MakeHistory(&job1); tok != "@job:1" {
+			t.Errorf("expected '@job:1', got '%s'", tok)
+		}
+		MakeHistory(&job2)
+	})
+	runWithHistory(t, "dev", func() {
+		if tok := MakeHistory(&svc); tok != "@service:1" {
+			t.Errorf("expected services to be numbered separately, got '%s'", tok)
+		}
+		if tok := MakeHistory(&job2); tok != "@job:2" {
+			t.Errorf("expected token to be reused, got '%s'", tok)
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		if id := GetHistory("@job:1"); id != job1 {
+			t.Errorf("expected '%s', got '%s'", job1, id)
+		}
+		if id := GetHistory("@2"); id != job2 {
+			t.Errorf("expected short token to resolve to '%s', got '%s'", job2, id)
+		}
+	})
+	runWithHistory(t, "prod", func() {
+		if tok := MakeHistory(&svc); tok != "@service:1" {
+			t.Errorf("expected separate history for 'prod', got '%s'", tok)
+		}
+	})
+}
+
+func TestHistory_ConcurrentCommands(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetContext(&Context{Name: "dev", URL: "https://dev.example.com"}, false)
+
+	job1, job2, job3 := "urn:ivcap:job:1", "urn:ivcap:job:2", "urn:ivcap:job:3"
+	runWithHistory(t, "dev", func() {
+		if tok := MakeHistory(&job1); tok != "@job:1" {
+			t.Errorf("expected '@job:1', got '%s'", tok)
+		}
+		// another terminal shows a job while this command is running
+		saved, ctxt, changed, reserved := history, historyContext, historyChanged, historyReserved
+		runWithHistory(t, "dev", func() {
+			if tok := MakeHistory(&job2); tok == "@job:1" || tok == "@job:2" {
+				t.Errorf("expected a number not reserved by the other command, got '%s'", tok)
+			}
+		})
+		contextName, history, historyContext, historyLoaded = "dev", saved, ctxt, true
+		historyChanged, historyReserved = changed, reserved
+
+		if tok := MakeHistory(&job3); tok != "@job:2" {
+			t.Errorf("expected next reserved token, got '%s'", tok)
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		if id := GetHistory("@job:1"); id != job1 {
+			t.Errorf("expected '@job:1' to refer to '%s', got '%s'", job1, id)
+		}
+		if id := GetHistory("@job:2"); id != job3 {
+			t.Errorf("expected '@job:2' to refer to '%s', got '%s'", job3, id)
+		}
+	})
+}
+
+func TestHistory_NumbersAreNeverReused(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetContext(&Context{Name: "dev", URL: "https://dev.example.com", HistoryMaxEntries: 3}, false)
+
+	runWithHistory(t, "dev", func() {
+		for i := 1; i <= 5; i++ {
+			id := fmt.Sprintf("urn:ivcap:job:%d", i)
+			if tok, expected := MakeHistory(&id), fmt.Sprintf("@job:%d", i); tok != expected {
+				t.Errorf("expected '%s', got '%s'", expected, tok)
+			}
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		h := getHistory()
+		if len(h.Tokens) != 3 || h.Tokens["@job:1"] != nil || h.Tokens["@job:5"] == nil {
+			t.Errorf("expected only the last 3 tokens to be kept, got %v", h.items(""))
+		}
+		if err := clearHistoryCmd.RunE(clearHistoryCmd, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		id := "urn:ivcap:job:6"
+		if tok := MakeHistory(&id); tok != "@job:6" {
+			t.Errorf("expected evicted and cleared numbers to not be reused, got '%s'", tok)
+		}
+	})
+}
+
+func TestHistory_Expire(t *testing.T) {
+	now := time.Now()
+	h := &historyFile{Tokens: map[string]*historyEntry{
+		"@job:1":     {ID: "urn:ivcap:job:1", Kind: "job", Used: now.Add(-3 * time.Hour)},
+		"@job:2":     {ID: "urn:ivcap:job:2", Kind: "job", Used: now.Add(-time.Minute)},
+		"@job:3":     {ID: "urn:ivcap:job:3", Kind: "job", Used: now},
+		"@service:1": {ID: "urn:ivcap:service:1", Kind: "service", Used: now.Add(-time.Hour)},
+	}}
+	h.expire(&Context{HistoryRetention: "2h", HistoryMaxEntries: 1}, now)
+	if len(h.Tokens) != 2 || h.Tokens["@job:3"] == nil || h.Tokens["@service:1"] == nil {
+		t.Errorf("expected '@job:3' and '@service:1' to be kept, got %v", h.items(""))
+	}
+}
+
+func TestHistory_ShortTokens(t *testing.T) {
+	h := &historyFile{Tokens: map[string]*historyEntry{
+		"@job:1":     {ID: "urn:ivcap:job:1", Kind: "job"},
+		"@job:2":     {ID: "urn:ivcap:job:2", Kind: "job"},
+		"@service:1": {ID: "urn:ivcap:service:1", Kind: "service"},
+	}}
+	if got := h.withNumber("1"); len(got) != 2 || got[0] != "@job:1" || got[1] != "@service:1" {
+		t.Errorf("expected '@1' to be ambiguous, got %v", got)
+	}
+	if got := h.withNumber("2"); len(got) != 1 || got[0] != "@job:2" {
+		t.Errorf("expected '@2' to match '@job:2', got %v", got)
+	}
+}
+
+func TestHistory_Alias(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	SetContext(&Context{Name: "dev", URL: "https://dev.example.com"}, false)
+	svc := "urn:ivcap:service:1"
+	runWithHistory(t, "dev", func() {
+		MakeHistory(&svc)
+		if err := aliasHistoryCmd.RunE(aliasHistoryCmd, []string{"@service:1", "my-model"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		if err := clearHistoryCmd.RunE(clearHistoryCmd, nil); err != nil {
+			t.Fatal(err)
+		}
+	})
+	runWithHistory(t, "dev", func() {
+		if len(getHistory().Tokens) != 0 {
+			t.Errorf("expected no tokens after clear, got %v", getHistory().items(""))
+		}
+		if id := GetHistory("@my-model"); id != svc {
+			t.Errorf("expected alias to survive clear, got '%s'", id)
+		}
+	})
+}
@@ -15,11 +15,11 @@
 package cmd
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"math"
+	"os"
 	"strings"
 	"time"
 
@@ -30,7 +30,6 @@ import (
 
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/jedib0t/go-pretty/v6/text"
-	"github.com/r3labs/sse/v2"
 	"github.com/spf13/cobra"
 )
 
@@ -56,6 +55,10 @@ func init() {
 	createJobCmd.Flags().StringVarP(&aspectURN, "aspect", "a", "", "URN of aspect containing job parameters")
 	createJobCmd.Flags().BoolVar(&watchFlag, "watch", false, "if set, watch the job until it is finished")
 	createJobCmd.Flags().BoolVar(&streamFlag, "stream", false, "if set, print job related events to stdout")
+
+	// EVENTS
+	jobCmd.AddCommand(eventsJobCmd)
+	eventsJobCmd.Flags().StringVar(&sinceSeqID, "since", "", "only print events after the one with this sequence ID")
 }
 
 var (
@@ -63,6 +66,7 @@ var (
 	aspectURN      string
 	watchFlag      bool
 	streamFlag     bool
+	sinceSeqID     string
 )
 
 var (
@@ -90,20 +94,17 @@ var (
 			if jobsJsonFilter != "" {
 				selector.JsonFilter = &jobsJsonFilter
 			}
-			ctxt := context.Background()
-			if list, res, err := sdk.ListAspect(ctxt, selector, CreateAdapter(true), logger); err == nil {
-				switch outputFormat {
-				case "json":
-					return a.ReplyPrinter(res, false)
-				case "yaml":
-					return a.ReplyPrinter(res, true)
-				default:
-					printJobListTable(list, false)
-				}
-				return nil
-			} else {
-				return err
-			}
+			adapter := CreateAdapter(true)
+			getPrinter().IDFunc = jobRecordID
+			return printList(
+				func(ctxt context.Context) (a.Payload, error) {
+					_, res, err := sdk.ListAspect(ctxt, selector, adapter, logger)
+					return res, err
+				},
+				adapter,
+				func(list *aspect.ListResponseBody) *[]*aspect.AspectListItemRTResponseBody { return &list.Items },
+				func(list *aspect.ListResponseBody, next *string) { printJobListTable(list, next, isWideOutput()) },
+			)
 		},
 	}
 
@@ -114,7 +115,7 @@ var (
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			recordID := GetHistory(args[0])
-			ctxt := context.Background()
+			ctxt := RootContext()
 			return readDisplayJob(ctxt, recordID)
 		},
 	}
@@ -128,10 +129,10 @@ to an aspect containing the parameter definitions. If the job definition is
 provided through 'stdin' use '-' as the file name and also include the --format flag`,
 		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 
 			if fileName == "" && aspectURN == "" {
-				cobra.CheckErr("Missing parameter file '-f job-file|-' or '-a aspectURN'")
+				checkErr("Missing parameter file '-f job-file|-' or '-a aspectURN'")
 			}
 
 			serviceID := GetHistory(args[0])
@@ -139,16 +140,20 @@ provided through 'stdin' use '-' as the file name and also include the --format
 			var pyld a.Payload
 			if fileName != "" {
 				if pyld, err = payloadFromFile(fileName, inputFormat); err != nil {
-					cobra.CheckErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
+					checkErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
 				}
 			}
 			if aspectURN != "" {
 				j := fmt.Sprintf(CREATE_FROM_ASPECT, aspectURN, serviceID)
 				if pyld, err = a.LoadPayloadFromBytes([]byte(j), false); err != nil {
-					cobra.CheckErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
+					checkErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
 				}
 			}
 			res, jobCreate, err := sdk.CreateServiceJobRaw(ctxt, serviceID, pyld, 0, CreateAdapter(true), logger)
+			if id := traceID(ctxt); id != "" && !silent {
+				// to find the server side spans of this request
+				fmt.Fprintf(os.Stderr, "Trace ID: %s\n", id)
+			}
 			if err != nil {
 				return err
 			}
@@ -161,13 +166,35 @@ provided through 'stdin' use '-' as the file name and also include the --format
 			}
 			jobID, ok := reply["job-id"].(string)
 			if !ok {
-				cobra.CheckErr("Cannot find job ID in response")
+				checkErr("Cannot find job ID in response")
 			}
 			return readDisplayJob(ctxt, jobID) // a.ReplyPrinter(res, outputFormat == "yaml")
 		},
 	}
 )
 
+var eventsJobCmd = &cobra.Command{
+	Use:   "events [flags] job_id",
+	Short: "Stream the events of a job until it is finished",
+	Long: `Print the events of a job as they arrive, until the job is finished.
+The stream is resumed after connection failures, and '--since' skips all
+events up to and including the one with the given sequence ID.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctxt := RootContext()
+		jobID := GetHistory(args[0])
+		serviceID, err := jobServiceID(ctxt, jobID)
+		if err != nil {
+			return err
+		}
+		var since *string
+		if sinceSeqID != "" {
+			since = &sinceSeqID
+		}
+		return sdk.StreamJobEvents(ctxt, serviceID, jobID, since, printJobEvent, CreateAdapter(true), logger)
+	},
+}
+
 func waitForResult(
 	ctxt context.Context,
 	jobCreate *sdk.JobCreateT,
@@ -196,11 +223,18 @@ func waitForResult(
 }
 
 func watchJob(ctxt context.Context, jobID string, maxChecks int, wait int) (*sdk.JobReadResponseBody, a.Payload, error) {
+	serviceID, err := jobServiceID(ctxt, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	adapter := CreateAdapter(true)
 	done := false
 	tries := 0
 	for !done {
-		time.Sleep(time.Duration(wait) * time.Second)
-		job, pyld, err := readJob(ctxt, jobID)
+		if err := sleep(ctxt, time.Duration(wait)*time.Second); err != nil {
+			return nil, nil, err
+		}
+		job, pyld, err := readServiceJob(ctxt, serviceID, jobID, adapter)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -218,20 +252,30 @@ func watchJob(ctxt context.Context, jobID string, maxChecks int, wait int) (*sdk
 }
 
 func streamJobResults(ctxt context.Context, jobCreate *sdk.JobCreateT) error {
-	onEvent := func(msg *sse.Event) {
-		var out bytes.Buffer
-		if err := json.Indent(&out, msg.Data, "", "  "); err == nil {
-			fmt.Println("---------")
-			s := out.String()
-			fmt.Println(s)
-		}
+	err := sdk.StreamJobEvents(ctxt, jobCreate.ServiceID, jobCreate.JobID, nil, printJobEvent, CreateAdapter(true), logger)
+	if err != nil {
+		checkErr(fmt.Errorf("While watching events for job '%s' - %w", jobCreate.JobID, err))
+	}
+	if isTableOutput() {
+		fmt.Println("---------")
+	}
+	return readDisplayJob(ctxt, jobCreate.JobID)
+}
+
+// printJobEvent prints a job event as indented JSON, or in the selected
+// output format.
+func printJobEvent(ev *sdk.JobEventsResponseBody) {
+	if !isTableOutput() {
+		checkErr(printValue(ev))
+		return
 	}
-	err := sdk.GetJobEvents(ctxt, jobCreate.ServiceID, jobCreate.JobID, nil, onEvent, CreateAdapter(true), logger)
+	b, err := json.MarshalIndent(ev, "", "  ")
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("While watching events for job '%s' - %s", jobCreate.JobID, err))
+		logger.Warn("cannot print job event", log.Error(err))
+		return
 	}
 	fmt.Println("---------")
-	return readDisplayJob(ctxt, jobCreate.JobID)
+	fmt.Println(string(b))
 }
 
 func readDisplayJob(ctxt context.Context, jobID string) error {
@@ -243,41 +287,66 @@ func readDisplayJob(ctxt context.Context, jobID string) error {
 }
 
 func displayJob(job *sdk.JobReadResponseBody, pyld a.Payload) error {
-	switch outputFormat {
-	case "json", "yaml":
-		return a.ReplyPrinter(pyld, outputFormat == "yaml")
+	switch {
+	case !isTableOutput():
+		return printReply(pyld)
 	default:
-		printJob(job, false)
+		printJob(job, isWideOutput())
 	}
 	return nil
 }
 
 func readJob(ctxt context.Context, jobID string) (*sdk.JobReadResponseBody, a.Payload, error) {
+	serviceId, err := jobServiceID(ctxt, jobID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return readServiceJob(ctxt, serviceId, jobID, CreateAdapter(true))
+}
+
+// readServiceJob reads the current state of a job. It is never cached, as
+// the state changes while the job is running.
+func readServiceJob(ctxt context.Context, serviceID string, jobID string, adapter *a.Adapter) (*sdk.JobReadResponseBody, a.Payload, error) {
+	req := &sdk.ReadServiceJobRequest{ServiceId: serviceID, JobId: jobID}
+	return sdk.ReadServiceJob(ctxt, req, adapter, logger)
+}
+
+// jobServiceID returns the ID of the service job `jobID` was created for.
+// The job aspect never changes, so the lookup is cached.
+func jobServiceID(ctxt context.Context, jobID string) (string, error) {
 	selector := sdk.AspectSelector{
 		Entity:         jobID,
 		SchemaPrefix:   JOB_SCHEMA,
 		IncludeContent: true,
 	}
-	var serviceId string
-	if list, _, err := sdk.ListAspect(ctxt, selector, CreateAdapter(true), logger); err == nil {
-		if len(list.Items) != 1 {
-			cobra.CheckErr("Cannot find job")
-		}
-		c := list.Items[0].Content.(map[string]any)
-		if s, ok := c["service-id"].(string); ok {
-			serviceId = s
-		} else {
-			cobra.CheckErr("Cannot find 'service-id' for this job")
+	list, _, err := sdk.ListAspect(ctxt, selector, CreateCachedAdapter(true), logger)
+	if err != nil {
+		return "", err
+	}
+	if len(list.Items) != 1 {
+		checkErr("Cannot find job")
+	}
+	c := list.Items[0].Content.(map[string]any)
+	s, ok := c["service-id"].(string)
+	if !ok {
+		checkErr("Cannot find 'service-id' for this job")
+	}
+	return s, nil
+}
+
+// jobRecordID returns the job ID of a job aspect for '--output ids'
+func jobRecordID(record any) string {
+	if r, ok := record.(map[string]any); ok {
+		if c, ok := r["content"].(map[string]any); ok {
+			if id, ok := c["id"].(string); ok {
+				return id
+			}
 		}
-	} else {
-		return nil, nil, err
 	}
-	req := &sdk.ReadServiceJobRequest{ServiceId: serviceId, JobId: jobID}
-	job, pyld, err := sdk.ReadServiceJob(context.Background(), req, CreateAdapter(true), logger)
-	return job, pyld, err
+	return ""
 }
 
-func printJobListTable(list *aspect.ListResponseBody, wide bool) {
+func printJobListTable(list *aspect.ListResponseBody, next *string, wide bool) {
 	tw2 := table.NewWriter()
 	tw2.AppendHeader(table.Row{"ID", "Service", "Status", "Requested At"})
 	tw2.SetStyle(table.StyleLight)
@@ -321,7 +390,7 @@ func printJobListTable(list *aspect.ListResponseBody, wide bool) {
 		p = append(p, table.Row{"At Time", safeDate(list.AtTime, false)})
 	}
 	p = append(p, table.Row{"Jobs", tw2.Render()})
-	p = addNextPageRow(findNextAspectPage(list.Links), p)
+	p = addNextPageRow(next, p)
 	tw.AppendRows(p)
 
 	fmt.Printf("\n%s\n\n", tw.Render())
@@ -0,0 +1,231 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MicahParks/keyfunc"
+	"github.com/golang-jwt/jwt/v4"
+	log "go.uber.org/zap"
+)
+
+const JWKS_DIR_NAME = "jwks"
+
+// How long to use a JWKS if the provider doesn't say
+const JWKS_DEFAULT_MAX_AGE = time.Hour
+
+// How long to fall back to an expired JWKS if the provider can't be reached,
+// unless the provider sets 'stale-if-error'
+const JWKS_DEFAULT_STALE_IF_ERROR = 7 * 24 * time.Hour
+
+const JWKS_MAX_SIZE = 1 << 20
+
+// jwksEntry is a JWKS document cached on disk, together with how long it
+// can be used.
+type jwksEntry struct {
+	URL        string          `json:"url"`
+	JWKS       json.RawMessage `json:"jwks"`
+	ETag       string          `json:"etag,omitempty"`
+	FetchedAt  time.Time       `json:"fetched-at"`
+	Expires    time.Time       `json:"expires"`
+	StaleUntil time.Time       `json:"stale-until"`
+	noStore    bool
+}
+
+// jwksKeys verifies tokens with the keys of a provider's JWKS. The JWKS is
+// taken from the cache while it's fresh, and otherwise fetched again (or
+// revalidated if it has an ETag). If the provider can't be reached, an
+// expired JWKS is still used for a while.
+type jwksKeys struct {
+	ctxt    *Context
+	url     string
+	entry   *jwksEntry
+	jwks    *keyfunc.JWKS
+	fetched bool
+}
+
+// Returns the keys of the JWKS at `jwksURL`, fetching them with the
+// transport of `ctxt` if they are not cached.
+func getJWKSKeys(ctxt *Context, jwksURL string) (*jwksKeys, error) {
+	k := &jwksKeys{ctxt: ctxt, url: jwksURL, entry: loadJWKSEntry(jwksURL)}
+	if k.entry != nil && time.Now().Before(k.entry.Expires) {
+		if err := k.use(k.entry); err == nil {
+			return k, nil
+		}
+	}
+	if err := k.refresh(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Keyfunc returns the key to verify `token` with. If the token is signed with
+// a key we don't know yet, the JWKS is fetched again, as the provider may have
+// rotated its keys.
+func (k *jwksKeys) Keyfunc(token *jwt.Token) (interface{}, error) {
+	key, err := k.jwks.Keyfunc(token)
+	if errors.Is(err, keyfunc.ErrKIDNotFound) && !k.fetched {
+		logger.Debug("oauth: Unknown key ID, refreshing JWKS", log.String("url", k.url))
+		if rerr := k.refresh(); rerr != nil {
+			return nil, fmt.Errorf("%w - %v", err, rerr)
+		}
+		return k.jwks.Keyfunc(token)
+	}
+	return key, err
+}
+
+func (k *jwksKeys) refresh() error {
+	entry, err := fetchJWKS(k.ctxt, k.url, k.entry)
+	if err == nil {
+		err = k.use(entry)
+	}
+	if err != nil {
+		if k.entry != nil && time.Now().Before(k.entry.StaleUntil) {
+			logger.Warn("oauth: Cannot fetch JWKS, using cached keys", log.String("url", k.url),
+				log.Time("fetched-at", k.entry.FetchedAt), log.Error(err))
+			return k.use(k.entry)
+		}
+		return fmt.Errorf("cannot load the JWKS - %w", err)
+	}
+	k.fetched = true
+	if !entry.noStore {
+		if serr := storeJWKSEntry(entry); serr != nil {
+			logger.Warn("oauth: Cannot cache JWKS", log.String("url", k.url), log.Error(serr))
+		}
+	}
+	return nil
+}
+
+func (k *jwksKeys) use(entry *jwksEntry) error {
+	jwks, err := keyfunc.NewJSON(entry.JWKS)
+	if err != nil {
+		return fmt.Errorf("cannot parse the JWKS from '%s' - %w", entry.URL, err)
+	}
+	k.entry = entry
+	k.jwks = jwks
+	return nil
+}
+
+// Fetches the JWKS at `jwksURL`. If `cached` is set and has an ETag, it is
+// returned with updated expiry if it hasn't changed.
+func fetchJWKS(ctxt *Context, jwksURL string, cached *jwksEntry) (*jwksEntry, error) {
+	req, err := http.NewRequestWithContext(RootContext(), http.MethodGet, jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if cached != nil && cached.ETag != "" {
+		req.Header.Set("If-None-Match", cached.ETag)
+	}
+	client := &http.Client{Transport: tracedTransport(httpTransport(ctxt)), Timeout: time.Duration(timeout) * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var entry jwksEntry
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		entry = *cached
+	case resp.StatusCode == http.StatusOK:
+		body, err := io.ReadAll(io.LimitReader(resp.Body, JWKS_MAX_SIZE))
+		if err != nil {
+			return nil, err
+		}
+		entry = jwksEntry{URL: jwksURL, JWKS: body, ETag: resp.Header.Get("ETag")}
+	default:
+		return nil, fmt.Errorf("unexpected status '%s' from '%s'", resp.Status, jwksURL)
+	}
+	entry.FetchedAt = time.Now()
+	setJWKSExpiry(&entry, resp.Header)
+	return &entry, nil
+}
+
+// Sets how long `entry` can be used, according to the cache headers `h` of
+// the reply it came with.
+func setJWKSExpiry(entry *jwksEntry, h http.Header) {
+	maxAge := JWKS_DEFAULT_MAX_AGE
+	staleIfError := JWKS_DEFAULT_STALE_IF_ERROR
+	cc := map[string]string{}
+	for _, d := range strings.Split(h.Get("Cache-Control"), ",") {
+		name, val, _ := strings.Cut(strings.TrimSpace(d), "=")
+		cc[strings.ToLower(name)] = strings.Trim(val, `"`)
+	}
+	if s, ok := cc["max-age"]; ok {
+		if secs, err := strconv.Atoi(s); err == nil {
+			maxAge = time.Duration(secs) * time.Second
+		}
+	} else if expires, err := http.ParseTime(h.Get("Expires")); err == nil {
+		maxAge = time.Until(expires)
+	}
+	if _, ok := cc["no-cache"]; ok {
+		maxAge = 0
+	}
+	if _, ok := cc["no-store"]; ok {
+		maxAge = 0
+		entry.noStore = true
+	}
+	if s, ok := cc["stale-if-error"]; ok {
+		if secs, err := strconv.Atoi(s); err == nil {
+			staleIfError = time.Duration(secs) * time.Second
+		}
+	}
+	maxAge = max(maxAge, 0)
+	entry.Expires = entry.FetchedAt.Add(maxAge)
+	entry.StaleUntil = entry.Expires.Add(staleIfError)
+}
+
+func jwksEntryPath(jwksURL string) string {
+	h := sha256.Sum256([]byte(jwksURL))
+	return filepath.Join(GetConfigDir(true), JWKS_DIR_NAME, hex.EncodeToString(h[:])+".json")
+}
+
+func loadJWKSEntry(jwksURL string) *jwksEntry {
+	data, err := os.ReadFile(jwksEntryPath(jwksURL))
+	if err != nil {
+		return nil
+	}
+	var entry jwksEntry
+	if err = json.Unmarshal(data, &entry); err != nil || entry.URL != jwksURL {
+		return nil
+	}
+	return &entry
+}
+
+func storeJWKSEntry(entry *jwksEntry) error {
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := jwksEntryPath(entry.URL)
+	if err = os.MkdirAll(filepath.Dir(path), fs.FileMode(0700)); err != nil {
+		return err
+	}
+	return writeFileLocked(path, data, fs.FileMode(0600))
+}
@@ -0,0 +1,101 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	log "go.uber.org/zap"
+)
+
+func TestJWKSKeys_CachesAndRefreshes(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	key1, kid1 := newTestSigningKey(t), "k1"
+	key2, kid2 := newTestSigningKey(t), "k2"
+
+	var mu sync.Mutex
+	fetches := 0
+	current, currentKid, cacheControl := key1, kid1, "max-age=3600"
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		fetches++
+		w.Header().Set("Cache-Control", cacheControl)
+		e := base64.RawURLEncoding.EncodeToString(big.NewInt(int64(current.E)).Bytes())
+		n := base64.RawURLEncoding.EncodeToString(current.N.Bytes())
+		fmt.Fprintf(w, `{"keys":[{"kty":"RSA","alg":"RS256","use":"sig","kid":"%s","n":"%s","e":"%s"}]}`, currentKid, n, e)
+	}))
+	defer srv.Close()
+
+	verify := func(key *rsa.PrivateKey, kid string) {
+		t.Helper()
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{Subject: "me"})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys, err := getJWKSKeys(&Context{Name: "test"}, srv.URL)
+		if err != nil {
+			t.Fatalf("cannot get JWKS - %v", err)
+		}
+		if _, err = jwt.Parse(signed, keys.Keyfunc); err != nil {
+			t.Fatalf("cannot verify token signed with '%s' - %v", kid, err)
+		}
+	}
+	expectFetches := func(n int) {
+		t.Helper()
+		mu.Lock()
+		defer mu.Unlock()
+		if fetches != n {
+			t.Fatalf("expected %d JWKS fetches, got %d", n, fetches)
+		}
+	}
+
+	verify(key1, kid1)
+	verify(key1, kid1)
+	expectFetches(1)
+
+	// the provider rotates its key, which is picked up on the first unknown kid
+	mu.Lock()
+	current, currentKid, cacheControl = key2, kid2, "no-cache"
+	mu.Unlock()
+	verify(key2, kid2)
+	expectFetches(2)
+
+	// the cached copy has to be revalidated, but still works while the provider is down
+	srv.Close()
+	verify(key2, kid2)
+}
+
+func newTestSigningKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
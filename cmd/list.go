@@ -0,0 +1,126 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+)
+
+var (
+	listAll  bool
+	maxItems int
+)
+
+func addPagingFlags(cmd *cobra.Command) {
+	fs := cmd.Flags()
+	fs.BoolVar(&listAll, "all", false, "fetch all pages, not just the first one")
+	fs.IntVar(&maxItems, "max-items", 0, "fetch pages until this many records are listed (implies '--all')")
+}
+
+// printList prints the list returned by `first`. Unless '--all' or
+// '--max-items' is set, that is only the first page, which is printed
+// as a whole (including the 'next' cursor). Otherwise it follows the 'next'
+// links and streams each page as it arrives, either as a table per page
+// or through the printer, which joins the items of all pages.
+//
+// `items` returns the items field of a page, `printTable` prints a page
+// as table, with `next` being the 'next' link to show, if any.
+func printList[L any, T any](
+	first sdk.PageFetcher,
+	adapter *a.Adapter,
+	items func(list *L) *[]T,
+	printTable func(list *L, next *string),
+) error {
+	ctxt := RootContext()
+	if !listAll && maxItems <= 0 {
+		pyld, err := first(ctxt)
+		if err != nil {
+			return err
+		}
+		return printPage(pyld, printTable)
+	}
+
+	pr := getPrinter()
+	err := streamPages(ctxt, first, adapter, items, printTable)
+	if !pr.IsTable() {
+		// closes the array of the 'json' and 'yaml' formats, also after an
+		// error, so the items listed so far can still be parsed
+		if endErr := pr.End(os.Stdout); err == nil {
+			err = endErr
+		}
+	}
+	return err
+}
+
+// Prints the pages of the list returned by `first`, see 'printList'.
+func streamPages[L any, T any](
+	ctxt context.Context,
+	first sdk.PageFetcher,
+	adapter *a.Adapter,
+	items func(list *L) *[]T,
+	printTable func(list *L, next *string),
+) error {
+	pr := getPrinter()
+	remaining := maxItems
+	for page, err := range sdk.ListPages[L](ctxt, first, adapter, logger) {
+		if err != nil {
+			return err
+		}
+		raw, err := page.Payload.AsObject()
+		if err != nil {
+			return fmt.Errorf("failed to parse response body: %w", err)
+		}
+		if maxItems > 0 {
+			list := items(page.List)
+			*list = (*list)[:min(remaining, len(*list))]
+			if ri, ok := raw["items"].([]any); ok {
+				raw["items"] = ri[:min(remaining, len(ri))]
+			}
+			remaining -= len(*list)
+		}
+		if pr.IsTable() {
+			printTable(page.List, nil)
+		} else if err = pr.Stream(os.Stdout, raw); err != nil {
+			return err
+		}
+		if maxItems > 0 && remaining <= 0 {
+			break
+		}
+	}
+	return nil
+}
+
+func printPage[L any](pyld a.Payload, printTable func(list *L, next *string)) error {
+	if !isTableOutput() {
+		return printReply(pyld)
+	}
+	var list L
+	if err := pyld.AsType(&list); err != nil {
+		return fmt.Errorf("failed to parse response body: %w", err)
+	}
+	var next *string
+	if n := sdk.NextPage(pyld); n != "" {
+		next = &n
+	}
+	printTable(&list, next)
+	return nil
+}
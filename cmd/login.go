@@ -15,14 +15,16 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
-	"github.com/MicahParks/keyfunc"
 	jwt "github.com/golang-jwt/jwt/v4"
 	adpt "github.com/ivcap-works/ivcap-cli/pkg/adapter"
 	"github.com/skip2/go-qrcode"
@@ -32,19 +34,43 @@ import (
 	yaml "gopkg.in/yaml.v3"
 )
 
-var refreshTokenParam string
+var (
+	refreshTokenParam     string
+	loginBrowser          bool
+	loginCallbackPort     int
+	loginClientID         string
+	loginClientSecretFile string
+)
 
 func init() {
 	contextCmd.AddCommand(loginCmd)
 	var flags = loginCmd.Flags()
 	flags.StringVarP(&refreshTokenParam, "refresh-token", "r", "", "refresh token for login context")
+	flags.BoolVar(&loginBrowser, "browser", false, "login in a browser on this machine instead of with a login code")
+	flags.IntVar(&loginCallbackPort, "callback-port", 0, "local port to receive the '--browser' login on [random]")
+	flags.StringVar(&loginClientID, "client-id", "", "login as service account with the client credentials grant")
+	flags.StringVar(&loginClientSecretFile, "client-secret-file", "", "file containing the secret of '--client-id'")
+	loginCmd.MarkFlagsRequiredTogether("client-id", "client-secret-file")
+	loginCmd.MarkFlagsMutuallyExclusive("client-id", "refresh-token", "browser")
 	contextCmd.AddCommand(logoutCmd)
 }
 
 var loginCmd = &cobra.Command{
 	Use:   "login",
 	Short: "Authenticate with a current deployment/context",
-	Run:   login,
+	Long: `Authenticates with the deployment of the active context. By default, this
+shows a QR code and a link to login with a browser (device code flow).
+
+With '--browser', the login page is opened in a browser on this machine
+instead, which hands the result back to a temporary listener on the loopback
+interface (authorization code flow with PKCE). If no browser can be started,
+it falls back to the device code flow.
+
+For CI jobs and service accounts, use '--client-id' and '--client-secret-file'
+to login with the OAuth client credentials grant instead. The client ID and
+the location of the secret are kept in the context, and a new access token is
+obtained automatically whenever the current one expires.`,
+	Run: login,
 }
 
 var logoutCmd = &cobra.Command{
@@ -52,11 +78,11 @@ var logoutCmd = &cobra.Command{
 	Short: "Remove authentication tokens from the current deployment/context",
 	RunE: func(cmd *cobra.Command, args []string) (err error) {
 		ctxt := GetActiveContext()
-		ctxt.AccessToken = ""
-		ctxt.AccessTokenExpiry = time.Time{}
-		ctxt.RefreshToken = ""
-		SetContext(ctxt, true)
-		return
+		UpdateContext(ctxt.Name, func(stored *Context) {
+			stored.ClientID = ""
+			stored.ClientSecretFile = ""
+		})
+		return deleteCredentials(ctxt)
 	},
 }
 
@@ -140,46 +166,162 @@ func getAccessToken(refreshIfExpired bool) (accessToken string) {
 	// we'll use the refresh token to get ourselves a new one. If the refresh
 	// token has expired, we'll prompt the user to login again.
 	ctxt := GetActiveContext()
+	if err := loadCredentials(ctxt); err != nil {
+		checkErr(err)
+	}
 	accessTokenExpiry := ctxt.AccessTokenExpiry
 	if time.Now().After(accessTokenExpiry) {
 		if !refreshIfExpired {
 			return ""
 		}
-		if ctxt.RefreshToken == "" {
+		if ctxt.RefreshToken == "" && ctxt.ClientID == "" {
 			// We don't have a refresh token for this context, so we fail early
-			cobra.CheckErr("Could not login - invalid credentials. Please use the login command to refresh your credentials")
+			checkErr(&LoginError{errors.New("Could not login - invalid credentials. Please use the login command to refresh your credentials")})
 		}
 
 		// Access token has expired, we have to refresh it
-		authProvider := getLoginInformation(ctxt)
-		authProvider.grantType = "refresh_token"
+		_, err := refreshStoredContextToken(RootContext(), ctxt, contextToken(ctxt))
+		if err != nil && !errors.Is(err, adpt.ErrTokenNotRefreshable) {
+			checkErr(&LoginError{err})
+		}
+	} // Access token has not expired, let's just use it
 
-		if (authProvider.TokenURL != "") && (authProvider.ClientID != "") {
-			params := url.Values{
-				"refresh_token": {ctxt.RefreshToken},
-			}
-			tokenResponse := getTokenResponse(authProvider, params, ctxt, false)
-			if tokenResponse.ErrorString != "" {
-				logger.Warn("tokenResponse", log.String("error", tokenResponse.ErrorString))
-				cobra.CheckErr("oauth: Unexpected error from authentication provider")
-			}
+	return ctxt.AccessToken
+}
 
-			ctxt.AccessToken = tokenResponse.AccessToken
-			if tokenResponse.RefreshToken != "" {
-				ctxt.RefreshToken = tokenResponse.RefreshToken
-			}
-			// Add a 10 second buffer to expiry to account for differences in clock time between client
-			// server and message transport time (oauth2 library does the same thing)
-			ctxt.AccessTokenExpiry = time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10))
+// Returns a token source for the adapter which refreshes the access token of
+// `ctxt` shortly before it expires, or when it gets rejected, and persists
+// the new (and potentially rotated refresh) token in the config file.
+func contextTokenSource(ctxt *Context) adpt.TokenSource {
+	refresh := func(c context.Context, current *adpt.Token) (*adpt.Token, error) {
+		return refreshStoredContextToken(c, ctxt, current)
+	}
+	return adpt.RefreshingTokenSource(contextToken(ctxt), refresh, nil)
+}
 
-			// We also get an updated ID token, let's make sure we have the latest info
-			ParseIDToken(&tokenResponse, ctxt, authProvider.JwksURL)
-			SetContext(ctxt, true)
-			logger.Info("Successfully acquired new access token.", log.String("expires", ctxt.AccessTokenExpiry.Format(time.RFC822)))
-		} // Access token has not expired, let's just use it
+// Refreshes the `current` token of `ctxt` and saves the new one in the
+// credential store. The credentials of the context stay locked while
+// refreshing, so that parallel 'ivcap' processes don't use the same
+// (rotating) refresh token. If one of them already refreshed it, we simply
+// pick up its token instead.
+func refreshStoredContextToken(c context.Context, ctxt *Context, current *adpt.Token) (*adpt.Token, error) {
+	dir, err := getCredentialsDir()
+	if err != nil {
+		return nil, err
+	}
+	unlock, err := lockFile(filepath.Join(dir, credentialsFileName(ctxt.Name)))
+	if err != nil {
+		return nil, err
 	}
+	defer unlock()
 
-	return ctxt.AccessToken
+	store, err := getCredentialStore(ctxt.CredentialStore, ctxt)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := store.Load(ctxt)
+	if err != nil {
+		return nil, err
+	}
+	if stored.AccessToken != current.AccessToken && stored.AccessToken != "" &&
+		time.Now().Add(adpt.DefaultExpiryMargin).Before(stored.AccessTokenExpiry) {
+		setCredentials(ctxt, stored)
+		return contextToken(ctxt), nil
+	}
+	var token *adpt.Token
+	if ctxt.ClientID != "" {
+		token, err = requestClientCredentialsToken(ctxt)
+	} else {
+		refreshToken := current.RefreshToken
+		if stored.RefreshToken != "" {
+			refreshToken = stored.RefreshToken
+		}
+		if refreshToken == "" {
+			return nil, adpt.ErrTokenNotRefreshable
+		}
+		token, err = refreshContextToken(c, ctxt, refreshToken)
+		if err == nil && token == nil {
+			err = adpt.ErrTokenNotRefreshable
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	setContextToken(ctxt, token)
+	if err = storeLogin(ctxt); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// Saves the account information obtained when logging into `ctxt` in the
+// config file, and its tokens in the credential store.
+func storeLogin(ctxt *Context) error {
+	UpdateContext(ctxt.Name, func(stored *Context) {
+		stored.ClientID = ctxt.ClientID
+		stored.ClientSecretFile = ctxt.ClientSecretFile
+		stored.AccountID = ctxt.AccountID
+		stored.ProviderID = ctxt.ProviderID
+		stored.AccountName = ctxt.AccountName
+		stored.AccountNickName = ctxt.AccountNickName
+		stored.Email = ctxt.Email
+	})
+	return saveCredentials(ctxt)
+}
+
+// Exchanges `refreshToken` for a new access token with the identity provider
+// of `ctxt`. Also updates the account information in `ctxt` from the
+// accompanying ID token. Returns nil if the provider doesn't support refreshing.
+func refreshContextToken(_ context.Context, ctxt *Context, refreshToken string) (*adpt.Token, error) {
+	authProvider, err := fetchLoginInformation()
+	if err != nil {
+		return nil, err
+	}
+	authProvider.grantType = "refresh_token"
+	if authProvider.TokenURL == "" || authProvider.ClientID == "" {
+		return nil, nil
+	}
+	params := url.Values{
+		"refresh_token": {refreshToken},
+	}
+	tokenResponse, err := requestToken(authProvider, params, false)
+	if err != nil {
+		return nil, err
+	}
+	if tokenResponse.ErrorString != "" {
+		logger.Warn("tokenResponse", log.String("error", tokenResponse.ErrorString))
+		return nil, errors.New("oauth: Unexpected error from authentication provider")
+	}
+	// We also get an updated ID token, let's make sure we have the latest info
+	if err = parseIDToken(&tokenResponse, ctxt, authProvider.JwksURL); err != nil {
+		return nil, err
+	}
+	token := &adpt.Token{
+		AccessToken:  tokenResponse.AccessToken,
+		RefreshToken: tokenResponse.RefreshToken,
+		// Add a 10 second buffer to expiry to account for differences in clock time between client
+		// server and message transport time (oauth2 library does the same thing)
+		Expiry: time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10)),
+	}
+	logger.Info("Successfully acquired new access token.", log.String("expires", token.Expiry.Format(time.RFC822)))
+	return token, nil
+}
+
+func contextToken(ctxt *Context) *adpt.Token {
+	return &adpt.Token{
+		AccessToken:  ctxt.AccessToken,
+		RefreshToken: ctxt.RefreshToken,
+		Expiry:       ctxt.AccessTokenExpiry,
+	}
+}
+
+// Saves `token` in `ctxt`.
+func setContextToken(ctxt *Context, token *adpt.Token) {
+	ctxt.AccessToken = token.AccessToken
+	if token.RefreshToken != "" {
+		ctxt.RefreshToken = token.RefreshToken
+	}
+	ctxt.AccessTokenExpiry = token.Expiry
 }
 
 func IsAuthorised() bool {
@@ -187,6 +329,14 @@ func IsAuthorised() bool {
 }
 
 func getTokenResponse(authProvider *AuthProvider, params url.Values, ctxt *Context, allowStatusForbidden bool) (tokenResponse deviceTokenResponse) {
+	tokenResponse, err := requestToken(authProvider, params, allowStatusForbidden)
+	if err != nil {
+		checkErr(err.Error())
+	}
+	return
+}
+
+func requestToken(authProvider *AuthProvider, params url.Values, allowStatusForbidden bool) (tokenResponse deviceTokenResponse, err error) {
 	adapter := CreateAdapter(false)
 	params.Set("grant_type", authProvider.grantType)
 	params.Set("client_id", authProvider.ClientID)
@@ -196,40 +346,43 @@ func getTokenResponse(authProvider *AuthProvider, params url.Values, ctxt *Conte
 	defer cancel()
 
 	var pyld adpt.Payload
-	var err error
 	pyld, err = (*adapter).PostForm(ctx, authProvider.TokenURL, params, nil, logger)
 	if err != nil {
 		var apiErr *adpt.ApiError
-		if errors.As(err, &apiErr) && allowStatusForbidden {
-			if apiErr.StatusCode == http.StatusForbidden {
-				pyld = apiErr.Payload
-			} else {
-				cobra.CheckErr(fmt.Sprintf("Cannot obtain OAuth Token, please try `ivcap context login`. Error detail: - %s", err))
-			}
+		if errors.As(err, &apiErr) && allowStatusForbidden && apiErr.StatusCode == http.StatusForbidden {
+			pyld = apiErr.Payload
 		} else {
-			cobra.CheckErr(fmt.Sprintf("Cannot obtain OAuth Token, please try `ivcap context login`. Error detail: - %s", err))
-			return // never reached
+			err = fmt.Errorf("Cannot obtain OAuth Token, please try `ivcap context login`. Error detail: - %w", err)
+			return
 		}
 	}
 
 	if err = pyld.AsType(&tokenResponse); err != nil {
 		logger.Error("while parsing 'deviceTokenResponse'", log.String("pyld", string(pyld.AsBytes())))
-		cobra.CheckErr("oauth: Cannot decode token response")
+		err = errors.New("oauth: Cannot decode token response")
 		return
 	}
 
 	switch tokenResponse.ErrorString {
 	case "expired_token":
-		cobra.CheckErr("The login process was not completed in time - please login again")
+		err = errors.New("The login process was not completed in time - please login again")
 	case "access_denied":
-		cobra.CheckErr("Could not login - access was denied")
+		err = errors.New("Could not login - access was denied")
 	case "invalid_grant":
-		cobra.CheckErr("Could not login - expired credentials. Please use the login command to refresh your credentials")
+		err = errors.New("Could not login - expired credentials. Please use the login command to refresh your credentials")
 	}
 	return
 }
 
 func getLoginInformation(ctxt *Context) (authProvider *AuthProvider) {
+	authProvider, err := fetchLoginInformation()
+	if err != nil {
+		checkErr(err.Error())
+	}
+	return
+}
+
+func fetchLoginInformation() (*AuthProvider, error) {
 	adpt := CreateAdapter(false)
 
 	ctx, cancel := NewTimeoutContext()
@@ -237,17 +390,14 @@ func getLoginInformation(ctxt *Context) (authProvider *AuthProvider) {
 
 	pyld, err := (*adpt).Get(ctx, "/1/authinfo.yaml", logger)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("oauth: Cannot retrieve authentication info from server - %s", err))
-		return
+		return nil, fmt.Errorf("oauth: Cannot retrieve authentication info from server - %w", err)
 	}
 	var ai AuthInfo
 	if err = yaml.Unmarshal(pyld.AsBytes(), &ai); err != nil {
-		cobra.CheckErr(fmt.Sprintf("oauth: Cannot parse authentication info from server. - %s", err))
-		return
+		return nil, fmt.Errorf("oauth: Cannot parse authentication info from server. - %s", err)
 	}
 	if ai.Version != 1 {
-		cobra.CheckErr("oauth: Client out of date: Please update this application")
-		return
+		return nil, errors.New("oauth: Client out of date: Please update this application")
 	}
 	providers := ai.ProviderList.AuthProviders
 	defProvider := ai.ProviderList.DefaultProviderId
@@ -255,33 +405,35 @@ func getLoginInformation(ctxt *Context) (authProvider *AuthProvider) {
 		return verifyProviderInfo(&provider)
 	}
 	if defProvider != "" {
-		cobra.CheckErr(fmt.Sprintf("oauth: Undeclared authentication provider '%s' returned", defProvider))
-		return
+		return nil, fmt.Errorf("oauth: Undeclared authentication provider '%s' returned", defProvider)
 	}
 	// If no default provider is given, just pick the first one
 	for _, p := range providers {
 		p := p // golang for loop re-use the same var
 		return verifyProviderInfo(&p)
 	}
-	cobra.CheckErr("oauth: Cannot extract a suitable authentication provider")
-	return // never get here
+	return nil, errors.New("oauth: Cannot extract a suitable authentication provider")
 }
 
-func verifyProviderInfo(p *AuthProvider) *AuthProvider {
-	f := func(name string, urls string) {
+func verifyProviderInfo(p *AuthProvider) (*AuthProvider, error) {
+	f := func(name string, urls string) error {
 		if _, e := url.ParseRequestURI(urls); e != nil {
-			cobra.CheckErr(fmt.Sprintf("oauth: Authentication provider's %s '%s' is not a valid URL - %s", name, urls, e))
+			return fmt.Errorf("oauth: Authentication provider's %s '%s' is not a valid URL - %s", name, urls, e)
+		}
+		return nil
+	}
+	for _, u := range [][2]string{
+		{"LoginURL", p.LoginURL}, {"TokenURL", p.TokenURL}, {"CodeURL", p.CodeURL}, {"JwksURL", p.JwksURL},
+	} {
+		if err := f(u[0], u[1]); err != nil {
+			return nil, err
 		}
 	}
-	f("LoginURL", p.LoginURL)
-	f("TokenURL", p.TokenURL)
-	f("CodeURL", p.CodeURL)
-	f("JwksURL", p.JwksURL)
 
 	if p.Audience == "" {
-		cobra.CheckErr("oauth: Authentication provider's audience is not set in the authinfo.yaml file")
+		return nil, errors.New("oauth: Authentication provider's audience is not set in the authinfo.yaml file")
 	}
-	return p
+	return p, nil
 }
 
 func requestDeviceCode(authProvider *AuthProvider) (code *DeviceCode) {
@@ -297,14 +449,14 @@ func requestDeviceCode(authProvider *AuthProvider) (code *DeviceCode) {
 	}
 	pyld, err := (*adpt).PostForm(ctx, authProvider.CodeURL, params, nil, logger)
 	if err != nil {
-		cobra.CheckErr("oauth: Error while requesting device code from authentication provider")
+		checkErr("oauth: Error while requesting device code from authentication provider")
 		return
 	}
 
 	var dc DeviceCode
 	if err = pyld.AsType(&dc); err != nil {
 		logger.Error("while parsing 'DeviceCode'", log.String("pyld", string(pyld.AsBytes())))
-		cobra.CheckErr("oauth: Cannot understand device information returned from authentication provider")
+		checkErr("oauth: Cannot understand device information returned from authentication provider")
 		return
 	}
 	return &dc
@@ -335,7 +487,7 @@ func waitForTokens(authProvider *AuthProvider, deviceCode *DeviceCode, ctxt *Con
 			// the wait interval
 			deviceCode.Interval *= 2
 		default:
-			cobra.CheckErr(fmt.Sprintf("oauth: Authentication provider returned unexpected error '%s'", tokenResponse.ErrorString))
+			checkErr(fmt.Sprintf("oauth: Authentication provider returned unexpected error '%s'", tokenResponse.ErrorString))
 		}
 
 		elapsedTime := int64(time.Since(startTime).Seconds())
@@ -345,18 +497,23 @@ func waitForTokens(authProvider *AuthProvider, deviceCode *DeviceCode, ctxt *Con
 		lastElapsedTime = elapsedTime
 
 		// We sleep until we're allowed to poll again
-		time.Sleep(time.Duration(deviceCode.Interval) * time.Second)
+		if err := sleep(RootContext(), time.Duration(deviceCode.Interval)*time.Second); err != nil {
+			checkErr(fmt.Errorf("oauth: Stopped waiting for login - %w", err))
+		}
 	}
 }
 
 func ParseIDToken(tokenResponse *deviceTokenResponse, ctxt *Context, jwksURL string) {
-	// Lookup the public key to verify the signature (and check we have a valid token)
+	if err := parseIDToken(tokenResponse, ctxt, jwksURL); err != nil {
+		checkErr(err.Error())
+	}
+}
 
-	// TODO: Download and cache the jwks data rather than download it on every login / token
-	// refresh
-	jwks, err := keyfunc.Get(jwksURL, keyfunc.Options{})
+func parseIDToken(tokenResponse *deviceTokenResponse, ctxt *Context, jwksURL string) error {
+	// Lookup the public key to verify the signature (and check we have a valid token)
+	jwks, err := getJWKSKeys(ctxt, jwksURL)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot load the JWKS - %s", err))
+		return err
 	}
 	idToken, err := jwt.ParseWithClaims(tokenResponse.IDToken, &CustomIdClaims{}, jwks.Keyfunc)
 	if err != nil {
@@ -365,25 +522,26 @@ func ParseIDToken(tokenResponse *deviceTokenResponse, ctxt *Context, jwksURL str
 			// let's wait a bit and try again as this is most likely due to clock shifts as we immediately check
 			// token after it has been created.
 			logger.Info("oauth: Waiting a few seconds as token is not valid yet")
-			time.Sleep(time.Duration(3 * time.Second))
-			ParseIDToken(tokenResponse, ctxt, jwksURL)
-			return
+			if err := sleep(RootContext(), 3*time.Second); err != nil {
+				return err
+			}
+			return parseIDToken(tokenResponse, ctxt, jwksURL)
 		case errors.Is(err, jwt.ErrTokenMalformed):
-			cobra.CheckErr(fmt.Sprintf("malformed ID Token received - %s", err))
+			return fmt.Errorf("malformed ID Token received - %s", err)
 		case errors.Is(err, jwt.ErrTokenExpired), errors.Is(err, jwt.ErrTokenNotValidYet):
 			// Token is either expired or not active yet
-			cobra.CheckErr(fmt.Sprintf("expired ID Token received - %s", err))
+			return fmt.Errorf("expired ID Token received - %s", err)
 		default:
-			cobra.CheckErr(fmt.Sprintf("cannot verify ID token - %s", err))
+			return fmt.Errorf("cannot verify ID token - %s", err)
 		}
 	}
 
 	if idToken == nil {
-		cobra.CheckErr("Should never happen. No 'idToken' and no error")
-		return
+		return errors.New("Should never happen. No 'idToken' and no error")
 	}
 	if claims, ok := idToken.Claims.(*CustomIdClaims); ok && idToken.Valid {
 		// Save the data from the ID token into the config/context
+		ctxt.IDToken = tokenResponse.IDToken
 		ctxt.AccountName = claims.Name
 		ctxt.Email = claims.Email
 		ctxt.AccountNickName = claims.Nickname
@@ -394,10 +552,18 @@ func ParseIDToken(tokenResponse *deviceTokenResponse, ctxt *Context, jwksURL str
 		}
 		ctxt.ProviderID = fmt.Sprintf("urn:%s:provider:%s", URN_PREFIX, providerID)
 	}
+	return nil
 }
 
 func login(_ *cobra.Command, args []string) {
 	ctxt := GetActiveContext() // will always return ctxt or have already failed
+	// a new login replaces any previous service account login
+	ctxt.ClientID = ""
+	ctxt.ClientSecretFile = ""
+	if loginClientID != "" {
+		loginByClientCredentials(ctxt)
+		return
+	}
 	authProvider := getLoginInformation(ctxt)
 
 	// offline_access is required for the refresh tokens to be sent through
@@ -409,13 +575,40 @@ func login(_ *cobra.Command, args []string) {
 		loginByRefreshToken(ctxt, authProvider, refreshTokenParam)
 		return
 	}
+	var tokenResponse *deviceTokenResponse
+	if loginBrowser {
+		var err error
+		if tokenResponse, err = loginByBrowser(ctxt, authProvider); errors.Is(err, errNoBrowser) {
+			fmt.Printf("Cannot open a browser (%s), using a login code instead.\n\n", err)
+		} else if err != nil {
+			checkErr(err)
+		}
+	}
+	if tokenResponse == nil {
+		tokenResponse = loginByDeviceCode(ctxt, authProvider)
+	}
+	ParseIDToken(tokenResponse, ctxt, authProvider.JwksURL)
+
+	ctxt.AccessToken = tokenResponse.AccessToken
+	// Add a 10 second buffer to expiry to account for differences in clock time between client
+	// server and message transport time (oauth2 library does the same thing)
+	ctxt.AccessTokenExpiry = time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10))
+	ctxt.RefreshToken = tokenResponse.RefreshToken
+	if err := storeLogin(ctxt); err != nil {
+		checkErr(err)
+	}
+
+	fmt.Printf("Success: You are authorised.\n")
+}
+
+func loginByDeviceCode(ctxt *Context, authProvider *AuthProvider) *deviceTokenResponse {
 	// First request a device code for this command line tool
 	deviceCode := requestDeviceCode(authProvider)
 
 	// Show QR code for authenticating via a web browser
 	qrCode, err := qrcode.New(deviceCode.VerificationURLComplete, qrcode.Medium)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot create QR code - %s", err))
+		checkErr(fmt.Sprintf("cannot create QR code - %s", err))
 	}
 	qrCodeStrings := qrCode.ToSmallString(true)
 
@@ -428,17 +621,7 @@ func login(_ *cobra.Command, args []string) {
 	fmt.Println("or scan the QR Code to be taken to the login page")
 	fmt.Println("Waiting for authorisation...")
 
-	tokenResponse := waitForTokens(authProvider, deviceCode, ctxt)
-	ParseIDToken(tokenResponse, ctxt, authProvider.JwksURL)
-
-	ctxt.AccessToken = tokenResponse.AccessToken
-	// Add a 10 second buffer to expiry to account for differences in clock time between client
-	// server and message transport time (oauth2 library does the same thing)
-	ctxt.AccessTokenExpiry = time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10))
-	ctxt.RefreshToken = tokenResponse.RefreshToken
-	SetContext(ctxt, true)
-
-	fmt.Printf("Success: You are authorised.\n")
+	return waitForTokens(authProvider, deviceCode, ctxt)
 }
 
 func loginByRefreshToken(ctxt *Context, authProvider *AuthProvider, requestTokenParam string) {
@@ -452,5 +635,72 @@ func loginByRefreshToken(ctxt *Context, authProvider *AuthProvider, requestToken
 	// server and message transport time (oauth2 library does the same thing)
 	ctxt.AccessTokenExpiry = time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10))
 	ctxt.RefreshToken = tokenResponse.RefreshToken
-	SetContext(ctxt, true)
+	if err := storeLogin(ctxt); err != nil {
+		checkErr(err)
+	}
+}
+
+func loginByClientCredentials(ctxt *Context) {
+	secretFile, err := filepath.Abs(loginClientSecretFile)
+	if err != nil {
+		checkErr(&UsageError{err})
+	}
+	ctxt.ClientID = loginClientID
+	ctxt.ClientSecretFile = secretFile
+	token, err := requestClientCredentialsToken(ctxt)
+	if err != nil {
+		checkErr(err)
+	}
+	// there is no ID token, so we don't know anything about the account
+	ctxt.AccountID = ""
+	ctxt.ProviderID = ""
+	ctxt.AccountName = ""
+	ctxt.AccountNickName = ""
+	ctxt.Email = ""
+	ctxt.RefreshToken = ""
+	ctxt.IDToken = ""
+	setContextToken(ctxt, token)
+	if err := storeLogin(ctxt); err != nil {
+		checkErr(err)
+	}
+	fmt.Printf("Success: You are authorised as client '%s'.\n", ctxt.ClientID)
+}
+
+// Obtains a new access token for `ctxt` with the client credentials grant,
+// using the client ID and secret the context has been logged in with.
+func requestClientCredentialsToken(ctxt *Context) (*adpt.Token, error) {
+	data, err := os.ReadFile(filepath.Clean(ctxt.ClientSecretFile))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read client secret - %w", err)
+	}
+	secret := strings.TrimSpace(string(data))
+	if secret == "" {
+		return nil, fmt.Errorf("client secret file '%s' is empty", ctxt.ClientSecretFile)
+	}
+	authProvider, err := fetchLoginInformation()
+	if err != nil {
+		return nil, err
+	}
+	authProvider.grantType = "client_credentials"
+	authProvider.ClientID = ctxt.ClientID
+	params := url.Values{
+		"client_secret": {secret},
+		"audience":      {authProvider.Audience},
+	}
+	tokenResponse, err := requestToken(authProvider, params, false)
+	if err != nil {
+		return nil, err
+	}
+	if tokenResponse.ErrorString != "" {
+		return nil, fmt.Errorf("oauth: Cannot login as client '%s' - %s", ctxt.ClientID, tokenResponse.ErrorString)
+	}
+	token := &adpt.Token{
+		AccessToken: tokenResponse.AccessToken,
+		// Add a 10 second buffer to expiry to account for differences in clock time between client
+		// server and message transport time (oauth2 library does the same thing)
+		Expiry: time.Now().Add(time.Second * time.Duration(tokenResponse.ExpiresIn-10)),
+	}
+	logger.Info("Successfully acquired access token for client.", log.String("client-id", ctxt.ClientID),
+		log.String("expires", token.Expiry.Format(time.RFC822)))
+	return token, nil
 }
@@ -0,0 +1,181 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// How long to wait for the user to complete the login in the browser
+const BROWSER_LOGIN_TIMEOUT = 5 * time.Minute
+
+const BROWSER_LOGIN_CALLBACK_PATH = "/callback"
+
+var errNoBrowser = errors.New("no browser available")
+
+// Logs into `ctxt` with the authorization code flow. The provider's login page
+// is opened in the browser with a PKCE challenge, and redirects back to a
+// listener on the loopback interface, which receives the code to exchange
+// for the tokens. Returns an error wrapping `errNoBrowser` if no browser
+// could be started.
+func loginByBrowser(ctxt *Context, authProvider *AuthProvider) (*deviceTokenResponse, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", loginCallbackPort))
+	if err != nil {
+		return nil, fmt.Errorf("cannot listen for login callback - %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+	redirectURI := fmt.Sprintf("http://%s%s", listener.Addr().String(), BROWSER_LOGIN_CALLBACK_PATH)
+
+	verifier, challenge := newPKCE()
+	state := randomString(16)
+	loginURL, err := url.Parse(authProvider.LoginURL)
+	if err != nil {
+		return nil, err
+	}
+	q := loginURL.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", authProvider.ClientID)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("scope", authProvider.scopes)
+	q.Set("audience", authProvider.Audience)
+	q.Set("state", state)
+	q.Set("code_challenge", challenge)
+	q.Set("code_challenge_method", "S256")
+	loginURL.RawQuery = q.Encode()
+
+	if err = openBrowser(loginURL.String()); err != nil {
+		return nil, err
+	}
+	fmt.Println("Please complete the login in your browser. If it didn't open, go to:")
+	fmt.Println()
+	fmt.Println("   ", loginURL.String())
+	fmt.Println()
+	fmt.Println("Waiting for authorisation...")
+
+	ctx, cancel := context.WithTimeout(RootContext(), BROWSER_LOGIN_TIMEOUT)
+	defer cancel()
+	code, err := waitForAuthCode(ctx, listener, state)
+	if err != nil {
+		return nil, err
+	}
+
+	authProvider.grantType = "authorization_code"
+	params := url.Values{
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+	}
+	tokenResponse := getTokenResponse(authProvider, params, ctxt, false)
+	if tokenResponse.ErrorString != "" {
+		return nil, fmt.Errorf("oauth: Could not login - %s", tokenResponse.ErrorString)
+	}
+	return &tokenResponse, nil
+}
+
+// Serves the login callback on `listener` until it has been called with
+// `state`, and returns the authorization code it received.
+func waitForAuthCode(ctxt context.Context, listener net.Listener, state string) (string, error) {
+	type result struct {
+		code string
+		err  error
+	}
+	resultC := make(chan result, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+BROWSER_LOGIN_CALLBACK_PATH, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			// not for us, maybe an old browser tab
+			http.Error(w, "Unexpected login request, please try again.", http.StatusBadRequest)
+			return
+		}
+		res := result{code: q.Get("code")}
+		if e := q.Get("error"); e != "" {
+			res.err = fmt.Errorf("oauth: Could not login - %s %s", e, q.Get("error_description"))
+		} else if res.code == "" {
+			res.err = errors.New("oauth: Login did not return an authorization code")
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if res.err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "<html><body><h3>Login failed.</h3>Please check the terminal for details.</body></html>")
+		} else {
+			fmt.Fprint(w, "<html><body><h3>You are logged into IVCAP.</h3>You can close this window now.</body></html>")
+		}
+		select {
+		case resultC <- res:
+		default:
+		}
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(listener) }()
+	defer func() { _ = srv.Close() }()
+
+	select {
+	case res := <-resultC:
+		return res.code, res.err
+	case <-ctxt.Done():
+		return "", fmt.Errorf("the login was not completed in time - %w", ctxt.Err())
+	}
+}
+
+// Returns a PKCE code verifier and its (S256) challenge.
+func newPKCE() (verifier, challenge string) {
+	verifier = randomString(32)
+	h := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(h[:])
+}
+
+func randomString(size int) string {
+	b := make([]byte, size)
+	_, _ = rand.Read(b) // never returns an error
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Opens `u` in the user's browser. Returns an error wrapping `errNoBrowser`
+// if there is no browser to open.
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		if b := os.Getenv("BROWSER"); b != "" {
+			cmd = exec.Command(b, u) // #nosec G204
+		} else if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("%w - no display", errNoBrowser)
+		} else {
+			cmd = exec.Command("xdg-open", u)
+		}
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%w - %v", errNoBrowser, err)
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
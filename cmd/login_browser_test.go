@@ -0,0 +1,63 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewPKCE(t *testing.T) {
+	verifier, challenge := newPKCE()
+	if len(verifier) < 43 {
+		t.Errorf("verifier '%s' is too short", verifier)
+	}
+	h := sha256.Sum256([]byte(verifier))
+	if challenge != base64.RawURLEncoding.EncodeToString(h[:]) {
+		t.Errorf("challenge doesn't match verifier")
+	}
+}
+
+func TestWaitForAuthCode(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	callback := "http://" + listener.Addr().String() + BROWSER_LOGIN_CALLBACK_PATH
+	go func() {
+		// a stale request is rejected, and doesn't end the login
+		if resp, err := http.Get(callback + "?state=old&code=c1"); err == nil {
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("expected stale request to be rejected, got %d", resp.StatusCode)
+			}
+			_ = resp.Body.Close()
+		}
+		if resp, err := http.Get(callback + "?state=s1&code=c2"); err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+
+	ctxt, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	code, err := waitForAuthCode(ctxt, listener, "s1")
+	if err != nil || code != "c2" {
+		t.Fatalf("expected code 'c2', got '%s' (%v)", code, err)
+	}
+}
@@ -82,12 +82,12 @@ Before answering any task:
 					server.WithSSEEndpoint("/mcp"),
 				)
 				if err := hs.Start(fmt.Sprintf("localhost:%d", mcpPort)); err != nil {
-					cobra.CheckErr(fmt.Sprintf("Server error: %v", err))
+					checkErr(fmt.Errorf("server error: %w", err))
 				}
 			} else {
 				logger.Info("MCP Proxy Server starting in STDIO mode...")
 				if err := server.ServeStdio(s); err != nil {
-					cobra.CheckErr(fmt.Sprintf("Server error: %v", err))
+					checkErr(fmt.Errorf("server error: %w", err))
 				}
 			}
 			return nil
@@ -102,24 +102,38 @@ func createMCPAdapter(timeoutSec int) (*a.Adapter, error) {
 	}
 
 	accessToken := ""
+	var opts []a.Option
 	if accessTokenF != "" {
 		accessToken = accessTokenF
 	} else if envToken := os.Getenv(ACCESS_TOKEN_ENV); envToken != "" {
 		accessToken = envToken
+	} else if err = loadCredentials(ctxt); err != nil {
+		return nil, err
+	} else if ctxt.RefreshToken != "" || ctxt.ClientID != "" {
+		// The token source will refresh the cached token whenever needed, so the
+		// server survives the expiry of the token it started with.
+		accessToken = ctxt.AccessToken
+		opts = append(opts, a.WithTokenSource(contextTokenSource(ctxt)))
 	} else if ctxt.AccessToken != "" && time.Now().Before(ctxt.AccessTokenExpiry) {
 		// Only use cached context token if it hasn't expired.
 		accessToken = ctxt.AccessToken
 	}
-	if accessToken == "" {
+	if accessToken == "" && len(opts) == 0 {
 		return nil, mcppkg.ErrLoginRequired
 	}
 
+	transport, err := a.NewTransport(transportConfig(ctxt))
+	if err != nil {
+		return nil, fmt.Errorf("context '%s': %w", ctxt.Name, err)
+	}
+	opts = append(opts, a.WithRetryPolicy(retryPolicy(ctxt)), a.WithTransport(transport))
+
 	url := ctxt.URL
 	var headers *map[string]string
 	if ctxt.Host != "" {
 		headers = &(map[string]string{"Host": ctxt.Host})
 	}
-	adp, err := NewAdapter(url, accessToken, timeoutSec, headers)
+	adp, err := NewAdapter(url, accessToken, timeoutSec, headers, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -17,6 +17,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 
@@ -37,7 +38,7 @@ func init() {
 	addFileFlag(nextflowCreateCmd, "Path to local tar/tgz containing ivcap.yaml or ivcap-tool.yaml")
 	nextflowCreateCmd.Flags().StringVar(&nextflowServiceID, "service-id", "", "Service ID/URN to use for generated service description")
 	nextflowCreateCmd.Flags().StringVar(&nextflowCreateFormat, "format", "", "Output format for nextflow create result [json, yaml]")
-	cobra.CheckErr(nextflowCreateCmd.MarkFlagRequired("service-id"))
+	checkErr(nextflowCreateCmd.MarkFlagRequired("service-id"))
 
 	addFileFlag(nextflowUpdateCmd, "Path to local tar/tgz containing ivcap.yaml or ivcap-tool.yaml")
 	nextflowUpdateCmd.Flags().StringVar(&nextflowCreateFormat, "format", "", "Output format for nextflow update result [json, yaml]")
@@ -67,7 +68,7 @@ var (
 		Short: "Create a Nextflow service definition from a local archive",
 		Args:  cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runNextflowCreateOrUpdate(context.Background(), nextflowServiceID)
+			return runNextflowCreateOrUpdate(RootContext(), nextflowServiceID)
 		},
 	}
 
@@ -77,7 +78,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			serviceID := GetHistory(args[0])
-			return runNextflowCreateOrUpdate(context.Background(), serviceID)
+			return runNextflowCreateOrUpdate(RootContext(), serviceID)
 		},
 	}
 
@@ -87,21 +88,21 @@ var (
 		Long:  "Alias for 'ivcap job create' (creates a job for a given service ID with provided input).",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			serviceID := GetHistory(args[0])
 			if fileName == "" && nextflowRunAspectURN == "" {
-				cobra.CheckErr("Missing parameter file '-f job-file|-' or '-a aspectURN'")
+				checkErr("Missing parameter file '-f job-file|-' or '-a aspectURN'")
 			}
 			var pyld a.Payload
 			if fileName != "" {
 				if pyld, err = payloadFromFile(fileName, inputFormat); err != nil {
-					cobra.CheckErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
+					checkErr(fmt.Sprintf("While reading job file '%s' - %v", fileName, err))
 				}
 			}
 			if nextflowRunAspectURN != "" {
 				j := fmt.Sprintf(CREATE_FROM_ASPECT, nextflowRunAspectURN, serviceID)
 				if pyld, err = a.LoadPayloadFromBytes([]byte(j), false); err != nil {
-					cobra.CheckErr(fmt.Sprintf("While reading job aspect '%s' - %v", nextflowRunAspectURN, err))
+					checkErr(fmt.Sprintf("While reading job aspect '%s' - %v", nextflowRunAspectURN, err))
 				}
 			}
 			res, jobCreate, err := sdk.CreateServiceJobRaw(ctxt, serviceID, pyld, 0, CreateAdapter(true), logger)
@@ -120,7 +121,7 @@ var (
 			}
 			jobID, ok := reply["job-id"].(string)
 			if !ok {
-				cobra.CheckErr("Cannot find job ID in response")
+				checkErr("Cannot find job ID in response")
 			}
 			return readDisplayJob(ctxt, jobID)
 		},
@@ -129,13 +130,13 @@ var (
 
 func runNextflowCreateOrUpdate(ctxt context.Context, serviceID string) error {
 	if serviceID == "" {
-		cobra.CheckErr("Missing service id")
+		return &UsageError{errors.New("Missing service id")}
 	}
 	if fileName == "" {
-		cobra.CheckErr("Missing archive file '-f package.tar|package.tgz'")
+		return &UsageError{errors.New("Missing archive file '-f package.tar|package.tgz'")}
 	}
 	if fileName == "-" {
-		cobra.CheckErr("Archive file must be a local path; stdin ('-') is not supported")
+		return &UsageError{errors.New("Archive file must be a local path; stdin ('-') is not supported")}
 	}
 
 	tool, _, err := nf.LoadToolHeaderFromArchivePath(fileName)
@@ -149,13 +150,13 @@ func runNextflowCreateOrUpdate(ctxt context.Context, serviceID string) error {
 	adapter := CreateAdapter(true)
 	artifactID, err := nf.UploadArchiveAsArtifact(ctxt, tool.Name, fileName, DEF_CHUNK_SIZE, adapter, silent, logger)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("while uploading archive as artifact: %v", err))
+		checkErr(fmt.Errorf("while uploading archive as artifact: %w", err))
 	}
 
 	svc := nf.BuildServiceDescription(tool, serviceID, artifactID)
 	aspectID, err := nf.UpsertServiceDescriptionAspect(ctxt, serviceID, svc, adapter, logger)
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("while publishing service description aspect: %v", err))
+		checkErr(fmt.Errorf("while publishing service description aspect: %w", err))
 	}
 
 	res := &nf.CreateOutput{
@@ -28,7 +28,7 @@ import (
 	nf "github.com/ivcap-works/ivcap-cli/pkg/nextflow"
 )
 
-const testToolYAML = `{"$schema": "urn:sd-core:schema.ai-tool.1"}
+const testToolYAML = `$schema: urn:sd-core:schema.ai-tool.1
 id: urn:sd-core:nextflow:simple-rnaseq-pipeline
 name: simple-rnaseq-pipeline
 service-id: urn:ivcap:service:a98b81a8-9279-509f-9c0e-40d39e83058a
@@ -50,7 +50,7 @@ fn-schema:
           type: string
 `
 
-const testSimpleToolYAML = `{"$schema": "urn:sd-core:schema.ai-tool.1"}
+const testSimpleToolYAML = `$schema: urn:sd-core:schema.ai-tool.1
 id: urn:sd-core:nextflow:simple-rnaseq-pipeline
 name: simple-rnaseq-pipeline
 service-id: urn:ivcap:service:a98b81a8-9279-509f-9c0e-40d39e83058a
@@ -75,21 +75,15 @@ var (
 
 		RunE: func(cmd *cobra.Command, args []string) error {
 			req := createListRequest()
-			switch outputFormat {
-			case "json", "yaml":
-				if res, err := sdk.ListOrdersRaw(context.Background(), req, CreateAdapter(true), logger); err == nil {
-					return a.ReplyPrinter(res, outputFormat == "yaml")
-				} else {
-					return err
-				}
-			default:
-				if list, err := sdk.ListOrders(context.Background(), req, CreateAdapter(true), logger); err == nil {
-					printOrdersTable(list, false)
-				} else {
-					return err
-				}
-			}
-			return nil
+			adapter := CreateAdapter(true)
+			return printList(
+				func(ctxt context.Context) (a.Payload, error) {
+					return sdk.ListOrdersRaw(ctxt, req, adapter, logger)
+				},
+				adapter,
+				func(list *api.ListResponseBody) *[]*api.OrderListItemResponseBody { return &list.Items },
+				func(list *api.ListResponseBody, next *string) { printOrdersTable(list, next, isWideOutput()) },
+			)
 		},
 	}
 
@@ -103,18 +97,18 @@ var (
 			req := &sdk.ReadOrderRequest{Id: recordID}
 			adapter := CreateAdapter(true)
 
-			switch outputFormat {
-			case "json", "yaml":
-				if res, err := sdk.ReadOrderRaw(context.Background(), req, adapter, logger); err == nil {
-					return a.ReplyPrinter(res, outputFormat == "yaml")
+			switch {
+			case !isTableOutput():
+				if res, err := sdk.ReadOrderRaw(RootContext(), req, adapter, logger); err == nil {
+					return printReply(res)
 				} else {
 					return err
 				}
 			default:
-				if order, err := sdk.ReadOrder(context.Background(), req, adapter, logger); err == nil {
+				if order, err := sdk.ReadOrder(RootContext(), req, adapter, logger); err == nil {
 					selector := sdk.AspectSelector{Entity: recordID}
-					if meta, _, err := sdk.ListAspect(context.Background(), selector, adapter, logger); err == nil {
-						printOrder(order, meta, false)
+					if meta, _, err := sdk.ListAspect(RootContext(), selector, adapter, logger); err == nil {
+						printOrder(order, meta, isWideOutput())
 					} else {
 						return err
 					}
@@ -142,13 +136,13 @@ An example:
 `,
 		Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			serviceId := GetHistory(args[0])
 
 			var paramSet = map[string]bool{}
 			if !skipParameterCheck {
 				// fetch defined parameters to do some early verification
-				service, err := sdk.ReadService(ctxt, &sdk.ReadServiceRequest{Id: serviceId}, CreateAdapter(true), logger)
+				service, err := sdk.ReadService(ctxt, &sdk.ReadServiceRequest{Id: serviceId}, CreateCachedAdapter(true), logger)
 				if err != nil {
 					return err
 				}
@@ -160,13 +154,13 @@ An example:
 			for i, ps := range args[1:] {
 				pa := strings.SplitN(ps, "=", 2)
 				if len(pa) != 2 {
-					cobra.CheckErr(fmt.Sprintf("cannot parse parameter argument '%s'", ps))
+					checkErr(fmt.Sprintf("cannot parse parameter argument '%s'", ps))
 				}
 				name := pa[0]
 				value := pa[1]
 				if !skipParameterCheck {
 					if _, ok := paramSet[name]; !ok {
-						cobra.CheckErr(fmt.Sprintf("parameter '%s' is not defined by the requested service", name))
+						checkErr(fmt.Sprintf("parameter '%s' is not defined by the requested service", name))
 					}
 				}
 				params[i] = &api.ParameterT{Name: &name, Value: &value}
@@ -179,10 +173,10 @@ An example:
 			if name != "" {
 				req.Name = &name
 			}
-			switch outputFormat {
-			case "json", "yaml":
+			switch {
+			case !isTableOutput():
 				if res, err := sdk.CreateOrderRaw(ctxt, req, CreateAdapter(true), logger); err == nil {
-					return a.ReplyPrinter(res, outputFormat == "yaml")
+					return printReply(res)
 				} else {
 					return err
 				}
@@ -224,7 +218,7 @@ An example:
 			}
 
 			adapter := CreateAdapter(true)
-			return sdk.DownloadOrderLog(context.Background(), req, adapter, logger)
+			return sdk.DownloadOrderLog(RootContext(), req, adapter, logger)
 		},
 	}
 
@@ -236,22 +230,25 @@ An example:
 			recordID := GetHistory(args[0])
 
 			adapter := CreateAdapter(true)
-			ctx := context.Background()
+			ctx := RootContext()
 			res, err := sdk.TopOrderRaw(ctx, recordID, adapter, logger)
 			if err != nil {
 				return err
 			}
-			switch outputFormat {
-			case "json":
-				return a.ReplyPrinter(res, outputFormat == "yaml")
-			default:
-				return a.ReplyPrinter(res, true)
+			if !isTableOutput() {
+				return printReply(res)
+			}
+			var top api.TopResponseBody
+			if err = res.AsType(&top); err != nil {
+				return fmt.Errorf("cannot parse reply - %w", err)
 			}
+			printOrderTop(top)
+			return nil
 		},
 	}
 )
 
-func printOrdersTable(list *api.ListResponseBody, wide bool) {
+func printOrdersTable(list *api.ListResponseBody, next *string, wide bool) {
 	srv2name := make(map[string]string)
 	rows := make([]table.Row, len(list.Items))
 	for i, o := range list.Items {
@@ -265,12 +262,35 @@ func printOrdersTable(list *api.ListResponseBody, wide bool) {
 		}
 		rows[i] = table.Row{MakeHistory(o.ID), safeString(o.Name), safeString(o.Status),
 			safeDate(o.OrderedAt, true), serviceName}
+		if wide {
+			rows[i] = append(rows[i], safeDate(o.StartedAt, false), safeDate(o.FinishedAt, false),
+				safeString(o.Account), safeString(o.ID))
+		}
 	}
-	rows = addNextPageRow(findNextOrderPage(list.Links), rows)
+	rows = addNextPageRow(next, rows)
 
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"ID", "Name", "Status", "Order At", "Service ID"})
+	header := table.Row{"ID", "Name", "Status", "Order At", "Service ID"}
+	if wide {
+		header = append(header, "Started At", "Finished At", "Account ID", "URN")
+	}
+	t.AppendHeader(header)
+	t.AppendRows(rows)
+	t.Render()
+}
+
+func printOrderTop(top api.TopResponseBody) {
+	rows := make([]table.Row, 0, len(top))
+	for _, c := range top {
+		if c != nil {
+			rows = append(rows, table.Row{safeString(c.Container), safeString(c.CPU), safeString(c.Memory),
+				safeString(c.Storage), safeString(c.EphemeralStorage)})
+		}
+	}
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Container", "CPU", "Memory", "Storage", "Ephemeral Storage"})
 	t.AppendRows(rows)
 	t.Render()
 }
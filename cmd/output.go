@@ -0,0 +1,62 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	"github.com/ivcap-works/ivcap-cli/pkg/printer"
+)
+
+var (
+	outputQuery string
+
+	outPrinter *printer.Printer
+)
+
+// getPrinter returns the printer for '--output' and '--query'. Invalid
+// values are reported as usage errors.
+func getPrinter() *printer.Printer {
+	if outPrinter == nil {
+		p, err := printer.New(outputFormat, outputQuery)
+		if err != nil {
+			checkErr(&UsageError{err})
+		}
+		outPrinter = p
+	}
+	return outPrinter
+}
+
+// isTableOutput returns true if the command should print a human readable
+// view instead of the reply itself
+func isTableOutput() bool {
+	return getPrinter().IsTable()
+}
+
+// isWideOutput returns true if tables should include all columns
+func isWideOutput() bool {
+	return getPrinter().IsWide()
+}
+
+// printReply prints an API reply in the selected output format
+func printReply(pyld a.Payload) error {
+	return getPrinter().Print(os.Stdout, pyld.AsBytes())
+}
+
+// printValue prints any JSON serialisable value in the selected output format
+func printValue(v any) error {
+	return getPrinter().Print(os.Stdout, v)
+}
@@ -15,7 +15,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 
 	sdk "github.com/ivcap-works/ivcap-cli/pkg"
@@ -49,7 +48,7 @@ var (
 		Long:    `List the service packages by image or image:tag under current account, image can have other account-id as prefix, if you have the permission to read.`,
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			var tag string
 			if len(args) > 0 {
 				tag = args[0]
@@ -74,7 +73,7 @@ var (
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			srcPackageTag := args[0]
-			_, err = sdk.PushPackage(context.Background(), srcPackageTag, forcePush, localImage, *CreateAdapter(true), logger)
+			_, err = sdk.PushPackage(RootContext(), srcPackageTag, forcePush, localImage, *CreateAdapter(true), logger)
 			if err != nil {
 				return err
 			}
@@ -88,7 +87,7 @@ var (
 		Long:  `Pull the service package by tag, from the ivcap service repository`,
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			tag := args[0]
 			err = sdk.PullPackage(ctxt, tag, *CreateAdapter(true), logger)
 			if err != nil {
@@ -105,7 +104,7 @@ var (
 		Long:    `Remove the service package by tag, from the ivcap service repository`,
 		Args:    cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			tag := args[0]
 			err = sdk.RemovePackage(ctxt, tag, CreateAdapter(true), logger)
 			if err != nil {
@@ -0,0 +1,169 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const PROJECT_FILE_NAME = ".ivcap.yaml"
+
+// ProjectConfig is read from the nearest '.ivcap.yaml' in the working
+// directory or any of its parents, and provides defaults for flags which
+// haven't been set on the command line.
+type ProjectConfig struct {
+	Context    string `yaml:"context,omitempty"`    // '--context' of every command
+	Output     string `yaml:"output,omitempty"`     // '--output' of every command
+	Policy     string `yaml:"policy,omitempty"`     // '--policy' of commands which have it
+	Collection string `yaml:"collection,omitempty"` // '--collection' of commands which have it
+	// Flag defaults for specific commands, indexed by the command path
+	// without 'ivcap', e.g. 'job list' or 'artifact'. Only the command's own
+	// flags can be set this way.
+	Defaults map[string]map[string]any `yaml:"defaults,omitempty"`
+}
+
+var (
+	projectConfig     *ProjectConfig
+	projectConfigFile string
+	projectConfigRead bool
+
+	// flags of the current command which were set from the project file
+	projectFlags = map[string]bool{}
+)
+
+// Returns the project file applying to the working directory and its path,
+// or nil if there is none.
+func getProjectConfig() (*ProjectConfig, string) {
+	if projectConfigRead {
+		return projectConfig, projectConfigFile
+	}
+	projectConfigRead = true
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil, ""
+	}
+	path := findProjectFile(dir)
+	if path == "" {
+		return nil, ""
+	}
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		checkErr(fmt.Sprintf("cannot read project file '%s' - %v", path, err))
+		return nil, ""
+	}
+	var pc ProjectConfig
+	if err = yaml.UnmarshalStrict(data, &pc); err != nil {
+		checkErr(fmt.Sprintf("cannot parse project file '%s' - %v", path, err))
+		return nil, ""
+	}
+	projectConfig, projectConfigFile = &pc, path
+	return projectConfig, projectConfigFile
+}
+
+// Returns the path of the nearest project file in `dir` or any of its
+// parents, or "" if there is none.
+func findProjectFile(dir string) string {
+	for {
+		path := filepath.Join(dir, PROJECT_FILE_NAME)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Returns the flag defaults the project file `pc` provides for `cmd`.
+// Defaults for a more specific command path override those of its parents.
+func (pc *ProjectConfig) flagDefaults(cmd *cobra.Command) map[string]string {
+	defaults := map[string]string{}
+	set := func(flag, value string) {
+		if value != "" {
+			defaults[flag] = value
+		}
+	}
+	set("context", pc.Context)
+	set("output", pc.Output)
+	set("policy", pc.Policy)
+	set("collection", pc.Collection)
+
+	path := strings.Fields(strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()))
+	for i := 1; i <= len(path); i++ {
+		for flag, value := range pc.Defaults[strings.Join(path[:i], " ")] {
+			switch v := value.(type) {
+			case []any:
+				s := make([]string, len(v))
+				for j, e := range v {
+					s[j] = fmt.Sprint(e)
+				}
+				defaults[flag] = strings.Join(s, ",")
+			default:
+				defaults[flag] = fmt.Sprint(v)
+			}
+		}
+	}
+	return defaults
+}
+
+// Sets the flags of `cmd` which haven't been provided on the command line
+// to the defaults of the project file, if there is one.
+func applyProjectConfig(cmd *cobra.Command) {
+	pc, path := getProjectConfig()
+	if pc == nil {
+		return
+	}
+	local := cmd.LocalNonPersistentFlags()
+	defaults := pc.flagDefaults(cmd)
+	flags := make([]string, 0, len(defaults))
+	for flag := range defaults {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	for _, flag := range flags {
+		var f *pflag.Flag
+		switch flag {
+		case "context", "output":
+			f = cmd.Flags().Lookup(flag)
+		default:
+			// only the command's own flags, not global ones like '--access-token'
+			if f = local.Lookup(flag); f == nil && flag != "policy" && flag != "collection" {
+				fmt.Fprintf(os.Stderr, "WARNING: project file '%s' sets unknown flag '--%s' for '%s'\n",
+					path, flag, cmd.CommandPath())
+			}
+		}
+		if f == nil || f.Changed {
+			continue
+		}
+		if err := cmd.Flags().Set(flag, defaults[flag]); err != nil {
+			checkErr(&UsageError{fmt.Errorf("project file '%s': invalid value for '--%s' - %w", path, flag, err)})
+		}
+		projectFlags[flag] = true
+	}
+}
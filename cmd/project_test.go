@@ -0,0 +1,96 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFindProjectFile(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	if p := findProjectFile(sub); p != "" {
+		t.Fatalf("expected no project file, got '%s'", p)
+	}
+	path := filepath.Join(root, "a", PROJECT_FILE_NAME)
+	if err := os.WriteFile(path, []byte("context: dev\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if p := findProjectFile(sub); p != path {
+		t.Fatalf("expected '%s', got '%s'", path, p)
+	}
+}
+
+func TestProjectConfig_FlagDefaults(t *testing.T) {
+	root := &cobra.Command{Use: "ivcap"}
+	parent := &cobra.Command{Use: "job"}
+	list := &cobra.Command{Use: "list"}
+	root.AddCommand(parent)
+	parent.AddCommand(list)
+
+	pc := &ProjectConfig{
+		Context: "dev",
+		Policy:  "p1",
+		Defaults: map[string]map[string]any{
+			"job":      {"limit": 10, "filter": "a"},
+			"job list": {"limit": 5, "search": []any{"x", "y"}},
+		},
+	}
+	got := pc.flagDefaults(list)
+	expected := map[string]string{"context": "dev", "policy": "p1", "limit": "5", "filter": "a", "search": "x,y"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("expected '%s' for '%s', got '%s'", v, k, got[k])
+		}
+	}
+}
+
+func TestExplainCommandFlags_EnvOverridesProject(t *testing.T) {
+	root := &cobra.Command{Use: "ivcap"}
+	parent := &cobra.Command{Use: "job"}
+	list := &cobra.Command{Use: "list"}
+	root.AddCommand(parent)
+	parent.AddCommand(list)
+	list.Flags().Int("limit", 10, "")
+	list.Flags().String("filter", "", "")
+	list.Flags().String("order-by", "", "")
+
+	projectConfig, projectConfigFile, projectConfigRead = &ProjectConfig{
+		Defaults: map[string]map[string]any{"job list": {"limit": 5, "filter": "a"}},
+	}, "/p/"+PROJECT_FILE_NAME, true
+	t.Cleanup(func() { projectConfig, projectConfigFile, projectConfigRead = nil, "", false })
+	t.Setenv("IVCAP_JOB_LIST_LIMIT", "7")
+	t.Setenv("IVCAP_JOB_ORDER_BY", "name")
+
+	expected := []settingSource{
+		{Name: "--filter", Value: "a", Source: SOURCE_PROJECT},
+		{Name: "--limit", Value: "7", Source: SOURCE_ENV},
+		{Name: "--order-by", Value: "name", Source: SOURCE_ENV},
+	}
+	if got := explainCommandFlags(list); !slices.Equal(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
@@ -187,17 +187,14 @@ Examples:
 
 func runListQueueCmd(cmd *cobra.Command, args []string) error {
 	req := createListRequest()
-
-	err := printResponseBody(
-		func() (a.Payload, error) {
-			return sdk.ListQueuesRaw(context.Background(), req, CreateAdapter(true), logger)
-		},
-		func() (*api.ListResponseBody, error) {
-			return sdk.ListQueues(context.Background(), req, CreateAdapter(true), logger)
-		},
-		func(res *api.ListResponseBody) {
-			printListResponse(res)
+	adapter := CreateAdapter(true)
+	err := printList(
+		func(ctxt context.Context) (a.Payload, error) {
+			return sdk.ListQueuesRaw(ctxt, req, adapter, logger)
 		},
+		adapter,
+		func(list *api.ListResponseBody) *[]*api.QueueListItemResponseBody { return &list.Items },
+		printListResponse,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to list queues: %w", err)
@@ -219,10 +216,10 @@ func runReadQueueCmd(cmd *cobra.Command, args []string) error {
 
 	err := printResponseBody(
 		func() (a.Payload, error) {
-			return sdk.ReadQueueRaw(context.Background(), req, CreateAdapter(true), logger)
+			return sdk.ReadQueueRaw(RootContext(), req, CreateAdapter(true), logger)
 		},
 		func() (*api.ReadResponseBody, error) {
-			return sdk.ReadQueue(context.Background(), req, CreateAdapter(true), logger)
+			return sdk.ReadQueue(RootContext(), req, CreateAdapter(true), logger)
 		},
 		func(res *api.ReadResponseBody) {
 			printReadResponse(res)
@@ -260,10 +257,10 @@ func runCreateQueueCmd(cmd *cobra.Command, args []string) error {
 
 	err := printResponseBody(
 		func() (a.Payload, error) {
-			return sdk.CreateQueueRaw(context.Background(), req, CreateAdapter(true), logger)
+			return sdk.CreateQueueRaw(RootContext(), req, CreateAdapter(true), logger)
 		},
 		func() (*api.CreateResponseBody, error) {
-			return sdk.CreateQueue(context.Background(), req, CreateAdapter(true), logger)
+			return sdk.CreateQueue(RootContext(), req, CreateAdapter(true), logger)
 		},
 		func(res *api.CreateResponseBody) {
 			printCreateResponse(res)
@@ -314,17 +311,17 @@ func runEnqueueCmd(cmd *cobra.Command, args []string) error {
 
 	payload, err := payloadFromFile(filepath, "json")
 	if err != nil {
-		cobra.CheckErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
+		checkErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
 	}
 
 	message := string(payload.AsBytes())
 
 	err = printResponseBody(
 		func() (a.Payload, error) {
-			return sdk.EnqueueRaw(context.Background(), req, schema, message, CreateAdapter(true), logger)
+			return sdk.EnqueueRaw(RootContext(), req, schema, message, CreateAdapter(true), logger)
 		},
 		func() (*api.EnqueueResponseBody, error) {
-			return sdk.Enqueue(context.Background(), req, schema, message, CreateAdapter(true), logger)
+			return sdk.Enqueue(RootContext(), req, schema, message, CreateAdapter(true), logger)
 		},
 		func(res *api.EnqueueResponseBody) {
 			fmt.Printf("Message enqueued to queue %s with ID %s\n", queueID, *res.ID)
@@ -366,7 +363,7 @@ func runDequeueCmd(cmd *cobra.Command, args []string) error {
 		limit = 1 // Default value if the flag is not set or invalid
 	}
 
-	payload, err := sdk.DequeueRaw(context.Background(), req, limit, CreateAdapter(true), logger)
+	payload, err := sdk.DequeueRaw(RootContext(), req, limit, CreateAdapter(true), logger)
 	if err != nil {
 		return fmt.Errorf("failed to dequeue messages: %w", err)
 	}
@@ -390,14 +387,14 @@ func runDeleteQueueCmd(cmd *cobra.Command, args []string) error {
 	recordID := GetHistory(args[0])
 	req := &sdk.ReadQueueRequest{Id: GetHistory(recordID)}
 
-	res, err := sdk.DeleteQueueRaw(context.Background(), req, CreateAdapter(true), logger)
+	res, err := sdk.DeleteQueueRaw(RootContext(), req, CreateAdapter(true), logger)
 	if err != nil {
 		return fmt.Errorf("failed to delete queue: %w", err)
 	}
 
-	switch outputFormat {
-	case "json", "yaml":
-		return a.ReplyPrinter(res, outputFormat == "yaml")
+	switch {
+	case !isTableOutput():
+		return printReply(res)
 	default:
 		fmt.Printf("Queue %s deleted\n", recordID)
 	}
@@ -409,10 +406,10 @@ func printResponseBody[ResponseType any](
 	requestFunc func() (*ResponseType, error),
 	printFunc func(*ResponseType),
 ) error {
-	switch outputFormat {
-	case "json", "yaml":
+	switch {
+	case !isTableOutput():
 		if res, err := rawRequestFunc(); err == nil {
-			return a.ReplyPrinter(res, outputFormat == "yaml")
+			return printReply(res)
 		} else {
 			return fmt.Errorf("failed to print response: %w", err)
 		}
@@ -464,7 +461,7 @@ func safeOpen(filePath string) (*os.File, error) {
 	return os.Create(cleanPath)
 }
 
-func printListResponse(list *api.ListResponseBody) {
+func printListResponse(list *api.ListResponseBody, next *string) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
 	t.AppendHeader(table.Row{"ID", "Name", "Account"})
@@ -474,23 +471,11 @@ func printListResponse(list *api.ListResponseBody) {
 		rows[i] = table.Row{MakeHistory(o.ID), safeTruncString(o.Name), safeString(o.Account)}
 	}
 
-	rows = addNextPageRow(findNextQueuePage(list.Links), rows)
+	rows = addNextPageRow(next, rows)
 	t.AppendRows(rows)
 	t.Render()
 }
 
-func findNextQueuePage(links []*api.LinkTResponseBody) *string {
-	if links == nil {
-		return nil
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return l.Href
-		}
-	}
-	return nil
-}
-
 func printReadResponse(queue *api.ReadResponseBody) {
 	tw := table.NewWriter()
 	tw.SetStyle(table.StyleLight)
@@ -0,0 +1,88 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ivcap-works/ivcap-cli/pkg/ivcaptest"
+	log "go.uber.org/zap"
+)
+
+// Resets the flags set by the tests below, as cobra doesn't between runs
+func resetCommandFlags() {
+	recordFile, replayFile, cassette = "", "", nil
+	accessToken, contextName, outputFormat = "", "", ""
+	entityURN, schemaPrefix = "", ""
+}
+
+// Runs the command tree with `args` and returns what it printed to stdout.
+func runCommand(t *testing.T, args ...string) string {
+	t.Helper()
+	resetCommandFlags()
+	t.Cleanup(resetCommandFlags)
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	rootCmd.SetArgs(args)
+	err = rootCmd.ExecuteContext(context.Background())
+	os.Stdout = stdout
+	_ = w.Close()
+	var out bytes.Buffer
+	_, _ = io.Copy(&out, r)
+	if err != nil {
+		t.Fatalf("'%s' failed - %v", strings.Join(args, " "), err)
+	}
+	return out.String()
+}
+
+func TestReplay_RunsCommandOffline(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(NO_UPDATE_CHECK_ENV, "true")
+	if logger == nil {
+		logger = log.NewNop()
+	}
+	srv := ivcaptest.NewServer(ivcaptest.WithAccessToken("secret-token"))
+	srv.AddAspect(ivcaptest.Aspect{Entity: "urn:ivcap:test:1", Schema: "urn:example:schema.1", Content: map[string]any{"a": 1}})
+	SetContext(&Context{Name: "fake", URL: srv.URL, AccessToken: "secret-token", AccessTokenExpiry: time.Now().Add(time.Hour)}, false)
+
+	cassetteFile := filepath.Join(t.TempDir(), "cassette.yaml")
+	query := []string{"--context", "fake", "datafabric", "query", "-s", "urn:example:", "-o", "json"}
+	recorded := runCommand(t, append([]string{"--record", cassetteFile}, query...)...)
+	if err := cassette.Save(); err != nil {
+		t.Fatal(err)
+	}
+	srv.Close()
+	if !strings.Contains(recorded, "urn:ivcap:test:1") {
+		t.Fatalf("expected aspect in output, got '%s'", recorded)
+	}
+	if data, _ := os.ReadFile(cassetteFile); bytes.Contains(data, []byte("secret-token")) {
+		t.Error("expected access token to be redacted in cassette")
+	}
+
+	if replayed := runCommand(t, append([]string{"--replay", cassetteFile}, query...)...); replayed != recorded {
+		t.Errorf("expected replay to print\n%s\ngot\n%s", recorded, replayed)
+	}
+}
@@ -16,22 +16,30 @@ package cmd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
-	"io/fs"
 	"net/http"
 	"net/url"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"strings"
+	"syscall"
 	"time"
 
 	humanize "github.com/dustin/go-humanize"
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
 
 	adpt "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	"github.com/ivcap-works/ivcap-cli/pkg/printer"
 
 	log "go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -42,8 +50,6 @@ const (
 	URN_PREFIX = "ivcap"
 )
 
-const RELEASE_CHECK_URL = "https://github.com/ivcap-works/ivcap-cli/releases/latest"
-
 // Max characters to limit name to
 const MAX_NAME_COL_LEN = 30
 
@@ -59,6 +65,13 @@ var (
 	debug               bool
 	agentContextFlag    bool
 	agentHelpFlag       bool
+	recordFile          string
+	replayFile          string
+	traceFile           string
+	otelEndpoint        string
+	otelFile            string
+	noCache             bool
+	retries             int
 )
 
 var logger *log.Logger
@@ -67,6 +80,8 @@ type Config struct {
 	Version       string    `yaml:"version"`
 	ActiveContext string    `yaml:"active-context"`
 	Contexts      []Context `yaml:"contexts"`
+
+	Updates *UpdateConfig `yaml:"updates,omitempty"` // see 'self-update'
 }
 
 type Context struct {
@@ -86,6 +101,31 @@ type Context struct {
 	AccessToken       string    `yaml:"access-token"`
 	AccessTokenExpiry time.Time `yaml:"access-token-expiry"`
 	RefreshToken      string    `yaml:"refresh-token"`
+	IDToken           string    `yaml:"id-token,omitempty"` // from the last login or refresh, see 'whoami'
+	// Set if logged in with the client credentials grant, which is repeated
+	// whenever the access token expires
+	ClientID         string `yaml:"client-id,omitempty"`
+	ClientSecretFile string `yaml:"client-secret-file,omitempty"`
+	// Where the above credentials are kept, see CREDENTIAL_STORES. Unless it's
+	// the config file itself, they are not written to it.
+	CredentialStore   string `yaml:"credential-store,omitempty"`
+	CredentialKeyFile string `yaml:"credential-key-file,omitempty"` // age identity for the 'file' store
+
+	// Retry Policy
+	MaxRetries      *int `yaml:"max-retries,omitempty"`
+	IdempotencyKeys bool `yaml:"idempotency-keys,omitempty"`
+
+	// History Tokens, see 'history'
+	HistoryRetention  string `yaml:"history-retention,omitempty"` // e.g. '48h'
+	HistoryMaxEntries int    `yaml:"history-max-entries,omitempty"`
+
+	// TLS and Proxy
+	CAFile             string `yaml:"ca-file,omitempty"`
+	ClientCert         string `yaml:"client-cert,omitempty"`
+	ClientKey          string `yaml:"client-key,omitempty"`
+	InsecureSkipVerify bool   `yaml:"insecure-skip-verify,omitempty"`
+	ProxyURL           string `yaml:"proxy-url,omitempty"`
+	NoProxy            string `yaml:"no-proxy,omitempty"`
 }
 
 type AppError struct {
@@ -116,11 +156,46 @@ const generalSupportGroupID = "general-support"
 func Execute(version string) {
 	rootCmd.Version = version
 	rootCmd.SilenceUsage = true
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	rootCmd.SilenceErrors = true // reported by 'reportError' below
+	ctxt, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// a second Ctrl-C should terminate immediately
+		<-ctxt.Done()
+		stop()
+	}()
+	rootCtxt = ctxt
+	err := rootCmd.ExecuteContext(ctxt)
+	finishCommand(err)
+	if err != nil {
+		reportError(err)
+		os.Exit(exitCode(err))
 	}
 	if err := saveHistory(); err != nil {
-		os.Exit(1)
+		reportError(err)
+		os.Exit(EXIT_ERROR)
+	}
+}
+
+var commandFinished bool
+
+// Ends the command's telemetry and saves the cassette and trace. It is
+// called once, either when `Execute` returns or by `checkErr` before
+// exiting, as failed commands are the ones worth diagnosing.
+func finishCommand(err error) {
+	if commandFinished {
+		return
+	}
+	commandFinished = true
+	endTelemetry(err)
+	if cassette != nil && !cassette.IsReplaying() {
+		if err := cassette.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: cannot save cassette '%s' - %v\n", recordFile, err)
+		}
+	}
+	if trace != nil {
+		if err := trace.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: cannot save trace '%s' - %v\n", traceFile, err)
+		}
 	}
 }
 
@@ -228,26 +303,51 @@ func init() {
 		defaultHelpFunc(cmd, args)
 	})
 
+	rootCmd.SetFlagErrorFunc(flagErrorFunc)
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		// global flags are already taken from the environment in 'initConfig'
+		applyEnvFlags(cmd, cmd.LocalNonPersistentFlags())
+		applyProjectConfig(cmd)
+		startCommandSpan(cmd)
+	}
+
 	rootCmd.PersistentFlags().StringVar(&contextName, "context", "", "Context (deployment) to use")
 	rootCmd.PersistentFlags().StringVar(&accessTokenF, "access-token", "",
 		fmt.Sprintf("Access token to use for authentication with API server [%s]", ACCESS_TOKEN_ENV))
 	rootCmd.PersistentFlags().IntVar(&timeout, "timeout", DEFAULT_SERVICE_TIMEOUT_IN_SECONDS, "Max. number of seconds to wait for completion")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", -1,
+		fmt.Sprintf("Max. number of retries for failed requests, 0 disables retries [context setting or %d]", adpt.DefaultMaxRetries))
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Set logging level to DEBUG")
-	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Set format for displaying output [json, yaml]")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "",
+		fmt.Sprintf("Set format for displaying output [%s]", strings.Join(printer.Formats, ", ")))
+	rootCmd.PersistentFlags().StringVar(&outputQuery, "query", "", "jq expression to filter the reply with before printing (e.g. '.items[].name')")
 	rootCmd.PersistentFlags().BoolVar(&silent, "silent", false, "Do not show any progress information")
 	rootCmd.PersistentFlags().BoolVar(&noHistory, "no-history", false, "Do not store history")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record all API interactions into this cassette file (credentials are redacted)")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "Replay API interactions from this cassette file instead of contacting the deployment")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "Write all HTTP traffic as HAR file, e.g. 'out.har' (credentials are redacted)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Do not use cached replies, and do not cache new ones")
+	rootCmd.PersistentFlags().StringVar(&otelEndpoint, "otel-endpoint", "",
+		"Export OpenTelemetry spans to this OTLP/HTTP collector, e.g. 'http://localhost:4318' [OTEL_EXPORTER_OTLP_ENDPOINT]")
+	rootCmd.PersistentFlags().StringVar(&otelFile, "otel-file", "", "Write OpenTelemetry spans as JSON to this file")
 	rootCmd.PersistentFlags().BoolVar(&agentContextFlag, "agent-context", false, "Print embedded agent context guidance and exit")
 	rootCmd.PersistentFlags().BoolVar(&agentHelpFlag, "agent-help", false, "Alias for --agent-context")
 	// Keep agent retrieval available, but avoid cluttering human `--help` flag output.
-	cobra.CheckErr(rootCmd.PersistentFlags().MarkHidden("agent-context"))
-	cobra.CheckErr(rootCmd.PersistentFlags().MarkHidden("agent-help"))
+	checkErr(rootCmd.PersistentFlags().MarkHidden("agent-context"))
+	checkErr(rootCmd.PersistentFlags().MarkHidden("agent-help"))
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	// before anything else, as the environment may set '--debug' or '--replay'
+	applyEnvFlags(rootCmd, rootCmd.PersistentFlags())
 	initLogger()
+	if replayFile != "" {
+		// we are supposed to be offline
+		return
+	}
 	// before proceeding, let's check for updates
-	checkForUpdates(rootCmd.Version)
+	checkForUpdates()
 }
 
 func initLogger() {
@@ -278,22 +378,40 @@ func CreateAdapter(requiresAuth bool, opts ...adpt.Option) (adapter *adpt.Adapte
 //   - If `requiresAuth` is set, each outgoing request includes an `Authorization` header
 //     with a 'Bearer' token provided either via the `--access-token` flag,
 //     the IVCAP_ACCESS_TOKEN environment, or the AccessToken from the ActiveContext.
+//     In the latter case, the token is refreshed whenever it is about to expire or
+//     is rejected by the server, and the new token is saved back to the ActiveContext.
 //   - If the `path` parameter for any of the adapter calls is NOT a fully fledged URL,
 //     the URL defined in ActiveContext is automatically prefixed.
 //   - If the ActiveContext defines a `Host` parameter, it is also added as a
 //     `Host` HTTP header.
 func CreateAdapterWithTimeout(requiresAuth bool, timeoutSec int, opts ...adpt.Option) (adapter *adpt.Adapter) {
-	ctxt := GetActiveContext() // will always return with a context
-
+	if replayFile != "" {
+		return createReplayAdapter(getCassette(""), timeoutSec, opts...)
+	}
 	if requiresAuth {
 		if accessToken == "" {
 			accessToken = getAccessToken(true)
 		}
 		if accessToken == "" {
-			cobra.CheckErr(
+			checkErr(
 				fmt.Sprintf("Adapter requires auth token. Set with '--access-token' or env '%s'", ACCESS_TOKEN_ENV))
 		}
 	}
+	ctxt := GetActiveContext() // will always return with a context
+
+	if requiresAuth && !accessTokenProvided {
+		if err := loadCredentials(ctxt); err != nil {
+			checkErr(err)
+		}
+		if ctxt.RefreshToken != "" || ctxt.ClientID != "" {
+			// keep long running commands going beyond the expiry of the current token
+			opts = append([]adpt.Option{adpt.WithTokenSource(contextTokenSource(ctxt))}, opts...)
+		}
+	}
+	opts = append([]adpt.Option{
+		adpt.WithRetryPolicy(retryPolicy(ctxt)),
+		adpt.WithTransport(httpTransport(ctxt)),
+	}, opts...)
 
 	url := ctxt.URL
 	var headers *map[string]string
@@ -304,11 +422,21 @@ func CreateAdapterWithTimeout(requiresAuth bool, timeoutSec int, opts ...adpt.Op
 
 	adp, err := NewAdapter(url, accessToken, timeoutSec, headers, opts...)
 	if adp == nil || err != nil {
-		cobra.CheckErr(fmt.Sprintf("cannot create adapter for '%s' - %s", url, err))
+		checkErr(fmt.Sprintf("cannot create adapter for '%s' - %s", url, err))
 	}
 	return adp
 }
 
+// Returns an adapter like `CreateAdapter`, which also caches the replies
+// to GET requests (see `getHTTPCache`). Only use it for reading resources
+// which rarely change, as it adds disk I/O to every request.
+func CreateCachedAdapter(requiresAuth bool, opts ...adpt.Option) (adapter *adpt.Adapter) {
+	if c := getHTTPCache(requiresAuth); c != nil {
+		opts = append(opts, adpt.WithCache(c))
+	}
+	return CreateAdapter(requiresAuth, opts...)
+}
+
 // ****** ADAPTER ****
 
 func NewAdapter(
@@ -324,17 +452,254 @@ func NewAdapter(
 		}),
 	}
 	options = append(options, opts...)
+	if c := getCassette(url); c != nil {
+		options = append(options, adpt.WithCassette(c))
+	}
+	if t := getTrace(); t != nil {
+		options = append(options, adpt.WithTrace(t))
+	}
+	options = append(options, adpt.WithTelemetry(getTelemetry()))
 	adapter := adpt.RestAdapter(options...)
 	return &adapter, nil
 }
 
+// Returns the retry policy configured for `ctxt`. The '--retries' flag
+// takes precedence over the context's 'max-retries' setting.
+func retryPolicy(ctxt *Context) adpt.RetryPolicy {
+	policy := adpt.DefaultRetryPolicy()
+	if ctxt.MaxRetries != nil {
+		policy.MaxRetries = *ctxt.MaxRetries
+	}
+	if retries >= 0 {
+		policy.MaxRetries = retries
+	}
+	policy.IdempotencyKeys = ctxt.IdempotencyKeys
+	return policy
+}
+
+// Returns the TLS and proxy settings of `ctxt`
+func transportConfig(ctxt *Context) *adpt.TransportConfig {
+	return &adpt.TransportConfig{
+		CAFile:             ctxt.CAFile,
+		ClientCert:         ctxt.ClientCert,
+		ClientKey:          ctxt.ClientKey,
+		InsecureSkipVerify: ctxt.InsecureSkipVerify,
+		ProxyURL:           ctxt.ProxyURL,
+		NoProxy:            ctxt.NoProxy,
+	}
+}
+
+// Returns the transport for all connections to the deployment of `ctxt`,
+// including event streams and JWKS downloads.
+func httpTransport(ctxt *Context) *http.Transport {
+	t, err := adpt.NewTransport(transportConfig(ctxt))
+	if err != nil {
+		checkErr(fmt.Errorf("context '%s': %w", ctxt.Name, err))
+	}
+	return t
+}
+
+// ****** CASSETTES ****
+
+var cassette *adpt.Cassette
+
+// Returns the cassette for this process if either '--record' or '--replay'
+// is set, otherwise nil. `url` is noted in newly recorded cassettes.
+func getCassette(url string) *adpt.Cassette {
+	if cassette != nil || (recordFile == "" && replayFile == "") {
+		return cassette
+	}
+	if recordFile != "" && replayFile != "" {
+		checkErr("Cannot use '--record' and '--replay' at the same time")
+	}
+	if replayFile != "" {
+		c, err := adpt.LoadCassette(replayFile)
+		if err != nil {
+			checkErr(fmt.Sprintf("cannot load cassette '%s' - %v", replayFile, err))
+		}
+		cassette = c
+	} else {
+		cassette = adpt.NewCassette(recordFile, url)
+	}
+	return cassette
+}
+
+// ****** HTTP CACHE ****
+
+const CACHE_DIR_NAME = "cache"
+
+// Returns the HTTP cache of the active context, or nil if '--no-cache' is
+// set. Cassettes need to capture all requests, so '--record' and
+// '--replay' disable the cache as well.
+//
+// Replies to authenticated requests depend on who is asking, so they are
+// kept apart for every subject of the access token. Tokens without a
+// subject are not cached at all.
+func getHTTPCache(requiresAuth bool) *adpt.Cache {
+	if noCache || recordFile != "" || replayFile != "" {
+		return nil
+	}
+	dir := getCacheDir(GetActiveContext().Name)
+	if requiresAuth {
+		if accessToken == "" {
+			accessToken = getAccessToken(true)
+		}
+		claims := jwt.RegisteredClaims{}
+		if _, err := decodeToken(accessToken, &claims); err != nil || claims.Subject == "" {
+			return nil
+		}
+		h := sha256.Sum256([]byte(claims.Subject))
+		dir = filepath.Join(dir, hex.EncodeToString(h[:8]))
+	}
+	return adpt.NewCache(dir)
+}
+
+// Returns the directory of the HTTP cache for context `name`, or for all
+// contexts if `name` is empty.
+func getCacheDir(name string) string {
+	dir := filepath.Join(GetConfigDir(true), CACHE_DIR_NAME)
+	if name != "" {
+		dir = filepath.Join(dir, name)
+	}
+	return dir
+}
+
+// ****** TRACES ****
+
+var trace *adpt.Trace
+
+// Returns the trace for this process if '--trace-file' is set, otherwise nil.
+func getTrace() *adpt.Trace {
+	if trace == nil && traceFile != "" {
+		trace = adpt.NewTrace(traceFile, "ivcap-cli", rootCmd.Version)
+	}
+	return trace
+}
+
+// Returns `rt` recording into the trace, if '--trace-file' is set, and
+// creating spans for each request. Use it for any HTTP client which isn't
+// created through an adapter.
+func tracedTransport(rt http.RoundTripper) http.RoundTripper {
+	if t := getTrace(); t != nil {
+		rt = t.RoundTripper(rt)
+	}
+	return getTelemetry().RoundTripper(rt)
+}
+
+// ****** TELEMETRY ****
+
+const TELEMETRY_SHUTDOWN_TIMEOUT = 5 * time.Second
+
+var (
+	telemetry   *adpt.Telemetry
+	commandSpan oteltrace.Span
+)
+
+// Returns the telemetry for this process, exporting spans as set by
+// '--otel-endpoint' and '--otel-file'.
+func getTelemetry() *adpt.Telemetry {
+	if telemetry == nil {
+		t, err := adpt.NewTelemetry(&adpt.TelemetryConfig{
+			OTLPEndpoint:   otelEndpoint,
+			File:           otelFile,
+			ServiceName:    "ivcap-cli",
+			ServiceVersion: rootCmd.Version,
+		})
+		if err != nil {
+			checkErr(fmt.Errorf("cannot set up telemetry - %w", err))
+		}
+		telemetry = t
+	}
+	return telemetry
+}
+
+// Starts the span covering the execution of `cmd`. All API calls made
+// with the RootContext() become part of it.
+func startCommandSpan(cmd *cobra.Command) {
+	tracer := getTelemetry().TracerProvider().Tracer("github.com/ivcap-works/ivcap-cli/cmd")
+	rootCtxt, commandSpan = tracer.Start(rootCtxt, cmd.CommandPath(),
+		oteltrace.WithAttributes(attribute.String("ivcap.context", contextName)))
+}
+
+// Ends the command's span, recording `err`, and exports all spans.
+func endTelemetry(err error) {
+	if telemetry == nil {
+		return
+	}
+	if commandSpan != nil {
+		if err != nil {
+			commandSpan.RecordError(err)
+			commandSpan.SetStatus(codes.Error, err.Error())
+		}
+		commandSpan.End()
+	}
+	// not the root context, which may have been cancelled already
+	ctxt, cancel := context.WithTimeout(context.Background(), TELEMETRY_SHUTDOWN_TIMEOUT)
+	defer cancel()
+	if err := telemetry.Shutdown(ctxt); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: cannot export telemetry - %v\n", err)
+	}
+}
+
+// Returns the ID of the trace `ctxt` is part of, or "" if there is none.
+func traceID(ctxt context.Context) string {
+	if sc := oteltrace.SpanContextFromContext(ctxt); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Returns an adapter serving all requests from cassette `c`. Neither a
+// configured context nor valid credentials are required.
+func createReplayAdapter(c *adpt.Cassette, timeoutSec int, opts ...adpt.Option) *adpt.Adapter {
+	url := c.URL
+	var headers *map[string]string
+	if ctxt, err := GetContextWithError(contextName, true); err == nil {
+		url = ctxt.URL
+		if ctxt.Host != "" {
+			headers = &(map[string]string{"Host": ctxt.Host})
+		}
+	}
+	token := accessTokenF
+	if token == "" {
+		token = adpt.Redacted
+	}
+	adp, err := NewAdapter(url, token, timeoutSec, headers, opts...)
+	if err != nil {
+		checkErr(fmt.Sprintf("cannot create adapter for '%s' - %s", url, err))
+	}
+	return adp
+}
+
+var rootCtxt = context.Background()
+
+// RootContext returns the context to use for all API calls. It is
+// cancelled when the process receives SIGINT (Ctrl-C) or SIGTERM.
+func RootContext() context.Context {
+	return rootCtxt
+}
+
+// NewTimeoutContext returns a RootContext which also expires after '--timeout' seconds.
 func NewTimeoutContext() (ctxt context.Context, cancel context.CancelFunc) {
 	to := time.Now().Add(time.Duration(timeout) * time.Second)
 	// #nosec G118 - cancel is returned to and managed by the caller
-	ctxt, cancel = context.WithDeadline(context.Background(), to)
+	ctxt, cancel = context.WithDeadline(RootContext(), to)
 	return
 }
 
+// sleep waits for `d` or until `ctxt` is done, in which case it returns
+// the context's error.
+func sleep(ctxt context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctxt.Done():
+		return ctxt.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
 func Logger() *log.Logger {
 	return logger
 }
@@ -402,52 +767,6 @@ func payloadFromFile(fileName string, inputFormat string) (pyld adpt.Payload, er
 	return
 }
 
-// ***** CHECK FOR NEWER VERSIONS
-
-func checkForUpdates(currentVersion string) {
-	path := makeConfigFilePath(VERSION_CHECK_FILE_NAME)
-	if data, err := os.ReadFile(filepath.Clean(path)); err == nil {
-		if lastCheck, err := time.Parse(time.RFC3339, string(data)); err == nil {
-			d := time.Since(lastCheck)
-			// fmt.Printf(".... since: %d < %d - %s\n", d, CHECK_VERSION_INTERVAL, path)
-			if d < CHECK_VERSION_INTERVAL {
-				// too soon
-				return
-			}
-		} else {
-			logger.Debug("cannot parse data in version check file", log.Error(err))
-		}
-	}
-
-	// check latest versionpath string
-	client := &http.Client{
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			return http.ErrUseLastResponse
-		},
-	}
-	if resp, err := client.Head(RELEASE_CHECK_URL); err != nil {
-		logger.Debug("checkForUpdates: while checking github", log.Error(err))
-	} else {
-		if loc, err := resp.Location(); err != nil {
-			logger.Debug("checkForUpdates: while getting location", log.Error(err))
-		} else {
-			p := strings.Split(loc.Path, "/")
-			latest := strings.TrimPrefix(p[len(p)-1], "v")
-			current := strings.TrimPrefix(strings.Split(currentVersion, "|")[0], "v")
-			if current != latest {
-				fmt.Fprintf(os.Stderr, "\n>>>   A newer version 'v%s' is available. Please consider upgrading from 'v%s'", latest, current)
-				fmt.Fprintf(os.Stderr, "\n>>>     It is available at %s", RELEASE_CHECK_URL)
-				fmt.Fprintf(os.Stderr, "\n>>>     Or via 'brew upgrade ivcap'\n\n")
-			}
-		}
-	}
-
-	ts := time.Now().Format(time.RFC3339)
-	if err := os.WriteFile(path, []byte(ts), fs.FileMode(0600)); err != nil {
-		logger.Debug("cannot write version check timestamp", log.Error(err))
-	}
-}
-
 func addNextPageRow(
 	nextPage *string,
 	pIn []table.Row,
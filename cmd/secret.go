@@ -46,6 +46,7 @@ func init() {
 	flags.StringVarP(&secPage, "page", "p", "", "page cursor")
 	flags.StringVarP(&secOffset, "offset", "", "", "offset token")
 	flags.StringVarP(&secFilter, "filter", "", "", "regexp filter by name")
+	addPagingFlags(listSecretsCmd)
 
 	// GET
 	secretCmd.AddCommand(getSecretCmd)
@@ -86,21 +87,14 @@ var (
 				Filter:      filter,
 			}
 
-			switch outputFormat {
-			case "json", "yaml":
-				if res, err := sdk.ListSecretsRaw(context.Background(), reqHost, req, adpr, logger); err == nil {
-					return a.ReplyPrinter(res, outputFormat == "yaml")
-				} else {
-					return err
-				}
-			default:
-				if list, err := sdk.ListSecrets(context.Background(), reqHost, req, adpr, logger); err == nil {
-					printSecretsTable(list)
-				} else {
-					return err
-				}
-			}
-			return nil
+			return printList(
+				func(ctxt context.Context) (a.Payload, error) {
+					return sdk.ListSecretsRaw(ctxt, reqHost, req, adpr, logger)
+				},
+				adpr,
+				func(list *api.ListResponseBody) *[]*api.SecretListItemResponseBody { return &list.Items },
+				printSecretsTable,
+			)
 		},
 	}
 
@@ -120,15 +114,15 @@ var (
 				SecretName: args[0],
 			}
 
-			switch outputFormat {
-			case "json", "yaml":
-				res, err := sdk.GetSecretRaw(context.Background(), reqHost, req, adpr, logger)
+			switch {
+			case !isTableOutput():
+				res, err := sdk.GetSecretRaw(RootContext(), reqHost, req, adpr, logger)
 				if err != nil {
 					return err
 				}
-				return a.ReplyPrinter(res, outputFormat == "yaml")
+				return printReply(res)
 			default:
-				secret, err := sdk.GetSecret(context.Background(), reqHost, req, adpr, logger)
+				secret, err := sdk.GetSecret(RootContext(), reqHost, req, adpr, logger)
 				if err != nil {
 					return err
 				}
@@ -180,7 +174,7 @@ var (
 				ExpiryTime:  expiresAt,
 			}
 
-			if err = sdk.SetSecret(context.Background(), reqHost, req, adpr, logger); err != nil {
+			if err = sdk.SetSecret(RootContext(), reqHost, req, adpr, logger); err != nil {
 				return fmt.Errorf("sdk failed to set secret: %w", err)
 			}
 
@@ -195,7 +189,7 @@ var (
 	}
 )
 
-func printSecretsTable(list *api.ListResponseBody) {
+func printSecretsTable(list *api.ListResponseBody, next *string) {
 	rows := make([]table.Row, len(list.Items))
 	for i, item := range list.Items {
 		expiresAt := "N/A"
@@ -204,8 +198,8 @@ func printSecretsTable(list *api.ListResponseBody) {
 		}
 		rows[i] = table.Row{*item.SecretName, expiresAt}
 	}
-	if next := findNextSecretsPage(list.Links); next != "" {
-		rows = append(rows, table.Row{"next page", next})
+	if next != nil {
+		rows = append(rows, table.Row{"next page", *next})
 	}
 
 	t := table.NewWriter()
@@ -237,18 +231,6 @@ func printSecret(secret *api.GetResponseBody) {
 	fmt.Printf("\n%s\n\n", tw.Render())
 }
 
-func findNextSecretsPage(links []*api.LinkTResponseBody) string {
-	if links == nil {
-		return ""
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return *l.Href
-		}
-	}
-	return ""
-}
-
 func getSecretHost() (string, error) {
 	ctxt, err := GetContextWithError("", true)
 	if err != nil {
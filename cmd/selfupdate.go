@@ -0,0 +1,452 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	log "go.uber.org/zap"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Releases are looked up and downloaded with the same layout as on GitHub:
+// '<url>/latest' redirects to '.../tag/<version>', and the assets of a
+// release are at '<url>/download/<version>/<asset>'.
+const RELEASES_URL = "https://github.com/ivcap-works/ivcap-cli/releases"
+
+// Assets of every release, see '.goreleaser.yml'. The checksum file is
+// signed with the release signing key.
+const RELEASE_CHECKSUMS_FILE = "checksums.txt"
+const RELEASE_SIGNATURE_FILE = RELEASE_CHECKSUMS_FILE + ".sig"
+
+// Max. size of a downloaded release archive
+const RELEASE_MAX_SIZE = 200 << 20
+const RELEASE_DOWNLOAD_TIMEOUT = 5 * time.Minute
+
+var NO_UPDATE_CHECK_ENV = ENV_PREFIX + "_NO_UPDATE_CHECK"
+var UPDATE_URL_ENV = ENV_PREFIX + "_UPDATE_URL"
+
+// Base64 encoded ed25519 public key the checksum files of releases are
+// signed with. Set at build time, see '.goreleaser.yml'.
+var releaseSigningKey = ""
+
+// UpdateConfig controls the checks for, and the downloads of, new releases
+type UpdateConfig struct {
+	Disabled  bool   `yaml:"disabled,omitempty"`   // don't check for new releases on every command
+	URL       string `yaml:"url,omitempty"`        // mirror of RELEASES_URL
+	PublicKey string `yaml:"public-key,omitempty"` // replaces the built-in release signing key
+}
+
+func init() {
+	rootCmd.AddCommand(selfUpdateCmd)
+	selfUpdateCmd.Flags().StringVar(&updateVersion, "version", "", "release to install, e.g. 'v0.42.1' [latest]")
+	selfUpdateCmd.Flags().BoolVar(&updateForce, "force", false, "install even if it's the current version, or managed by Homebrew")
+
+	configCmd.AddCommand(updatesConfigCmd)
+	updatesConfigCmd.Flags().BoolVar(&updateCheck, "check", true, "check for new releases on every command (at most once a day)")
+	updatesConfigCmd.Flags().StringVar(&updateURL, "url", "", fmt.Sprintf("mirror to get releases from, '' for '%s'", RELEASES_URL))
+	updatesConfigCmd.Flags().StringVar(&updatePublicKey, "public-key", "",
+		"base64 encoded ed25519 key the mirror's releases are signed with, '' for the built-in one")
+}
+
+var (
+	updateVersion   string
+	updateForce     bool
+	updateCheck     bool
+	updateURL       string
+	updatePublicKey string
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:     "self-update",
+	Short:   "Replace this binary with the latest, or a specific, release",
+	GroupID: generalSupportGroupID,
+	Long: `Downloads the release archive for this platform, verifies it against the
+release's checksum file and the checksum file's signature, and then replaces
+the running binary.
+
+Releases are downloaded from GitHub, unless a mirror is set with
+'ivcap config updates --url' or the '` + UPDATE_URL_ENV + `' environment variable.`,
+	Example: `  ivcap self-update
+  ivcap self-update --version v0.42.1`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		cfg := getUpdateConfig()
+		current := currentVersion()
+		target := strings.TrimPrefix(updateVersion, "v")
+		if target == "" {
+			latest, err := latestVersion()
+			if err != nil {
+				return fmt.Errorf("cannot find latest release - %w", err)
+			}
+			target = latest
+		}
+		if target == current && !updateForce {
+			fmt.Printf("Already at 'v%s'\n", current)
+			return nil
+		}
+		exe, err := os.Executable()
+		if err == nil {
+			exe, err = filepath.EvalSymlinks(exe)
+		}
+		if err != nil {
+			return fmt.Errorf("cannot find the running binary - %w", err)
+		}
+		if strings.Contains(exe, string(os.PathSeparator)+"Cellar"+string(os.PathSeparator)) && !updateForce {
+			return errors.New("this binary is managed by Homebrew, update it with 'brew upgrade ivcap' instead")
+		}
+
+		if !silent {
+			fmt.Printf("Downloading 'v%s' from %s ...\n", target, cfg.URL)
+		}
+		binary, err := downloadRelease(cfg, target, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return err
+		}
+		if err = replaceBinary(exe, binary); err != nil {
+			return err
+		}
+		fmt.Printf("Updated '%s' from 'v%s' to 'v%s'\n", exe, current, target)
+		return nil
+	},
+}
+
+var updatesConfigCmd = &cobra.Command{
+	Use:   "updates",
+	Short: "Disable the checks for new releases, or get them from a mirror",
+	Long: `Without flags, shows the current settings. They can be overridden with the
+'` + NO_UPDATE_CHECK_ENV + `' and '` + UPDATE_URL_ENV + `' environment variables.
+
+A mirror needs to have the same layout as GitHub releases: '<url>/latest'
+either redirects to '.../<version>' or returns the version, and the assets of
+a release are at '<url>/download/<version>/<asset>'.`,
+	Example: `  ivcap config updates --check=false
+  ivcap config updates --url https://mirror.example.com/ivcap-cli/releases --public-key MCow...`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, _ []string) error {
+		flags := cmd.Flags()
+		if flags.Changed("public-key") && updatePublicKey != "" {
+			if _, err := parseSigningKey(updatePublicKey); err != nil {
+				return &UsageError{err}
+			}
+		}
+		if flags.Changed("check") || flags.Changed("url") || flags.Changed("public-key") {
+			UpdateConfigFile(true, func(config *Config) {
+				if config.Updates == nil {
+					config.Updates = &UpdateConfig{}
+				}
+				if flags.Changed("check") {
+					config.Updates.Disabled = !updateCheck
+				}
+				if flags.Changed("url") {
+					config.Updates.URL = strings.TrimRight(updateURL, "/")
+				}
+				if flags.Changed("public-key") {
+					config.Updates.PublicKey = updatePublicKey
+				}
+				if *config.Updates == (UpdateConfig{}) {
+					config.Updates = nil
+				}
+			})
+		}
+		cfg := getUpdateConfig()
+		type updateSettings struct {
+			Check     bool   `json:"check"`
+			URL       string `json:"url"`
+			PublicKey string `json:"public-key,omitempty"`
+		}
+		settings := updateSettings{Check: !cfg.Disabled, URL: cfg.URL, PublicKey: cfg.PublicKey}
+		if !isTableOutput() {
+			return printValue(settings)
+		}
+		publicKey := settings.PublicKey
+		if publicKey == "" {
+			publicKey = "none, 'self-update' is not possible"
+		}
+		fmt.Printf("Check:      %t\nURL:        %s\nPublic Key: %s\n", settings.Check, settings.URL, publicKey)
+		return nil
+	},
+}
+
+// Returns the update settings of the config file, overridden by the
+// environment, with defaults filled in.
+func getUpdateConfig() UpdateConfig {
+	var cfg UpdateConfig
+	// a broken config file is reported by the commands using it
+	if data, err := os.ReadFile(filepath.Clean(GetConfigFilePath())); err == nil {
+		var config Config
+		if yaml.Unmarshal(data, &config) == nil && config.Updates != nil {
+			cfg = *config.Updates
+		}
+	}
+	if v := os.Getenv(NO_UPDATE_CHECK_ENV); v != "" {
+		if disabled, err := strconv.ParseBool(v); err == nil {
+			cfg.Disabled = disabled
+		} else {
+			fmt.Fprintf(os.Stderr, "WARNING: ignoring invalid value '%s' of '%s'\n", v, NO_UPDATE_CHECK_ENV)
+		}
+	}
+	if v := os.Getenv(UPDATE_URL_ENV); v != "" {
+		cfg.URL = v
+	}
+	if cfg.URL == "" {
+		cfg.URL = RELEASES_URL
+	}
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.PublicKey == "" {
+		cfg.PublicKey = releaseSigningKey
+	}
+	return cfg
+}
+
+// Returns the version of this binary without the leading 'v'
+func currentVersion() string {
+	return strings.TrimPrefix(strings.Split(rootCmd.Version, "|")[0], "v")
+}
+
+func checkForUpdates() {
+	cfg := getUpdateConfig()
+	if cfg.Disabled {
+		return
+	}
+	path := makeConfigFilePath(VERSION_CHECK_FILE_NAME)
+	if data, err := os.ReadFile(filepath.Clean(path)); err == nil {
+		if lastCheck, err := time.Parse(time.RFC3339, string(data)); err == nil {
+			d := time.Since(lastCheck)
+			if d < CHECK_VERSION_INTERVAL {
+				// too soon
+				return
+			}
+		} else {
+			logger.Debug("cannot parse data in version check file", log.Error(err))
+		}
+	}
+
+	if latest, err := latestVersion(); err != nil {
+		logger.Debug("checkForUpdates: while checking for releases", log.Error(err))
+	} else if current := currentVersion(); current != latest {
+		fmt.Fprintf(os.Stderr, "\n>>>   A newer version 'v%s' is available. Please consider upgrading from 'v%s'", latest, current)
+		fmt.Fprintf(os.Stderr, "\n>>>     with 'ivcap self-update' or 'brew upgrade ivcap'")
+		fmt.Fprintf(os.Stderr, "\n>>>     Disable this check with 'ivcap config updates --check=false'\n\n")
+	}
+
+	ts := time.Now().Format(time.RFC3339)
+	if err := writeFileLocked(path, []byte(ts), fs.FileMode(0600)); err != nil {
+		logger.Debug("cannot write version check timestamp", log.Error(err))
+	}
+}
+
+// Returns the version of the latest release, without the leading 'v'. It's
+// taken from the redirect of '<url>/latest', or from its content if it
+// doesn't redirect.
+func latestVersion() (string, error) {
+	client := &http.Client{
+		Timeout: time.Duration(timeout) * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Get(getUpdateConfig().URL + "/latest")
+	if err != nil {
+		return "", err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	var version string
+	if loc, err := resp.Location(); err == nil {
+		version = path.Base(loc.Path)
+	} else if resp.StatusCode == http.StatusOK {
+		data, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+		if err != nil {
+			return "", err
+		}
+		version = strings.TrimSpace(string(data))
+	} else {
+		return "", fmt.Errorf("unexpected reply '%s' from '%s'", resp.Status, resp.Request.URL)
+	}
+	if version == "" || strings.ContainsAny(version, " /\n") {
+		return "", fmt.Errorf("invalid version '%s' from '%s'", version, resp.Request.URL)
+	}
+	return strings.TrimPrefix(version, "v"), nil
+}
+
+// Returns the name of the release archive for `version` on `goos` and
+// `goarch`, see the archive's 'name_template' in '.goreleaser.yml'.
+func releaseAssetName(version, goos, goarch string) string {
+	return fmt.Sprintf("ivcap-cli_%s_%s_%s.tar.gz", version, goos, goarch)
+}
+
+func parseSigningKey(key string) (ed25519.PublicKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(key))
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("release signing key needs to be a base64 encoded ed25519 public key")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// Downloads the release archive for `version`, `goos` and `goarch`, checks
+// it against the signed checksum file of the release, and returns the
+// 'ivcap' binary in it.
+func downloadRelease(cfg UpdateConfig, version, goos, goarch string) ([]byte, error) {
+	if cfg.PublicKey == "" {
+		return nil, errors.New("there is no release signing key to verify releases with, " +
+			"set one with 'ivcap config updates --public-key'")
+	}
+	key, err := parseSigningKey(cfg.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	baseURL := fmt.Sprintf("%s/download/v%s/", cfg.URL, version)
+	checksums, err := downloadReleaseAsset(baseURL + RELEASE_CHECKSUMS_FILE)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := downloadReleaseAsset(baseURL + RELEASE_SIGNATURE_FILE)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil || !ed25519.Verify(key, checksums, sig) {
+		return nil, fmt.Errorf("signature of '%s' of 'v%s' is invalid", RELEASE_CHECKSUMS_FILE, version)
+	}
+
+	asset := releaseAssetName(version, goos, goarch)
+	expected := ""
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		if f := strings.Fields(scanner.Text()); len(f) == 2 && f[1] == asset {
+			expected = f[0]
+		}
+	}
+	if expected == "" {
+		return nil, fmt.Errorf("release 'v%s' has no '%s' for this platform", version, asset)
+	}
+	archive, err := downloadReleaseAsset(baseURL + asset)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(archive); hex.EncodeToString(sum[:]) != strings.ToLower(expected) {
+		return nil, fmt.Errorf("checksum of '%s' doesn't match '%s'", asset, RELEASE_CHECKSUMS_FILE)
+	}
+	return extractBinary(archive, goos)
+}
+
+func downloadReleaseAsset(url string) ([]byte, error) {
+	client := &http.Client{Timeout: RELEASE_DOWNLOAD_TIMEOUT}
+	resp, err := client.Get(url) // #nosec G107 -- the URL is built from the configured release mirror
+	if err != nil {
+		return nil, fmt.Errorf("cannot download '%s' - %w", url, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot download '%s' - %s", url, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, RELEASE_MAX_SIZE+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot download '%s' - %w", url, err)
+	}
+	if len(data) > RELEASE_MAX_SIZE {
+		return nil, fmt.Errorf("'%s' is larger than %d bytes", url, RELEASE_MAX_SIZE)
+	}
+	return data, nil
+}
+
+// Returns the 'ivcap' binary in the release `archive`
+func extractBinary(archive []byte, goos string) ([]byte, error) {
+	name := "ivcap"
+	if goos == "windows" {
+		name += ".exe"
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read release archive - %w", err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("release archive doesn't contain '%s'", name)
+		} else if err != nil {
+			return nil, fmt.Errorf("cannot read release archive - %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && path.Base(hdr.Name) == name {
+			return io.ReadAll(io.LimitReader(tr, RELEASE_MAX_SIZE))
+		}
+	}
+}
+
+// Atomically replaces the binary at `exe` with `binary`. Windows doesn't
+// allow replacing a running binary, so it's moved out of the way first.
+func replaceBinary(exe string, binary []byte) error {
+	info, err := os.Stat(exe)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(exe), "."+filepath.Base(exe)+".*")
+	if err != nil {
+		return fmt.Errorf("cannot write to '%s' - %w", filepath.Dir(exe), err)
+	}
+	tmp := f.Name()
+	defer func() { _ = os.Remove(tmp) }()
+	if _, err = f.Write(binary); err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp, info.Mode().Perm()|0500)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot write new binary - %w", err)
+	}
+	old := ""
+	if runtime.GOOS == "windows" {
+		old = exe + ".old"
+		_ = os.Remove(old)
+		if err = os.Rename(exe, old); err != nil {
+			return fmt.Errorf("cannot move '%s' out of the way - %w", exe, err)
+		}
+	}
+	if err = os.Rename(tmp, exe); err != nil {
+		if old != "" {
+			// put the running binary back
+			if rerr := os.Rename(old, exe); rerr != nil {
+				return fmt.Errorf("cannot replace '%s' - %w, and cannot restore it from '%s' - %v", exe, err, old, rerr)
+			}
+		}
+		return fmt.Errorf("cannot replace '%s' - %w", exe, err)
+	}
+	return nil
+}
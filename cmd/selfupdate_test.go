@@ -0,0 +1,132 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Returns a mirror serving release 'v1.2.3' for linux/amd64 with its
+// checksum file signed by the returned key.
+func newReleaseMirror(t *testing.T, binary []byte) (*httptest.Server, ed25519.PublicKey, map[string][]byte) {
+	t.Helper()
+	var archive bytes.Buffer
+	gz := gzip.NewWriter(&archive)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "ivcap", Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = tw.Write(binary)
+	_ = tw.Close()
+	_ = gz.Close()
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset := releaseAssetName("1.2.3", "linux", "amd64")
+	sum := sha256.Sum256(archive.Bytes())
+	checksums := []byte(fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), asset))
+	files := map[string][]byte{
+		"/latest":                   []byte("v1.2.3\n"),
+		"/download/v1.2.3/" + asset: archive.Bytes(),
+		"/download/v1.2.3/" + RELEASE_CHECKSUMS_FILE: checksums,
+		"/download/v1.2.3/" + RELEASE_SIGNATURE_FILE: []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(priv, checksums))),
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if data, ok := files[r.URL.Path]; ok {
+			_, _ = w.Write(data)
+		} else {
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, pub, files
+}
+
+func TestDownloadRelease(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	srv, pub, files := newReleaseMirror(t, []byte("new binary"))
+	t.Setenv(UPDATE_URL_ENV, srv.URL)
+
+	if v, err := latestVersion(); err != nil || v != "1.2.3" {
+		t.Fatalf("expected latest version '1.2.3', got '%s' - %v", v, err)
+	}
+	cfg := UpdateConfig{URL: srv.URL, PublicKey: base64.StdEncoding.EncodeToString(pub)}
+	binary, err := downloadRelease(cfg, "1.2.3", "linux", "amd64")
+	if err != nil || string(binary) != "new binary" {
+		t.Fatalf("expected verified binary, got '%s' - %v", binary, err)
+	}
+	if _, err = downloadRelease(cfg, "1.2.3", "darwin", "arm64"); err == nil {
+		t.Error("expected missing platform to fail")
+	}
+
+	otherKey, _, _ := ed25519.GenerateKey(nil)
+	cfg.PublicKey = base64.StdEncoding.EncodeToString(otherKey)
+	if _, err = downloadRelease(cfg, "1.2.3", "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("expected signature check to fail, got %v", err)
+	}
+
+	cfg.PublicKey = base64.StdEncoding.EncodeToString(pub)
+	asset := "/download/v1.2.3/" + releaseAssetName("1.2.3", "linux", "amd64")
+	files[asset] = append(files[asset], 0)
+	if _, err = downloadRelease(cfg, "1.2.3", "linux", "amd64"); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum check to fail, got %v", err)
+	}
+}
+
+func TestReplaceBinary(t *testing.T) {
+	exe := filepath.Join(t.TempDir(), "ivcap")
+	if err := os.WriteFile(exe, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := replaceBinary(exe, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "new" {
+		t.Errorf("expected binary to be replaced, got '%s'", data)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(exe)); len(entries) != 1 {
+		t.Errorf("expected no leftover files, got %v", entries)
+	}
+}
+
+func TestGetUpdateConfig(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	UpdateConfigFile(true, func(config *Config) {
+		config.Updates = &UpdateConfig{URL: "https://mirror.example.com/releases/"}
+	})
+	if cfg := getUpdateConfig(); cfg.Disabled || cfg.URL != "https://mirror.example.com/releases" {
+		t.Errorf("unexpected settings from config file %+v", cfg)
+	}
+	t.Setenv(NO_UPDATE_CHECK_ENV, "true")
+	t.Setenv(UPDATE_URL_ENV, "https://other.example.com")
+	if cfg := getUpdateConfig(); !cfg.Disabled || cfg.URL != "https://other.example.com" {
+		t.Errorf("expected environment to override config file, got %+v", cfg)
+	}
+}
@@ -38,6 +38,7 @@ func init() {
 	// SEARCH (helper around LIST)
 	serviceCmd.AddCommand(searchServiceCmd)
 	addLimitFlag(searchServiceCmd)
+	addPagingFlags(searchServiceCmd)
 
 	// READ
 	serviceCmd.AddCommand(readServiceCmd)
@@ -70,24 +71,7 @@ var (
 		Short: "List existing service",
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			req := createListRequest()
-			if res, err := sdk.ListServicesRaw(context.Background(), req, CreateAdapter(true), logger); err == nil {
-				switch outputFormat {
-				case "json":
-					return a.ReplyPrinter(res, false)
-				case "yaml":
-					return a.ReplyPrinter(res, true)
-				default:
-					var list sdk.ServiceListResponseBody
-					if err = res.AsType(&list); err != nil {
-						return err
-					}
-					printServiceTable(&list, false)
-				}
-				return nil
-			} else {
-				return err
-			}
+			return printServiceList(createListRequest())
 		},
 	}
 
@@ -102,23 +86,7 @@ var (
 			if limit > 0 {
 				req.Limit = limit
 			}
-			if res, err := sdk.ListServicesRaw(context.Background(), req, CreateAdapter(true), logger); err == nil {
-				switch outputFormat {
-				case "json":
-					return a.ReplyPrinter(res, false)
-				case "yaml":
-					return a.ReplyPrinter(res, true)
-				default:
-					var list sdk.ServiceListResponseBody
-					if err = res.AsType(&list); err != nil {
-						return err
-					}
-					printServiceTable(&list, false)
-				}
-				return nil
-			} else {
-				return err
-			}
+			return printServiceList(req)
 		},
 	}
 
@@ -131,16 +99,16 @@ var (
 			recordID := GetHistory(args[0])
 			req := &sdk.ReadServiceRequest{Id: GetHistory(recordID)}
 
-			switch outputFormat {
-			case "json", "yaml":
-				if res, err := sdk.ReadServiceRaw(context.Background(), req, CreateAdapter(true), logger); err == nil {
-					return a.ReplyPrinter(res, outputFormat == "yaml")
+			switch {
+			case !isTableOutput():
+				if res, err := sdk.ReadServiceRaw(RootContext(), req, CreateCachedAdapter(true), logger); err == nil {
+					return printReply(res)
 				} else {
 					return err
 				}
 			default:
-				if service, err := sdk.ReadService(context.Background(), req, CreateAdapter(true), logger); err == nil {
-					printService(service, false)
+				if service, err := sdk.ReadService(RootContext(), req, CreateCachedAdapter(true), logger); err == nil {
+					printService(service, isWideOutput())
 				} else {
 					return err
 				}
@@ -157,14 +125,14 @@ described in a service definition file. If the service definition is provided
 through 'stdin' use '-' as the file name and also include the --format flag`,
 		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 
 			if fileName == "" {
-				cobra.CheckErr("Missing service file '-f service-file|-'")
+				checkErr("Missing service file '-f service-file|-'")
 			}
 			pyld, err := payloadFromFile(fileName, inputFormat)
 			if err != nil {
-				cobra.CheckErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
+				checkErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
 			}
 			var req sdk.ServiceCreateRequestBody
 			if err = pyld.AsType(&req); err != nil {
@@ -174,7 +142,7 @@ through 'stdin' use '-' as the file name and also include the --format flag`,
 			if err != nil {
 				return err
 			}
-			return a.ReplyPrinter(res, outputFormat == "yaml")
+			return printReply(res)
 		},
 	}
 
@@ -186,10 +154,10 @@ AND the --create flag is set. If the service definition is provided
 through 'stdin' use '-' as the file name and also include the --format flag`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
-			ctxt := context.Background()
+			ctxt := RootContext()
 			// serviceFile := args[1]
 			if fileName == "" {
-				cobra.CheckErr("Missing service file '-f service-file|-'")
+				checkErr("Missing service file '-f service-file|-'")
 			}
 
 			isYaml := inputFormat == "yaml" || strings.HasSuffix(fileName, ".yaml") || strings.HasSuffix(fileName, ".yml")
@@ -200,7 +168,7 @@ through 'stdin' use '-' as the file name and also include the --format flag`,
 				pyld, err = a.LoadPayloadFromStdin(isYaml)
 			}
 			if err != nil {
-				cobra.CheckErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
+				checkErr(fmt.Sprintf("While reading service file '%s' - %s", fileName, err))
 			}
 
 			var req sdk.ServiceUpdateRequestBody
@@ -224,27 +192,46 @@ through 'stdin' use '-' as the file name and also include the --format flag`,
 				}
 			}
 			if serviceID == "" {
-				cobra.CheckErr("Missing 'serviceID'. Neither provided nor found in serviceFile as 'ID' or '$id'")
+				checkErr("Missing 'serviceID'. Neither provided nor found in serviceFile as 'ID' or '$id'")
 			}
 			req.ID = serviceID
 			res, err := sdk.UpdateServiceRaw(ctxt, serviceID, createAnyway, &req, CreateAdapter(true), logger)
 			if err != nil {
 				return err
 			}
-			return a.ReplyPrinter(res, outputFormat == "yaml")
+			return printReply(res)
 		},
 	}
 )
 
-func printServiceTable(list *sdk.ServiceListResponseBody, wide bool) {
+func printServiceList(req *sdk.ListRequest) error {
+	adapter := CreateAdapter(true)
+	return printList(
+		func(ctxt context.Context) (a.Payload, error) {
+			return sdk.ListServicesRaw(ctxt, req, adapter, logger)
+		},
+		adapter,
+		func(list *sdk.ServiceListResponseBody) *[]*sdk.ServiceListItemTResponseBody { return &list.Items },
+		func(list *sdk.ServiceListResponseBody, next *string) { printServiceTable(list, next, isWideOutput()) },
+	)
+}
+
+func printServiceTable(list *sdk.ServiceListResponseBody, next *string, wide bool) {
 	t := table.NewWriter()
 	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(table.Row{"ID", "Name", "Description"})
+	header := table.Row{"ID", "Name", "Description"}
+	if wide {
+		header = append(header, "Tags", "Controller", "URN")
+	}
+	t.AppendHeader(header)
 	rows := make([]table.Row, len(list.Items))
 	for i, o := range list.Items {
 		rows[i] = table.Row{MakeHistory(o.ID), safeTruncString(o.Name), safeString(o.Description)}
+		if wide {
+			rows[i] = append(rows[i], strings.Join(o.Tags, ", "), safeString(o.ControllerSchema), safeString(o.ID))
+		}
 	}
-	rows = addNextPageRow(findNextServicePage(list.Links), rows)
+	rows = addNextPageRow(next, rows)
 	t.AppendRows(rows)
 	t.SetColumnConfigs([]table.ColumnConfig{
 		{Number: 2, WidthMaxEnforcer: text.WrapSoft},
@@ -328,32 +315,27 @@ func getPType(p *sdk.ParameterDefTResponseBody) string {
 	return fmt.Sprintf("[%s]", strings.Join(oa, ","))
 }
 
+// service names already looked up by this process
+var serviceNames = map[string]string{}
+
 func GetServiceNameForId(serviceID *string) string {
 	if serviceID == nil {
 		return "???"
 	}
+	if name, ok := serviceNames[*serviceID]; ok {
+		return name
+	}
 	req := &sdk.ReadServiceRequest{
 		Id: *serviceID,
 	}
-	if resp, err := sdk.ReadService(context.Background(), req, CreateAdapter(true), logger); err == nil {
+	if resp, err := sdk.ReadService(RootContext(), req, CreateCachedAdapter(true), logger); err == nil {
+		serviceNames[*serviceID] = *resp.Name
 		return *resp.Name
 	} else {
 		return *serviceID
 	}
 }
 
-func findNextServicePage(links []*sdk.LinkTResponseBody) *string {
-	if links == nil {
-		return nil
-	}
-	for _, l := range links {
-		if l.Rel != nil && *l.Rel == "next" {
-			return l.Href
-		}
-	}
-	return nil
-}
-
 func WrapSoftSoft(str string, wrapLen int) string {
 	if wrapLen <= 0 {
 		return ""
@@ -33,18 +33,26 @@ var (
 
 func TestMain(m *testing.M) {
 	initConfig()
+	setupLiveAdapter()
+	os.Exit(m.Run())
+}
+
+// Connects 'adapter' to a local test deployment if the active context points
+// to one. Tests which need it skip when 'testToken' is empty, all others run
+// without a deployment.
+func setupLiveAdapter() {
 	ctxt, err := GetContextWithError("", true)
 	if err != nil {
-		fmt.Printf("Can not get active context, %s\n", err)
+		fmt.Printf("Can not get active context, %s - skipping deployment tests\n", err)
 		return
 	}
 	if ctxt.Name != "minikube" && ctxt.Name != "docker-desktop" && !strings.HasPrefix(ctxt.URL, "http://localhost") {
-		fmt.Printf("Unit test should run against minikube, please set to minikube context\n")
+		fmt.Printf("Active context is not minikube - skipping deployment tests\n")
 		return
 	}
-	testToken = getAccessToken(true)
-	if testToken == "" {
-		fmt.Printf("Access token not found\n")
+	token := getAccessToken(true)
+	if token == "" {
+		fmt.Printf("Access token not found - skipping deployment tests\n")
 		return
 	}
 
@@ -54,7 +62,7 @@ func TestMain(m *testing.M) {
 		headers = &(map[string]string{"Host": ctxt.Host})
 	}
 
-	adapter, err = NewAdapter(url, testToken, DEFAULT_SERVICE_TIMEOUT_IN_SECONDS, headers)
+	adapter, err = NewAdapter(url, token, DEFAULT_SERVICE_TIMEOUT_IN_SECONDS, headers)
 	if err != nil {
 		fmt.Printf("Failed to get adapter: %v\n", err)
 		return
@@ -68,6 +76,5 @@ func TestMain(m *testing.M) {
 		fmt.Printf("Failed to create tlogger: %v\n", err)
 		return
 	}
-
-	os.Exit(m.Run())
+	testToken = token
 }
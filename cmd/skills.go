@@ -64,13 +64,13 @@ var skillsListCmd = &cobra.Command{
 			})
 		}
 
-		switch outputFormat {
-		case "json", "yaml":
+		switch {
+		case !isTableOutput():
 			payload, err := adpt.JsonPayloadFromAny(map[string]any{"skills": items}, logger)
 			if err != nil {
 				return err
 			}
-			return adpt.ReplyPrinter(payload, outputFormat == "yaml")
+			return printReply(payload)
 		default:
 			for _, s := range items {
 				if _, err := fmt.Fprintf(os.Stdout, "%s\t%s\t%s\n", s.Name, s.Version, s.Description); err != nil {
@@ -96,13 +96,13 @@ var skillsShowCmd = &cobra.Command{
 			if err != nil {
 				return err
 			}
-			switch outputFormat {
-			case "json", "yaml":
+			switch {
+			case !isTableOutput():
 				payload, err := adpt.JsonPayloadFromAny(map[string]any{"uri": ref, "path": p, "content": string(b)}, logger)
 				if err != nil {
 					return err
 				}
-				return adpt.ReplyPrinter(payload, outputFormat == "yaml")
+				return printReply(payload)
 			default:
 				if _, err := fmt.Fprint(os.Stdout, string(b)); err != nil {
 					return err
@@ -125,13 +125,13 @@ var skillsShowCmd = &cobra.Command{
 			return fmt.Errorf("unknown skill '%s'", ref)
 		}
 
-		switch outputFormat {
-		case "json", "yaml":
+		switch {
+		case !isTableOutput():
 			payload, err := adpt.JsonPayloadFromAny(d, logger)
 			if err != nil {
 				return err
 			}
-			return adpt.ReplyPrinter(payload, outputFormat == "yaml")
+			return printReply(payload)
 		default:
 			if _, err := fmt.Fprint(os.Stdout, d.Content); err != nil {
 				return err
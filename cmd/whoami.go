@@ -0,0 +1,250 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/spf13/cobra"
+)
+
+// Where the access token shown by 'whoami' came from
+const (
+	TOKEN_SOURCE_FLAG  = "flag"
+	TOKEN_SOURCE_ENV   = "env"
+	TOKEN_SOURCE_CACHE = "cache"
+)
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:     "whoami",
+	Short:   "Show who the current access token belongs to, and what it grants",
+	GroupID: generalSupportGroupID,
+	Long: `Decodes the access token which would be used for the next request, and
+the ID token of the last login, and shows the account, provider, groups,
+scopes and audience they carry, when they were issued and when they expire.
+
+The token is taken from '--access-token', the environment variable
+'IVCAP_ACCESS_TOKEN', or the credentials cached for the active context (in
+that order). Cached tokens are refreshed first if they have expired. The
+ID token is only shown for cached tokens.
+
+The signatures of the tokens are not verified, use this command for
+debugging only.`,
+	Example: `  ivcap whoami
+  ivcap whoami -o json --query '.groups'`,
+	Args: cobra.NoArgs,
+	RunE: func(_ *cobra.Command, _ []string) error {
+		info, err := getWhoami()
+		if err != nil {
+			return err
+		}
+		if !isTableOutput() {
+			return printValue(info)
+		}
+		printWhoamiTable(info)
+		return nil
+	},
+}
+
+// whoamiInfo describes the identity and grants of the current access token
+type whoamiInfo struct {
+	Context         string         `json:"context"`
+	TokenSource     string         `json:"token-source"`
+	CredentialStore string         `json:"credential-store,omitempty"`
+	Subject         string         `json:"subject,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	Email           string         `json:"email,omitempty"`
+	AccountID       string         `json:"account-id,omitempty"`
+	ProviderID      string         `json:"provider-id,omitempty"`
+	ClientID        string         `json:"client-id,omitempty"`
+	Groups          []string       `json:"groups,omitempty"`
+	Scopes          []string       `json:"scopes,omitempty"`
+	Audience        []string       `json:"audience,omitempty"`
+	Issuer          string         `json:"issuer,omitempty"`
+	IssuedAt        *time.Time     `json:"issued-at,omitempty"`
+	ExpiresAt       *time.Time     `json:"expires-at,omitempty"`
+	AccessToken     map[string]any `json:"access-token-claims,omitempty"`
+	IDToken         map[string]any `json:"id-token-claims,omitempty"`
+}
+
+// accessTokenClaims are the claims of an access token we know about. The
+// IVCAP specific ones are usually the same as in the ID token.
+type accessTokenClaims struct {
+	CustomIdClaims
+	Scope       string           `json:"scope,omitempty"`
+	Scp         jwt.ClaimStrings `json:"scp,omitempty"`
+	Permissions []string         `json:"permissions,omitempty"`
+	AZP         string           `json:"azp,omitempty"`
+}
+
+func getWhoami() (*whoamiInfo, error) {
+	ctxt := GetActiveContext()
+	info := &whoamiInfo{Context: ctxt.Name}
+	switch {
+	case accessTokenF != "" && envFlags["access-token"] == "":
+		info.TokenSource = TOKEN_SOURCE_FLAG
+	case accessTokenF != "" || os.Getenv(ACCESS_TOKEN_ENV) != "":
+		info.TokenSource = TOKEN_SOURCE_ENV
+	default:
+		info.TokenSource = TOKEN_SOURCE_CACHE
+		info.CredentialStore = ctxt.CredentialStore
+		if info.CredentialStore == "" {
+			info.CredentialStore = PLAINTEXT_CREDENTIAL_STORE
+		}
+		info.ClientID = ctxt.ClientID
+		if err := loadCredentials(ctxt); err != nil {
+			return nil, err
+		}
+		if ctxt.AccessToken == "" && ctxt.RefreshToken == "" && ctxt.ClientID == "" {
+			return nil, fmt.Errorf("not logged into context '%s', use 'ivcap context login'", ctxt.Name)
+		}
+	}
+	accessToken := getAccessToken(true)
+
+	var claims accessTokenClaims
+	if raw, err := decodeToken(accessToken, &claims); err != nil {
+		// not necessarily a JWT, all we know is when it expires
+		if info.TokenSource == TOKEN_SOURCE_CACHE && !ctxt.AccessTokenExpiry.IsZero() {
+			info.ExpiresAt = &ctxt.AccessTokenExpiry
+		}
+	} else {
+		info.AccessToken = raw
+		info.Subject = claims.Subject
+		info.Issuer = claims.Issuer
+		info.Audience = claims.Audience
+		info.IssuedAt = numericTime(claims.IssuedAt)
+		info.ExpiresAt = numericTime(claims.ExpiresAt)
+		if claims.Scope != "" {
+			info.Scopes = strings.Fields(claims.Scope)
+		} else {
+			info.Scopes = claims.Scp
+		}
+		for _, p := range claims.Permissions {
+			if !slices.Contains(info.Scopes, p) {
+				info.Scopes = append(info.Scopes, p)
+			}
+		}
+		if info.ClientID == "" && info.Subject == claims.AZP+"@clients" {
+			// client credentials
+			info.ClientID = claims.AZP
+		}
+		setWhoamiIdentity(info, &claims.CustomIdClaims)
+	}
+
+	if info.TokenSource == TOKEN_SOURCE_CACHE && ctxt.IDToken != "" {
+		var idClaims CustomIdClaims
+		if raw, err := decodeToken(ctxt.IDToken, &idClaims); err == nil {
+			info.IDToken = raw
+			setWhoamiIdentity(info, &idClaims)
+		}
+	}
+	return info, nil
+}
+
+// Fills in the identity of `info` from `claims`, unless it is already known.
+func setWhoamiIdentity(info *whoamiInfo, claims *CustomIdClaims) {
+	setIfEmpty := func(s *string, v string) {
+		if *s == "" {
+			*s = v
+		}
+	}
+	setIfEmpty(&info.Subject, claims.Subject)
+	setIfEmpty(&info.Name, claims.Name)
+	setIfEmpty(&info.Email, claims.Email)
+	if claims.AccountID != "" {
+		setIfEmpty(&info.AccountID, fmt.Sprintf("urn:%s:account:%s", URN_PREFIX, claims.AccountID))
+	}
+	if claims.ProviderID != "" {
+		setIfEmpty(&info.ProviderID, fmt.Sprintf("urn:%s:provider:%s", URN_PREFIX, claims.ProviderID))
+	}
+	if len(info.Groups) == 0 {
+		info.Groups = claims.GroupIDs
+	}
+}
+
+// Decodes the claims of JWT `token` into `claims` without verifying it, and
+// also returns them as a map.
+func decodeToken(token string, claims jwt.Claims) (map[string]any, error) {
+	parser := jwt.NewParser()
+	if _, _, err := parser.ParseUnverified(token, claims); err != nil {
+		return nil, err
+	}
+	raw := jwt.MapClaims{}
+	if _, _, err := parser.ParseUnverified(token, raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func numericTime(d *jwt.NumericDate) *time.Time {
+	if d == nil {
+		return nil
+	}
+	return &d.Time
+}
+
+func printWhoamiTable(info *whoamiInfo) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendRow(table.Row{"Context", info.Context})
+	source := info.TokenSource
+	switch info.TokenSource {
+	case TOKEN_SOURCE_FLAG:
+		source = "'--access-token' flag"
+	case TOKEN_SOURCE_ENV:
+		source = fmt.Sprintf("environment variable '%s'", ACCESS_TOKEN_ENV)
+	case TOKEN_SOURCE_CACHE:
+		source = fmt.Sprintf("cached in context (%s)", info.CredentialStore)
+	}
+	t.AppendRow(table.Row{"Token Source", source})
+	optional := func(name string, value string) {
+		if value != "" {
+			t.AppendRow(table.Row{name, value})
+		}
+	}
+	optional("Subject", info.Subject)
+	optional("Name", info.Name)
+	optional("Email", info.Email)
+	optional("Account ID", info.AccountID)
+	optional("Provider ID", info.ProviderID)
+	optional("Client ID", info.ClientID)
+	optional("Groups", strings.Join(info.Groups, "\n"))
+	optional("Scopes", strings.Join(info.Scopes, "\n"))
+	optional("Audience", strings.Join(info.Audience, "\n"))
+	optional("Issuer", info.Issuer)
+	if info.IssuedAt != nil {
+		t.AppendRow(table.Row{"Issued At", info.IssuedAt.Local().Format(time.RFC822)})
+	}
+	if info.ExpiresAt != nil {
+		expires := info.ExpiresAt.Local().Format(time.RFC822)
+		if d := time.Until(*info.ExpiresAt); d > 0 {
+			expires += fmt.Sprintf(" (in %s)", d.Round(time.Second))
+		} else {
+			expires += " (expired)"
+		}
+		t.AppendRow(table.Row{"Expires At", expires})
+	}
+	t.Render()
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+func TestGetWhoami_CachedTokens(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv(ACCESS_TOKEN_ENV, "")
+
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+	sign := func(claims jwt.Claims) string {
+		s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	accessToken := sign(jwt.MapClaims{
+		"sub":   "auth0|123",
+		"aud":   []string{"https://api.ivcap.net", "https://ivcap.au.auth0.com/userinfo"},
+		"scope": "openid profile email",
+		"exp":   expiry.Unix(),
+		"acc":   "acc-1",
+	})
+	idToken := sign(&CustomIdClaims{
+		Name:       "Jane",
+		Email:      "jane@example.com",
+		AccountID:  "acc-1",
+		ProviderID: "prov-1",
+		GroupIDs:   []string{"g1", "g2"},
+	})
+	SetContext(&Context{
+		Name:              "whoami",
+		URL:               "https://ivcap.example.com",
+		AccessToken:       accessToken,
+		AccessTokenExpiry: expiry,
+		IDToken:           idToken,
+	}, false)
+
+	info, err := getWhoami()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TokenSource != TOKEN_SOURCE_CACHE || info.CredentialStore != PLAINTEXT_CREDENTIAL_STORE {
+		t.Errorf("unexpected token source '%s' (%s)", info.TokenSource, info.CredentialStore)
+	}
+	if info.Subject != "auth0|123" || info.Name != "Jane" || info.Email != "jane@example.com" {
+		t.Errorf("unexpected identity %+v", info)
+	}
+	if info.AccountID != "urn:ivcap:account:acc-1" || info.ProviderID != "urn:ivcap:provider:prov-1" {
+		t.Errorf("unexpected account '%s' or provider '%s'", info.AccountID, info.ProviderID)
+	}
+	if !slices.Equal(info.Groups, []string{"g1", "g2"}) || !slices.Equal(info.Scopes, []string{"openid", "profile", "email"}) {
+		t.Errorf("unexpected groups %v or scopes %v", info.Groups, info.Scopes)
+	}
+	if len(info.Audience) != 2 || info.ExpiresAt == nil || !info.ExpiresAt.Equal(expiry) {
+		t.Errorf("unexpected audience %v or expiry %v", info.Audience, info.ExpiresAt)
+	}
+	if info.IDToken["email"] != "jane@example.com" {
+		t.Errorf("expected ID token claims, got %v", info.IDToken)
+	}
+}
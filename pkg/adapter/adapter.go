@@ -18,16 +18,22 @@ package adapter
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	neturl "net/url"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/cenkalti/backoff/v4"
+	"github.com/google/uuid"
 	"github.com/r3labs/sse/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	log "go.uber.org/zap"
+	sseBackoff "gopkg.in/cenkalti/backoff.v1"
 )
 
 type ConnectionCtxt struct {
@@ -51,14 +57,35 @@ func WithConnContext(connCtxt *ConnectionCtxt) Option {
 	}
 }
 
+// WithTokenSource sets the source for the access tokens attached to each
+// request. It takes precedence over `ConnectionCtxt.AccessToken`.
+func WithTokenSource(tokens TokenSource) Option {
+	return func(adpr *restAdapter) {
+		adpr.tokens = tokens
+	}
+}
+
+// WithCassette records all traffic to, or replays it from, cassette `c`.
+// It needs to come after any `WithHttpClient` option.
+func WithCassette(c *Cassette) Option {
+	return func(adpr *restAdapter) {
+		adpr.client.Transport = c.RoundTripper(adpr.client.Transport)
+	}
+}
+
 func RestAdapter(opts ...Option) Adapter {
 	adpr := &restAdapter{
 		client:   &http.Client{},
 		connCtxt: &ConnectionCtxt{},
+		retry:    DefaultRetryPolicy(),
+		tracer:   noopTracer,
 	}
 	for _, opt := range opts {
 		opt(adpr)
 	}
+	if adpr.cache != nil {
+		adpr.client.Transport = adpr.cache.RoundTripper(adpr.client.Transport)
+	}
 
 	return adpr
 }
@@ -83,9 +110,15 @@ func (e MissingUrlError) Error() string { return "Missing deployment URL" }
 
 type ResourceNotFoundError struct {
 	AdapterError
+	Details *ErrorDetails
 }
 
-func (e ResourceNotFoundError) Error() string { return "Resource not found" }
+func (e ResourceNotFoundError) Error() string {
+	if e.Details != nil && e.Details.ID != "" {
+		return fmt.Sprintf("Resource '%s' not found", e.Details.ID)
+	}
+	return "Resource not found"
+}
 
 type UnauthorizedError struct {
 	AdapterError
@@ -93,13 +126,38 @@ type UnauthorizedError struct {
 
 func (e *UnauthorizedError) Error() string { return "Unauthorized access" }
 
+type TokenSourceError struct {
+	AdapterError
+	err error
+}
+
+func (e *TokenSourceError) Error() string {
+	return fmt.Sprintf("cannot obtain access token - %s", e.err.Error())
+}
+
+func (e *TokenSourceError) Unwrap() error { return e.err }
+
 type ApiError struct {
 	AdapterError
 	StatusCode int
 	Payload    Payload
+	Details    *ErrorDetails // nil if the reply isn't an IVCAP error body
 }
 
 func (e *ApiError) Error() string {
+	if d := e.Details; d != nil {
+		msg := fmt.Sprintf("%d: %s", e.StatusCode, d.Message)
+		if d.Message == "" {
+			msg = fmt.Sprintf("%d: %s", e.StatusCode, http.StatusText(e.StatusCode))
+		}
+		if d.Name != "" {
+			msg = fmt.Sprintf("%s (parameter '%s', value '%s')", msg, d.Name, d.Value)
+		}
+		if d.ID != "" {
+			msg = fmt.Sprintf("%s (id '%s')", msg, d.ID)
+		}
+		return msg
+	}
 	if e.Payload != nil && !e.Payload.IsEmpty() {
 		return string(e.Payload.AsBytes())
 	} else {
@@ -107,6 +165,33 @@ func (e *ApiError) Error() string {
 	}
 }
 
+// ErrorDetails holds the fields found in IVCAP error replies. It covers
+// the various '...BadRequestResponseBody', '...InvalidParameterResponseBody',
+// '...NotFoundResponseBody' etc. types in 'pkg/service_types.go'.
+type ErrorDetails struct {
+	// Information message
+	Message string `json:"message,omitempty"`
+	// ID of involved, missing or already existing resource
+	ID string `json:"id,omitempty"`
+	// Name of the invalid parameter
+	Name string `json:"name,omitempty"`
+	// Value provided for the invalid parameter
+	Value string `json:"value,omitempty"`
+}
+
+// decodeErrorDetails returns the details of an IVCAP error reply, or nil
+// if `pyld` isn't one.
+func decodeErrorDetails(pyld Payload) *ErrorDetails {
+	if pyld == nil || pyld.IsEmpty() || !strings.Contains(pyld.ContentType(), "json") {
+		return nil
+	}
+	var d ErrorDetails
+	if err := json.Unmarshal(pyld.AsBytes(), &d); err != nil || d == (ErrorDetails{}) {
+		return nil
+	}
+	return &d
+}
+
 type ClientError struct {
 	AdapterError
 	err error
@@ -119,6 +204,11 @@ func (e *ClientError) Error() string {
 type restAdapter struct {
 	connCtxt *ConnectionCtxt
 	client   *http.Client
+	tokens   TokenSource
+	tokenMu  sync.Mutex // guards connCtxt.AccessToken, which is updated on refresh
+	retry    RetryPolicy
+	tracer   trace.Tracer
+	cache    *Cache
 }
 
 func (a *restAdapter) Head(ctxt context.Context, path string, headers *map[string]string, logger *log.Logger) (Payload, error) {
@@ -171,12 +261,30 @@ func (a *restAdapter) GetSSE(
 	onEvent func(*sse.Event),
 	headers *map[string]string,
 	logger *log.Logger,
-) error {
+) (err error) {
 	parsedURL, err := parseURL(path, a.connCtxt)
 	if err != nil {
 		return err
 	}
+	ctxt, span := a.tracer.Start(ctxt, "SSE "+parsedURL.Path, trace.WithAttributes(
+		attribute.String("url.full", RedactURL(parsedURL)),
+	))
+	defer func() { endSpan(span, err) }()
+	token, err := a.accessToken(ctxt, path)
+	if err != nil {
+		return err
+	}
 	client := sse.NewClient(parsedURL.String())
+	// share the transport (e.g. cassettes), but not the request timeout
+	client.Connection = &http.Client{Transport: a.client.Transport}
+	// stop reconnecting once 'ctxt' is done
+	client.ReconnectStrategy = sseBackoff.WithContext(sseBackoff.NewExponentialBackOff(), ctxt)
+	client.ReconnectNotify = func(err error, wait time.Duration) {
+		span.AddEvent("reconnect", trace.WithAttributes(
+			attribute.String("error", err.Error()),
+			attribute.String("wait", wait.String()),
+		))
+	}
 	if lastEventID != nil {
 		client.LastEventID.Store([]byte(*lastEventID))
 	}
@@ -185,10 +293,41 @@ func (a *restAdapter) GetSSE(
 			client.Headers[key] = value
 		}
 	}
-	if a.connCtxt.AccessToken != "" {
-		client.Headers["Authorization"] = "Bearer " + a.connCtxt.AccessToken
+	if token != "" {
+		client.Headers["Authorization"] = "Bearer " + token
+	}
+	refreshed := false
+	client.ResponseValidator = func(c *sse.Client, resp *http.Response) error {
+		if resp.StatusCode == http.StatusOK {
+			return nil
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusUnauthorized {
+			if !refreshed && a.tokens != nil && token != "" {
+				// try once more with a fresh token
+				refreshed = true
+				if t, err := a.tokens.Refresh(ctxt, token); err == nil {
+					token = t.AccessToken
+					a.setAccessToken(token)
+					c.Headers["Authorization"] = "Bearer " + token
+					return fmt.Errorf("access token rejected, reconnecting")
+				} else {
+					logger.Warn("while refreshing access token", log.Error(err))
+				}
+			}
+			return sseBackoff.Permanent(&UnauthorizedError{AdapterError{path}})
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return sseBackoff.Permanent(&ResourceNotFoundError{AdapterError{path}, nil})
+		}
+		return fmt.Errorf("could not connect to stream: %s", http.StatusText(resp.StatusCode))
+	}
+	err = client.SubscribeWithContext(ctxt, "", onEvent)
+	var perr *sseBackoff.PermanentError
+	if errors.As(err, &perr) {
+		return perr.Err
 	}
-	return client.Subscribe("", onEvent)
+	return err
 }
 
 func (a *restAdapter) SetUrl(url string) {
@@ -213,15 +352,26 @@ func (a *restAdapter) Connect(
 	headers *map[string]string,
 	respHandler ResponseHandler,
 	logger *log.Logger,
-) (Payload, error) {
+) (pyld Payload, err error) {
 	logger = logger.With(log.String("method", method), log.String("path", endpoint))
 	parsedURL, err := parseURL(endpoint, a.connCtxt)
 	if err != nil {
 		return nil, err
 	}
 	logger = logger.With(log.String("url", parsedURL.String()))
+	// covers all attempts, each of which gets its own span from the transport
+	ctxt, span := a.tracer.Start(ctxt, method+" "+parsedURL.Path, trace.WithAttributes(
+		attribute.String("http.request.method", method),
+		attribute.String("url.full", RedactURL(parsedURL)),
+	))
+	defer func() { endSpan(span, err) }()
+
+	token, err := a.accessToken(ctxt, endpoint)
+	if err != nil {
+		return nil, err
+	}
 
-	req, err := http.NewRequest(method, parsedURL.String(), body)
+	req, err := http.NewRequestWithContext(ctxt, method, parsedURL.String(), body)
 	if err != nil {
 		logger.Error("Creating http request", log.Error(err))
 		return nil, &ClientError{AdapterError{endpoint}, err}
@@ -238,8 +388,10 @@ func (a *restAdapter) Connect(
 	if length > 0 {
 		req.Header.Set("Content-Type", contentType)
 	}
-	req.Header.Set("Cache-Control", "no-cache")
-	if a.connCtxt.AccessToken != "" {
+	if a.cache == nil {
+		req.Header.Set("Cache-Control", "no-cache")
+	}
+	if token != "" {
 		// hide token in debug message below
 		req.Header.Set("Authorization", "Bearer ****")
 	}
@@ -257,6 +409,9 @@ func (a *restAdapter) Connect(
 			}
 		}
 	}
+	if a.retry.IdempotencyKeys && method == http.MethodPost && req.Header.Get(IdempotencyKeyHeader) == "" {
+		req.Header.Set(IdempotencyKeyHeader, uuid.NewString())
+	}
 	host := req.Header.Get("Host")
 	if host != "" {
 		req.Host = host
@@ -265,20 +420,89 @@ func (a *restAdapter) Connect(
 		a.client.Timeout = time.Second * time.Duration(a.connCtxt.TimeoutSec)
 	}
 	logger.Debug("calling api", log.Reflect("headers", req.Header))
-	if a.connCtxt.AccessToken != "" {
-		req.Header.Set("Authorization", "Bearer "+a.connCtxt.AccessToken)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	pyld, err = doWithRetry(a.client, req, a.retry, respHandler, endpoint, logger)
+	var unauthErr *UnauthorizedError
+	if err != nil && errors.As(err, &unauthErr) && a.tokens != nil && token != "" {
+		// The token may have been revoked or expired early. Get a fresh one
+		// and try once more, if we can replay the request body.
+		retryReq, ok := rewindRequest(req)
+		if !ok {
+			return nil, err
+		}
+		t, rerr := a.tokens.Refresh(ctxt, token)
+		if rerr != nil {
+			logger.Warn("while refreshing access token", log.Error(rerr))
+			return nil, err
+		}
+		a.setAccessToken(t.AccessToken)
+		retryReq.Header.Set("Authorization", "Bearer "+t.AccessToken)
+		logger.Debug("retrying with refreshed access token")
+		span.AddEvent("token refreshed")
+		return doWithRetry(a.client, retryReq, a.retry, respHandler, endpoint, logger)
+	}
+	return pyld, err
+}
+
+// accessToken returns the token to include in the next request, refreshing
+// it first if necessary.
+func (a *restAdapter) accessToken(ctxt context.Context, path string) (string, error) {
+	if a.tokens == nil {
+		a.tokenMu.Lock()
+		defer a.tokenMu.Unlock()
+		return a.connCtxt.AccessToken, nil
 	}
-	return doWithRetry(a.client, req, respHandler, endpoint, logger)
+	t, err := a.tokens.Token(ctxt)
+	if err != nil {
+		return "", &TokenSourceError{AdapterError{path}, err}
+	}
+	// keep it in sync for users of GetConnectionContext
+	a.setAccessToken(t.AccessToken)
+	return t.AccessToken, nil
+}
+
+// setAccessToken records `token` as the current one. Requests may be issued
+// concurrently (e.g. by MCP handlers), so all access needs to go through
+// `tokenMu`.
+func (a *restAdapter) setAccessToken(token string) {
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	a.connCtxt.AccessToken = token
 }
 
+// rewindRequest returns a copy of `req` with a fresh body, or false if
+// the body cannot be replayed.
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	r := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return r, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	r.Body = body
+	return r, true
+}
+
+// GetConnectionContext returns a snapshot of the connection context, as its
+// access token may be replaced by a concurrent refresh.
 func (a *restAdapter) GetConnectionContext() *ConnectionCtxt {
-	return a.connCtxt
+	a.tokenMu.Lock()
+	defer a.tokenMu.Unlock()
+	c := *a.connCtxt
+	return &c
 }
 
 func ProcessErrorResponse(resp *http.Response, path string, pyld Payload, logger *log.Logger) (err error) {
 	switch resp.StatusCode {
 	case http.StatusNotFound:
-		return &ResourceNotFoundError{AdapterError{path}}
+		return &ResourceNotFoundError{AdapterError{path}, decodeErrorDetails(pyld)}
 	case http.StatusUnauthorized:
 		return &UnauthorizedError{AdapterError{path}}
 	default:
@@ -287,72 +511,7 @@ func ProcessErrorResponse(resp *http.Response, path string, pyld Payload, logger
 			AdapterError: AdapterError{path},
 			StatusCode:   resp.StatusCode,
 			Payload:      pyld,
+			Details:      decodeErrorDetails(pyld),
 		}
 	}
 }
-
-const (
-	// default retry values for ivcap cli http req/res
-	defaultInitialInterval = 200 * time.Millisecond
-	defaultMaxInterval     = 60 * time.Second
-	defaultMaxElapsedTime  = 60 * time.Second
-)
-
-func doWithRetry(client *http.Client, req *http.Request, respHandler ResponseHandler, endpoint string, logger *log.Logger) (Payload, error) {
-	expBackoff := backoff.NewExponentialBackOff([]backoff.ExponentialBackOffOpts{
-		backoff.WithInitialInterval(defaultInitialInterval),
-		backoff.WithMaxInterval(defaultMaxInterval),
-		backoff.WithMaxElapsedTime(defaultMaxElapsedTime),
-	}...)
-
-	var (
-		respBody []byte
-		resp     *http.Response
-		err      error
-	)
-
-	e := backoff.Retry(func() error {
-		// #nosec G704 - URL is constructed from validated user configuration
-		resp, err = client.Do(req)
-		if err != nil {
-			return fmt.Errorf("failed to call http request: %w", err)
-		}
-		defer func() { _ = resp.Body.Close() }()
-
-		if respHandler != nil {
-			return respHandler(resp, endpoint, logger)
-		}
-		respBody, err = io.ReadAll(resp.Body)
-		if err != nil {
-			logger.Warn("Accessing response body failed.", log.Error(err))
-			return &ClientError{AdapterError{endpoint}, err}
-		}
-		logger.Debug("successful reply", log.Int("statusCode", resp.StatusCode),
-			log.Int("body-length", len(respBody)), log.Reflect("headers", resp.Header))
-
-		if resp.StatusCode >= 300 {
-			if len(respBody) > 0 {
-				logger = logger.With(log.ByteString("body", respBody))
-			}
-			e := ProcessErrorResponse(resp, endpoint, ToPayload(respBody, resp, logger), logger)
-			if isRetryableStatusCode(resp.StatusCode) {
-				return e
-			}
-			return backoff.Permanent(e)
-		}
-
-		return nil
-	}, expBackoff)
-	if e != nil {
-		return nil, fmt.Errorf("failed in retry http do request: %w", e)
-	}
-	return ToPayload(respBody, resp, logger), nil
-}
-
-func isRetryableStatusCode(statusCode int) bool {
-	return statusCode >= 500 ||
-		statusCode == http.StatusRequestTimeout ||
-		statusCode == http.StatusTooEarly ||
-		statusCode == http.StatusConflict ||
-		statusCode == http.StatusGone
-}
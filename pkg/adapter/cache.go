@@ -0,0 +1,252 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxCacheBodySize limits the size of replies kept in a cache. Larger
+// replies are passed through unchanged.
+const MaxCacheBodySize = 10 << 20
+
+// Cache keeps the replies to GET requests on disk. Cached replies are
+// returned as is while they are fresh according to their `Cache-Control:
+// max-age`, and are otherwise revalidated with `If-None-Match` if they
+// carry an `ETag`. Any other method invalidates the cached reply for its URL.
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a cache keeping its entries in directory `dir`, which
+// is created when needed.
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// WithCache serves GET requests through cache `c`. The cache always sits
+// in front of any other transport, so hits don't show up in traces.
+func WithCache(c *Cache) Option {
+	return func(adpr *restAdapter) {
+		adpr.cache = c
+	}
+}
+
+// RoundTripper returns a transport serving replies from the cache, and
+// passing all other requests on to `next` (nil for the default transport).
+func (c *Cache) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &cacheTransport{cache: c, next: next}
+}
+
+// Clear removes all entries of the cache.
+func (c *Cache) Clear() error {
+	return os.RemoveAll(c.dir)
+}
+
+type cacheEntry struct {
+	URL        string      `json:"url"`
+	StatusCode int         `json:"status-code"`
+	Headers    http.Header `json:"headers"`
+	Body       []byte      `json:"body"`
+	StoredAt   time.Time   `json:"stored-at"`
+}
+
+func (e *cacheEntry) isFresh(now time.Time) bool {
+	cc := parseCacheControl(e.Headers.Get("Cache-Control"))
+	if _, ok := cc["no-cache"]; ok {
+		return false
+	}
+	maxAge, err := strconv.Atoi(cc["max-age"])
+	return err == nil && now.Sub(e.StoredAt) < time.Duration(maxAge)*time.Second
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+func (c *Cache) path(url string) string {
+	h := sha256.Sum256([]byte(url))
+	return filepath.Join(c.dir, hex.EncodeToString(h[:])+".json")
+}
+
+func (c *Cache) load(url string) *cacheEntry {
+	data, err := os.ReadFile(c.path(url))
+	if err != nil {
+		return nil
+	}
+	var e cacheEntry
+	if err = json.Unmarshal(data, &e); err != nil || e.URL != url {
+		return nil
+	}
+	return &e
+}
+
+func (c *Cache) store(e *cacheEntry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(c.dir, 0700); err != nil {
+		return err
+	}
+	// write to a temporary file first, so concurrent readers never see a partial entry
+	f, err := os.CreateTemp(c.dir, "entry-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), c.path(e.URL))
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+func (c *Cache) remove(url string) {
+	_ = os.Remove(c.path(url))
+}
+
+type cacheTransport struct {
+	cache *Cache
+	next  http.RoundTripper
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.String()
+	if req.Method != http.MethodGet {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.cache.remove(url)
+		}
+		return resp, err
+	}
+
+	entry := t.cache.load(url)
+	_, revalidate := parseCacheControl(req.Header.Get("Cache-Control"))["no-cache"]
+	if entry != nil && !revalidate && entry.isFresh(time.Now()) {
+		return entry.response(req), nil
+	}
+	if entry != nil && entry.Headers.Get("ETag") != "" && req.Header.Get("If-None-Match") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("If-None-Match", entry.Headers.Get("ETag"))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		_ = resp.Body.Close()
+		for _, h := range []string{"Cache-Control", "Date", "ETag", "Expires"} {
+			if v := resp.Header.Get(h); v != "" {
+				entry.Headers.Set(h, v)
+			}
+		}
+		entry.StoredAt = time.Now()
+		_ = t.cache.store(entry)
+		return entry.response(req), nil
+	}
+	if !isCacheable(resp) {
+		if resp.StatusCode < 400 {
+			t.cache.remove(url)
+		}
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, MaxCacheBodySize+1))
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > MaxCacheBodySize {
+		// too large, hand on the rest without caching
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	_ = t.cache.store(&cacheEntry{
+		URL:        url,
+		StatusCode: resp.StatusCode,
+		Headers:    resp.Header.Clone(),
+		Body:       body,
+		StoredAt:   time.Now(),
+	})
+	return resp, nil
+}
+
+// isCacheable returns true if `resp` can be reused later, either because
+// it can be revalidated or because the server declared it fresh for a while.
+func isCacheable(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK || resp.ContentLength > MaxCacheBodySize {
+		return false
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return false
+	}
+	cc := parseCacheControl(resp.Header.Get("Cache-Control"))
+	if _, ok := cc["no-store"]; ok {
+		return false
+	}
+	_, hasMaxAge := cc["max-age"]
+	return resp.Header.Get("ETag") != "" || hasMaxAge
+}
+
+// parseCacheControl returns the directives of a `Cache-Control` header
+// with their (unquoted) values.
+func parseCacheControl(value string) map[string]string {
+	cc := map[string]string{}
+	for _, d := range strings.Split(value, ",") {
+		d = strings.TrimSpace(d)
+		if d == "" {
+			continue
+		}
+		name, val, _ := strings.Cut(d, "=")
+		cc[strings.ToLower(strings.TrimSpace(name))] = strings.Trim(strings.TrimSpace(val), `"`)
+	}
+	return cc
+}
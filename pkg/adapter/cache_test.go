@@ -0,0 +1,125 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	log "go.uber.org/zap"
+)
+
+func TestCache_RevalidatesWithETag(t *testing.T) {
+	var requests, notModified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"name":"s1"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cache := NewCache(filepath.Join(t.TempDir(), "ctxt"))
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithCache(cache))
+	ctxt := context.Background()
+	logger := log.NewNop()
+
+	for range 3 {
+		pyld, err := adpt.Get(ctxt, "/1/services2/s1", logger)
+		if err != nil {
+			t.Fatalf("unexpected error - %v", err)
+		}
+		if string(pyld.AsBytes()) != `{"name":"s1"}` || pyld.StatusCode() != http.StatusOK {
+			t.Fatalf("unexpected reply %d '%s'", pyld.StatusCode(), pyld.AsBytes())
+		}
+	}
+	if requests != 3 || notModified != 2 {
+		t.Fatalf("expected two revalidations, got %d requests with %d not modified", requests, notModified)
+	}
+
+	// any other method invalidates the entry
+	if _, err := adpt.Delete(ctxt, "/1/services2/s1", logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if _, err := adpt.Get(ctxt, "/1/services2/s1", logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if notModified != 2 {
+		t.Errorf("expected entry to be removed after DELETE")
+	}
+}
+
+func TestCache_ServesFreshReplies(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/fresh" {
+			w.Header().Set("Cache-Control", "max-age=60")
+		} else {
+			w.Header().Set("Cache-Control", "no-store")
+			w.Header().Set("ETag", `"v1"`)
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	cache := NewCache(filepath.Join(t.TempDir(), "ctxt"))
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithCache(cache))
+	for range 2 {
+		for _, path := range []string{"/fresh", "/no-store"} {
+			if _, err := adpt.Get(context.Background(), path, log.NewNop()); err != nil {
+				t.Fatalf("unexpected error - %v", err)
+			}
+		}
+	}
+	if requests != 3 {
+		t.Errorf("expected only the 'no-store' reply to be fetched again, got %d requests", requests)
+	}
+
+	if err := cache.Clear(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := adpt.Get(context.Background(), "/fresh", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if requests != 4 {
+		t.Errorf("expected cleared entry to be fetched again")
+	}
+}
+
+func TestParseCacheControl(t *testing.T) {
+	cc := parseCacheControl(`private, Max-Age="30", no-cache`)
+	if cc["max-age"] != "30" {
+		t.Errorf("unexpected max-age '%s'", cc["max-age"])
+	}
+	for _, d := range []string{"private", "no-cache"} {
+		if _, ok := cc[d]; !ok {
+			t.Errorf("missing directive '%s' in %v", d, cc)
+		}
+	}
+}
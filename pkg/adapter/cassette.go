@@ -0,0 +1,286 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const CassetteVersion = 1
+
+// ErrCassetteMiss is returned when replaying a cassette which doesn't
+// contain a (not yet used) recording for a request.
+var ErrCassetteMiss = errors.New("no recorded interaction for request")
+
+// Cassette holds recorded HTTP interactions. It either records all traffic
+// passing through its RoundTripper to a file, or replays a previously
+// recorded file without any network access. Credentials are redacted
+// before anything is written to disk.
+type Cassette struct {
+	Version      int            `json:"version"`
+	URL          string         `json:"url,omitempty"` // deployment the cassette was recorded against
+	RecordedAt   time.Time      `json:"recorded-at"`
+	Interactions []*Interaction `json:"interactions"`
+
+	path      string
+	replaying bool
+	used      []bool
+	mu        sync.Mutex
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	RecordedBody
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status-code"`
+	Headers    http.Header `json:"headers,omitempty"`
+	RecordedBody
+}
+
+// RecordedBody stores a body as plain text, or base64 encoded if it
+// isn't valid UTF-8.
+type RecordedBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 string `json:"body-base64,omitempty"`
+}
+
+func (b *RecordedBody) set(data []byte) {
+	if utf8.Valid(data) {
+		b.Body = string(data)
+	} else {
+		b.BodyBase64 = base64.StdEncoding.EncodeToString(data)
+	}
+}
+
+func (b *RecordedBody) bytes() []byte {
+	if b.BodyBase64 != "" {
+		if data, err := base64.StdEncoding.DecodeString(b.BodyBase64); err == nil {
+			return data
+		}
+	}
+	return []byte(b.Body)
+}
+
+// NewCassette returns a cassette recording to `path`. The file is rewritten
+// after each completed interaction, so it survives the process exiting early.
+func NewCassette(path string, url string) *Cassette {
+	return &Cassette{
+		Version:    CassetteVersion,
+		URL:        url,
+		RecordedAt: time.Now(),
+		path:       path,
+	}
+}
+
+// LoadCassette loads a previously recorded cassette for replay.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("cannot parse cassette '%s' - %w", path, err)
+	}
+	if c.Version != CassetteVersion {
+		return nil, fmt.Errorf("unsupported cassette version '%d'", c.Version)
+	}
+	c.path = path
+	c.replaying = true
+	c.used = make([]bool, len(c.Interactions))
+	return &c, nil
+}
+
+// IsReplaying returns true if the cassette is serving recorded responses.
+func (c *Cassette) IsReplaying() bool {
+	return c.replaying
+}
+
+// RoundTripper returns a transport which either records the traffic passed
+// on to `next` (nil for the default transport), or replays it.
+func (c *Cassette) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &cassetteTransport{cassette: c, next: next}
+}
+
+// Save writes the cassette to its file.
+func (c *Cassette) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+// must be called while holding 'c.mu'
+func (c *Cassette) save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, data, 0600)
+}
+
+func (c *Cassette) add(i *Interaction) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Interactions = append(c.Interactions, i)
+	_ = c.save() // best effort, also called again at the end
+}
+
+// finds the first unused interaction matching method and request URI. The
+// deployment host is ignored so cassettes can be replayed against any context.
+func (c *Cassette) next(req *http.Request) (*Interaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	uri := req.URL.RequestURI()
+	for idx, i := range c.Interactions {
+		if c.used[idx] || i.Request.Method != req.Method {
+			continue
+		}
+		if rurl, err := req.URL.Parse(i.Request.URL); err != nil || rurl.RequestURI() != uri {
+			continue
+		}
+		c.used[idx] = true
+		return i, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrCassetteMiss, req.Method, uri)
+}
+
+type cassetteTransport struct {
+	cassette *Cassette
+	next     http.RoundTripper
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.cassette.replaying {
+		return t.replay(req)
+	}
+	return t.record(req)
+}
+
+func (t *cassetteTransport) replay(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+	i, err := t.cassette.next(req)
+	if err != nil {
+		return nil, err
+	}
+	body := i.Response.bytes()
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", i.Response.StatusCode, http.StatusText(i.Response.StatusCode)),
+		StatusCode:    i.Response.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        i.Response.Headers.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request) (*http.Response, error) {
+	i := &Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     RedactURL(req.URL),
+			Headers: RedactHeaders(req.Header),
+		},
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		data, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		i.Request.set(RedactBody(req.Header.Get("Content-Type"), data))
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(data))
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	i.Response.StatusCode = resp.StatusCode
+	i.Response.Headers = RedactHeaders(resp.Header)
+	// Capture the body while the caller reads it, so streams (SSE) are
+	// recorded as far as they got consumed.
+	resp.Body = &recordingBody{
+		ReadCloser: resp.Body,
+		onDone: func(data []byte) {
+			i.Response.set(RedactBody(resp.Header.Get("Content-Type"), data))
+			t.cassette.add(i)
+		},
+	}
+	return resp, nil
+}
+
+type recordingBody struct {
+	io.ReadCloser
+	buf       bytes.Buffer
+	limit     int // max. number of bytes to capture, 0 for all
+	truncated bool
+	onDone    func(data []byte)
+	once      sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.capture(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *recordingBody) capture(p []byte) {
+	if b.limit > 0 && b.buf.Len()+len(p) > b.limit {
+		p = p[:max(0, b.limit-b.buf.Len())]
+		b.truncated = true
+	}
+	b.buf.Write(p)
+}
+
+func (b *recordingBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *recordingBody) finish() {
+	b.once.Do(func() { b.onDone(b.buf.Bytes()) })
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r3labs/sse/v2"
+	log "go.uber.org/zap"
+)
+
+func TestCassette_RecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"secret-at","expires_in":60}`))
+		case "/1/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("id: 1\ndata: {\"a\":1}\n\nid: 2\ndata: {\"a\":2}\n\n"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+		}
+	}))
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec := NewCassette(path, srv.URL)
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, AccessToken: "my-token", TimeoutSec: 5}), WithCassette(rec))
+	logger := log.NewNop()
+	ctxt := context.Background()
+
+	if _, err := adpt.Get(ctxt, "/1/services2?limit=2", logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	form := neturl.Values{"refresh_token": {"secret-rt"}}
+	if _, err := adpt.PostForm(ctxt, "/1/token", form, nil, logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	var events []string
+	onEvent := func(e *sse.Event) { events = append(events, string(e.Data)) }
+	if err := adpt.GetSSE(ctxt, "/1/events", nil, onEvent, nil, logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	srv.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cassette not written - %v", err)
+	}
+	for _, secret := range []string{"my-token", "secret-rt", "secret-at"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("cassette contains unredacted secret '%s'", secret)
+		}
+	}
+
+	// replay without a server
+	play, err := LoadCassette(path)
+	if err != nil {
+		t.Fatalf("cannot load cassette - %v", err)
+	}
+	adpt = RestAdapter(WithConnContext(&ConnectionCtxt{URL: "http://replay.local", TimeoutSec: 5}), WithCassette(play))
+	pyld, err := adpt.Get(ctxt, "/1/services2?limit=2", logger)
+	if err != nil {
+		t.Fatalf("unexpected replay error - %v", err)
+	}
+	if string(pyld.AsBytes()) != `{"path":"/1/services2"}` {
+		t.Fatalf("unexpected replayed body '%s'", pyld.AsBytes())
+	}
+	events = nil
+	if err := adpt.GetSSE(ctxt, "/1/events", nil, onEvent, nil, logger); err != nil {
+		t.Fatalf("unexpected replay error - %v", err)
+	}
+	if len(events) != 2 || events[1] != `{"a":2}` {
+		t.Fatalf("unexpected replayed events %v", events)
+	}
+	if _, err = adpt.Get(ctxt, "/1/services2?limit=2", logger); !errors.Is(err, ErrCassetteMiss) {
+		t.Fatalf("expected ErrCassetteMiss for exhausted recording, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	log "go.uber.org/zap"
+)
+
+func replyWith(t *testing.T, statusCode int, contentType string, body string) Adapter {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(RetryPolicy{}))
+}
+
+func TestConnect_DecodesInvalidParameter(t *testing.T) {
+	adpt := replyWith(t, http.StatusBadRequest, "application/json",
+		`{"message":"must be a URN","name":"service-id","value":"foo"}`)
+	_, err := adpt.Get(context.Background(), "/1/services2/foo", log.NewNop())
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.Details == nil {
+		t.Fatalf("expected ApiError with details, got %v", err)
+	}
+	if d := apiErr.Details; d.Message != "must be a URN" || d.Name != "service-id" || d.Value != "foo" {
+		t.Fatalf("unexpected details %+v", d)
+	}
+	if msg := apiErr.Error(); msg != "400: must be a URN (parameter 'service-id', value 'foo')" {
+		t.Fatalf("unexpected message '%s'", msg)
+	}
+}
+
+func TestConnect_DecodesNotFound(t *testing.T) {
+	adpt := replyWith(t, http.StatusNotFound, "application/json", `{"id":"urn:ivcap:job:123","message":"not found"}`)
+	_, err := adpt.Get(context.Background(), "/1/jobs/123", log.NewNop())
+	var notFound *ResourceNotFoundError
+	if !errors.As(err, &notFound) || notFound.Details == nil || notFound.Details.ID != "urn:ivcap:job:123" {
+		t.Fatalf("expected ResourceNotFoundError with ID, got %v", err)
+	}
+}
+
+func TestConnect_KeepsNonIvcapErrorBody(t *testing.T) {
+	adpt := replyWith(t, http.StatusForbidden, "text/plain", "go away")
+	_, err := adpt.Get(context.Background(), "/1/secrets", log.NewNop())
+	var apiErr *ApiError
+	if !errors.As(err, &apiErr) || apiErr.Details != nil || apiErr.Error() != "go away" {
+		t.Fatalf("expected plain ApiError, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"encoding/json"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+// Redacted replaces any secret value in captured traffic
+const Redacted = "REDACTED"
+
+// headers which should never be written to disk
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// names of form, query and JSON fields carrying secrets
+var sensitiveFields = map[string]bool{
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"client_secret": true,
+	"device_code":   true,
+	"code":          true,
+	"code_verifier": true,
+	"password":      true,
+	"secret-value":  true,
+}
+
+// RedactHeaders returns a copy of `h` with all credentials removed.
+func RedactHeaders(h http.Header) http.Header {
+	out := h.Clone()
+	for k := range out {
+		if sensitiveHeaders[http.CanonicalHeaderKey(k)] {
+			out[k] = []string{Redacted}
+		}
+	}
+	return out
+}
+
+// RedactURL returns `u` with the values of all sensitive query parameters removed.
+func RedactURL(u *neturl.URL) string {
+	q := u.Query()
+	if !redactValues(q) {
+		return u.String()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.String()
+}
+
+// RedactBody removes secrets from form encoded or JSON bodies. Any other
+// content is returned unchanged.
+func RedactBody(contentType string, body []byte) []byte {
+	b, _ := redactBody(contentType, body)
+	return b
+}
+
+// Like 'RedactBody', but also returns false if `body` should have been
+// redacted but cannot be parsed, e.g. as it got truncated.
+func redactBody(contentType string, body []byte) ([]byte, bool) {
+	switch {
+	case strings.HasPrefix(contentType, "application/x-www-form-urlencoded"):
+		q, err := neturl.ParseQuery(string(body))
+		if err != nil {
+			return body, false
+		}
+		if redactValues(q) {
+			return []byte(q.Encode()), true
+		}
+	case strings.Contains(contentType, "json"):
+		var f any
+		if err := json.Unmarshal(body, &f); err != nil {
+			return body, false
+		}
+		if redactJSON(f) {
+			if b, err := json.Marshal(f); err == nil {
+				return b, true
+			}
+		}
+	}
+	return body, true
+}
+
+func redactValues(q neturl.Values) (changed bool) {
+	for k := range q {
+		if sensitiveFields[strings.ToLower(k)] {
+			q[k] = []string{Redacted}
+			changed = true
+		}
+	}
+	return
+}
+
+func redactJSON(f any) (changed bool) {
+	switch v := f.(type) {
+	case map[string]any:
+		for k, el := range v {
+			if sensitiveFields[strings.ToLower(k)] {
+				v[k] = Redacted
+				changed = true
+			} else if redactJSON(el) {
+				changed = true
+			}
+		}
+	case []any:
+		for _, el := range v {
+			if redactJSON(el) {
+				changed = true
+			}
+		}
+	}
+	return
+}
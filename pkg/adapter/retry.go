@@ -0,0 +1,242 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	log "go.uber.org/zap"
+)
+
+// IdempotencyKeyHeader marks a request as safe to replay. The server is
+// expected to process requests carrying the same key only once.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+const (
+	// default retry values for ivcap cli http req/res
+	DefaultMaxRetries      = 5
+	defaultInitialInterval = 200 * time.Millisecond
+	defaultMaxInterval     = 60 * time.Second
+	defaultMaxElapsedTime  = 60 * time.Second
+)
+
+// RetryPolicy controls which failed requests are retried, and how often.
+//
+// Only requests with safe or idempotent methods (GET, HEAD, OPTIONS, TRACE,
+// PUT, DELETE) are retried after reaching the server. POST and PATCH
+// requests are only retried if they carry an `Idempotency-Key` header, or
+// if they never left the client because the connection could not be
+// established.
+type RetryPolicy struct {
+	// MaxRetries is the number of attempts after the first one. Zero disables retries.
+	MaxRetries      int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// MaxElapsedTime bounds the total time spent retrying, including any
+	// waits requested by the server through 'Retry-After'
+	MaxElapsedTime time.Duration
+	// IdempotencyKeys adds a random `Idempotency-Key` header to every POST
+	// request without one, making them eligible for retries. Only enable
+	// this for deployments honoring the header.
+	IdempotencyKeys bool
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries:      DefaultMaxRetries,
+		InitialInterval: defaultInitialInterval,
+		MaxInterval:     defaultMaxInterval,
+		MaxElapsedTime:  defaultMaxElapsedTime,
+	}
+}
+
+// WithRetryPolicy replaces the DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(adpr *restAdapter) {
+		adpr.retry = policy
+	}
+}
+
+func (p RetryPolicy) backOff() backoff.BackOff {
+	b := backoff.NewExponentialBackOff(
+		backoff.WithInitialInterval(p.InitialInterval),
+		backoff.WithMaxInterval(p.MaxInterval),
+		backoff.WithMaxElapsedTime(p.MaxElapsedTime),
+	)
+	return backoff.WithMaxRetries(b, uint64(max(p.MaxRetries, 0)))
+}
+
+// doWithRetry sends `req` and retries it according to `policy`. The body of
+// `req` is re-created for every attempt, so requests with bodies which
+// cannot be replayed are only tried once.
+func doWithRetry(
+	client *http.Client,
+	req *http.Request,
+	policy RetryPolicy,
+	respHandler ResponseHandler,
+	endpoint string,
+	logger *log.Logger,
+) (Payload, error) {
+	delays := policy.backOff()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		// keep a copy before the body gets consumed
+		next, canReplay := rewindRequest(req)
+		pyld, retryAfter, err := doRequest(client, req, respHandler, endpoint, logger)
+		if err == nil {
+			return pyld, nil
+		}
+		var permanent *backoff.PermanentError
+		if errors.As(err, &permanent) {
+			return nil, fmt.Errorf("failed in retry http do request: %w", permanent.Err)
+		}
+		if !canReplay {
+			logger.Debug("cannot replay request body, not retrying", log.Error(err))
+			return nil, fmt.Errorf("failed in retry http do request: %w", err)
+		}
+		wait := delays.NextBackOff()
+		if wait == backoff.Stop {
+			return nil, fmt.Errorf("failed in retry http do request: %w", err)
+		}
+		if retryAfter > 0 {
+			if policy.MaxElapsedTime > 0 && time.Since(start)+retryAfter > policy.MaxElapsedTime {
+				logger.Debug("server asks to retry too late", log.Duration("retry-after", retryAfter))
+				return nil, fmt.Errorf("failed in retry http do request: %w", err)
+			}
+			wait = retryAfter
+		}
+		logger.Debug("retrying request", log.Int("attempt", attempt), log.Duration("wait", wait), log.Error(err))
+		trace.SpanFromContext(req.Context()).AddEvent("retry", trace.WithAttributes(
+			attribute.Int("attempt", attempt),
+			attribute.String("wait", wait.String()),
+			attribute.String("error", err.Error()),
+		))
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, fmt.Errorf("failed in retry http do request: %w", req.Context().Err())
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+// doRequest sends `req` once. Errors which should not be retried are
+// wrapped with `backoff.Permanent`. If the server asks for a specific delay
+// before retrying, it is returned as well.
+func doRequest(
+	client *http.Client,
+	req *http.Request,
+	respHandler ResponseHandler,
+	endpoint string,
+	logger *log.Logger,
+) (Payload, time.Duration, error) {
+	// #nosec G704 - URL is constructed from validated user configuration
+	resp, err := client.Do(req)
+	if err != nil {
+		err = fmt.Errorf("failed to call http request: %w", err)
+		if errors.Is(err, ErrCassetteMiss) || req.Context().Err() != nil {
+			return nil, 0, backoff.Permanent(err)
+		}
+		if !isReplayable(req) && !isDialError(err) {
+			// the server may have acted on it already
+			return nil, 0, backoff.Permanent(err)
+		}
+		return nil, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if respHandler != nil {
+		if err = respHandler(resp, endpoint, logger); err != nil && !isReplayable(req) {
+			return nil, 0, backoff.Permanent(err)
+		}
+		return nil, 0, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Warn("Accessing response body failed.", log.Error(err))
+		return nil, 0, &ClientError{AdapterError{endpoint}, err}
+	}
+	logger.Debug("successful reply", log.Int("statusCode", resp.StatusCode),
+		log.Int("body-length", len(respBody)), log.Reflect("headers", resp.Header))
+
+	if resp.StatusCode >= 300 {
+		if len(respBody) > 0 {
+			logger = logger.With(log.ByteString("body", respBody))
+		}
+		e := ProcessErrorResponse(resp, endpoint, ToPayload(respBody, resp, logger), logger)
+		if !isRetryableStatusCode(resp.StatusCode) || !isReplayable(req) {
+			return nil, 0, backoff.Permanent(e)
+		}
+		var retryAfter time.Duration
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return nil, retryAfter, e
+	}
+	return ToPayload(respBody, resp, logger), 0, nil
+}
+
+func isRetryableStatusCode(statusCode int) bool {
+	return statusCode >= 500 ||
+		statusCode == http.StatusRequestTimeout ||
+		statusCode == http.StatusTooEarly ||
+		statusCode == http.StatusTooManyRequests ||
+		statusCode == http.StatusConflict ||
+		statusCode == http.StatusGone
+}
+
+// isReplayable returns true if sending `req` more than once has the same
+// effect as sending it once.
+func isReplayable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return req.Header.Get(IdempotencyKeyHeader) != ""
+	}
+}
+
+// isDialError returns true if the request failed before reaching the server.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// parseRetryAfter returns the delay requested by a 'Retry-After' header,
+// which is either a number of seconds or an HTTP date. Returns 0 if none.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(max(secs, 0)) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	log "go.uber.org/zap"
+)
+
+func fastRetries() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.InitialInterval = time.Millisecond
+	p.MaxInterval = 10 * time.Millisecond
+	p.MaxElapsedTime = 5 * time.Second
+	return p
+}
+
+// failingServer replies with `status` to the first `failures` requests.
+func failingServer(t *testing.T, failures int, status int, retryAfter string) (*httptest.Server, *[]*http.Request, *[]string) {
+	var reqs []*http.Request
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		reqs = append(reqs, r)
+		bodies = append(bodies, string(b))
+		if len(reqs) <= failures {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(status)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &reqs, &bodies
+}
+
+func TestConnect_ReplaysBodyOnRetry(t *testing.T) {
+	srv, reqs, bodies := failingServer(t, 2, http.StatusBadGateway, "")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(fastRetries()))
+	_, err := adpt.Put(context.Background(), "/1/things/1", strings.NewReader(`{"a":1}`), 7, nil, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(*reqs) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(*reqs))
+	}
+	for _, b := range *bodies {
+		if b != `{"a":1}` {
+			t.Fatalf("expected body to be replayed, got '%s'", b)
+		}
+	}
+}
+
+func TestConnect_DoesNotRetryPost(t *testing.T) {
+	srv, reqs, _ := failingServer(t, 1, http.StatusServiceUnavailable, "")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(fastRetries()))
+	_, err := adpt.Post(context.Background(), "/1/things", strings.NewReader(`{}`), 2, nil, log.NewNop())
+	if err == nil || len(*reqs) != 1 {
+		t.Fatalf("expected POST to fail after a single attempt, got %d attempts (%v)", len(*reqs), err)
+	}
+}
+
+func TestConnect_RetriesPostWithIdempotencyKey(t *testing.T) {
+	srv, reqs, _ := failingServer(t, 1, http.StatusServiceUnavailable, "")
+	policy := fastRetries()
+	policy.IdempotencyKeys = true
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(policy))
+	_, err := adpt.Post(context.Background(), "/1/things", strings.NewReader(`{}`), 2, nil, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(*reqs) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(*reqs))
+	}
+	key := (*reqs)[0].Header.Get(IdempotencyKeyHeader)
+	if key == "" || (*reqs)[1].Header.Get(IdempotencyKeyHeader) != key {
+		t.Fatalf("expected the same idempotency key on both attempts")
+	}
+}
+
+func TestConnect_HonorsRetryAfter(t *testing.T) {
+	srv, reqs, _ := failingServer(t, 1, http.StatusTooManyRequests, "1")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(fastRetries()))
+	start := time.Now()
+	if _, err := adpt.Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(*reqs) != 2 || time.Since(start) < time.Second {
+		t.Fatalf("expected a single retry after 1s, got %d attempts after %s", len(*reqs), time.Since(start))
+	}
+}
+
+func TestConnect_GivesUpIfRetryAfterTooLate(t *testing.T) {
+	srv, reqs, _ := failingServer(t, 1, http.StatusServiceUnavailable, "3600")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(fastRetries()))
+	if _, err := adpt.Get(context.Background(), "/1/things", log.NewNop()); err == nil || len(*reqs) != 1 {
+		t.Fatalf("expected to give up after one attempt, got %d attempts (%v)", len(*reqs), err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	if d := parseRetryAfter("120", now); d != 2*time.Minute {
+		t.Fatalf("expected 2m, got %s", d)
+	}
+	if d := parseRetryAfter(now.Add(30*time.Second).Format(http.TimeFormat), now); d != 30*time.Second {
+		t.Fatalf("expected 30s, got %s", d)
+	}
+	if d := parseRetryAfter("soon", now); d != 0 {
+		t.Fatalf("expected no delay for invalid value, got %s", d)
+	}
+}
+
+func TestConnect_StopsWhenCancelled(t *testing.T) {
+	srv, reqs, _ := failingServer(t, 100, http.StatusServiceUnavailable, "2")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithRetryPolicy(fastRetries()))
+	ctxt, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := adpt.Get(ctxt, "/1/things", log.NewNop())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+	if len(*reqs) != 1 || time.Since(start) > time.Second {
+		t.Fatalf("expected to stop waiting for retry, got %d attempts after %s", len(*reqs), time.Since(start))
+	}
+}
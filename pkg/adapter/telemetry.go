@@ -0,0 +1,159 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName identifies the spans created by the adapter
+const TracerName = "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+
+// TelemetryConfig selects where spans are exported to. Spans are created,
+// and `traceparent` headers sent, even if neither is set.
+type TelemetryConfig struct {
+	// OTLPEndpoint is the URL of an OTLP/HTTP collector, such as
+	// 'http://localhost:4318'. If empty, the standard OTEL_EXPORTER_OTLP_*
+	// environment variables are used, if set.
+	OTLPEndpoint string
+	// File receives one JSON object per finished span
+	File string
+	// ServiceName and ServiceVersion describe the process in exported spans
+	ServiceName    string
+	ServiceVersion string
+}
+
+// Telemetry creates OpenTelemetry spans for the adapter's calls and the
+// individual HTTP requests they result in (including retries and SSE
+// reconnects), and propagates them to the deployment as W3C `traceparent`
+// headers.
+type Telemetry struct {
+	provider *sdktrace.TracerProvider
+	file     *os.File
+}
+
+// NewTelemetry returns telemetry exporting spans as configured in `cfg`.
+// Call Shutdown before the process exits to flush all pending spans.
+func NewTelemetry(cfg *TelemetryConfig) (*Telemetry, error) {
+	t := &Telemetry{}
+	opts := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", cfg.ServiceName),
+			attribute.String("service.version", cfg.ServiceVersion),
+		)),
+	}
+	if cfg.OTLPEndpoint != "" || otlpEnvConfigured() {
+		var eopts []otlptracehttp.Option
+		if cfg.OTLPEndpoint != "" {
+			eopts = append(eopts, otlptracehttp.WithEndpointURL(cfg.OTLPEndpoint))
+		}
+		exp, err := otlptracehttp.New(context.Background(), eopts...)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+	if cfg.File != "" {
+		f, err := os.OpenFile(filepath.Clean(cfg.File), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			return nil, err
+		}
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		t.file = f
+		opts = append(opts, sdktrace.WithBatcher(exp))
+	}
+	t.provider = sdktrace.NewTracerProvider(opts...)
+	return t, nil
+}
+
+func otlpEnvConfigured() bool {
+	return os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// WithTelemetry creates spans for all calls of the adapter through `t`. It
+// needs to come after any `WithHttpClient`, `WithTransport`, `WithCassette`
+// or `WithTrace` option, so the `traceparent` header is recorded as well.
+func WithTelemetry(t *Telemetry) Option {
+	return func(adpr *restAdapter) {
+		adpr.tracer = t.Tracer()
+		adpr.client.Transport = t.RoundTripper(adpr.client.Transport)
+	}
+}
+
+// Tracer returns the tracer for spans created by the adapter.
+func (t *Telemetry) Tracer() trace.Tracer {
+	return t.provider.Tracer(TracerName)
+}
+
+// TracerProvider returns the provider of all tracers, e.g. to create
+// spans for a command.
+func (t *Telemetry) TracerProvider() trace.TracerProvider {
+	return t.provider
+}
+
+// RoundTripper returns a transport creating a span for each request passed
+// on to `next` (nil for the default transport), and adding its
+// `traceparent` header.
+func (t *Telemetry) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(next,
+		otelhttp.WithTracerProvider(t.provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+	)
+}
+
+// Shutdown exports all pending spans and closes the exporters.
+func (t *Telemetry) Shutdown(ctxt context.Context) error {
+	err := t.provider.Shutdown(ctxt)
+	if t.file != nil {
+		err = errors.Join(err, t.file.Close())
+	}
+	return err
+}
+
+// noopTracer is used by adapters without telemetry. It still passes on
+// the span context of its caller.
+var noopTracer = noop.NewTracerProvider().Tracer(TracerName)
+
+// endSpan marks `span` as failed if `err` is set, and ends it.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
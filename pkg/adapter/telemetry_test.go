@@ -0,0 +1,141 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	log "go.uber.org/zap"
+)
+
+func TestTelemetry_SpansAndTraceparent(t *testing.T) {
+	var calls atomic.Int32
+	var traceparents []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparents = append(traceparents, r.Header.Get("traceparent"))
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	exp := tracetest.NewInMemoryExporter()
+	tel := &Telemetry{provider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))}
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}),
+		WithRetryPolicy(RetryPolicy{MaxRetries: 1, InitialInterval: time.Millisecond}), WithTelemetry(tel))
+
+	ctxt, cmdSpan := tel.TracerProvider().Tracer("test").Start(context.Background(), "ivcap test")
+	if _, err := adpt.Get(ctxt, "/1/things?limit=2", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	cmdSpan.End()
+
+	traceID := cmdSpan.SpanContext().TraceID().String()
+	if len(traceparents) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(traceparents))
+	}
+	for _, tp := range traceparents {
+		if !strings.HasPrefix(tp, "00-"+traceID+"-") {
+			t.Errorf("expected traceparent for trace '%s', got '%s'", traceID, tp)
+		}
+	}
+	if traceparents[0] == traceparents[1] {
+		t.Errorf("expected each attempt to have its own span")
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 4 {
+		t.Fatalf("expected 4 spans, got %d", len(spans))
+	}
+	var call tracetest.SpanStub
+	for _, s := range spans {
+		if s.Name == "GET /1/things" {
+			call = s
+		}
+	}
+	if call.Parent.SpanID() != cmdSpan.SpanContext().SpanID() {
+		t.Fatalf("expected call span to be a child of the command span, got %+v", call)
+	}
+	if len(call.Events) != 1 || call.Events[0].Name != "retry" {
+		t.Errorf("expected a single retry event, got %+v", call.Events)
+	}
+	attempts := 0
+	for _, s := range spans {
+		if s.Parent.SpanID() == call.SpanContext.SpanID() {
+			attempts++
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempt spans, got %d", attempts)
+	}
+}
+
+func TestTelemetry_WritesFile(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	tel, err := NewTelemetry(&TelemetryConfig{File: path, ServiceName: "ivcap-cli", ServiceVersion: "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}),
+		WithRetryPolicy(RetryPolicy{}), WithTelemetry(tel))
+	if _, err := adpt.Get(context.Background(), "/1/missing", log.NewNop()); err == nil {
+		t.Fatal("expected request to fail")
+	}
+	if err := tel.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("spans not written - %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	names := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var span struct {
+			Name   string
+			Status struct{ Code string }
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &span); err != nil {
+			t.Fatalf("cannot parse span - %v", err)
+		}
+		names[span.Name] = span.Status.Code
+	}
+	if code, ok := names["GET /1/missing"]; !ok || code != "Error" {
+		t.Errorf("expected failed call span, got %v", names)
+	}
+	if len(names) != 2 {
+		t.Errorf("expected call and attempt span, got %v", names)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Token holds the credentials used to authenticate against an IVCAP deployment.
+type Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time // zero if unknown
+}
+
+// TokenSource supplies the access tokens attached to outgoing requests.
+// Implementations need to be safe for concurrent use.
+type TokenSource interface {
+	// Token returns a token which is expected to be valid for at least
+	// a little while longer.
+	Token(ctxt context.Context) (*Token, error)
+	// Refresh acquires a new token after the server rejected `rejected`.
+	// If the token has already been replaced in the meantime (e.g. by a
+	// concurrent request), the current one is returned instead.
+	Refresh(ctxt context.Context, rejected string) (*Token, error)
+}
+
+// RefreshFunc obtains a new token, usually by exchanging the refresh token
+// held in `current` at the identity provider. It returns
+// ErrTokenNotRefreshable if there is no way to obtain a new token.
+type RefreshFunc func(ctxt context.Context, current *Token) (*Token, error)
+
+// RotateFunc is called every time a new token has been acquired. It is
+// typically used to persist the new (and possibly rotated refresh) token.
+type RotateFunc func(token *Token)
+
+// DefaultExpiryMargin defines how long before its expiry a token is
+// proactively refreshed.
+const DefaultExpiryMargin = 30 * time.Second
+
+var ErrTokenNotRefreshable = errors.New("access token cannot be refreshed")
+
+// StaticTokenSource always returns the same access token. It is used for
+// tokens provided through flags or the environment which we cannot refresh.
+func StaticTokenSource(accessToken string) TokenSource {
+	return &staticTokenSource{token: &Token{AccessToken: accessToken}}
+}
+
+type staticTokenSource struct {
+	token *Token
+}
+
+func (s *staticTokenSource) Token(_ context.Context) (*Token, error) {
+	return s.token, nil
+}
+
+func (s *staticTokenSource) Refresh(_ context.Context, _ string) (*Token, error) {
+	return nil, ErrTokenNotRefreshable
+}
+
+// RefreshingTokenSource returns a token source starting with `initial`
+// which calls `refresh` whenever the current token is about to expire, or
+// has been rejected by the server. `onRotate` (optional) is called with
+// every newly acquired token.
+func RefreshingTokenSource(initial *Token, refresh RefreshFunc, onRotate RotateFunc) TokenSource {
+	if initial == nil {
+		initial = &Token{}
+	}
+	return &refreshingTokenSource{
+		token:    initial,
+		refresh:  refresh,
+		onRotate: onRotate,
+		margin:   DefaultExpiryMargin,
+	}
+}
+
+type refreshingTokenSource struct {
+	mu       sync.Mutex
+	token    *Token
+	refresh  RefreshFunc
+	onRotate RotateFunc
+	margin   time.Duration
+}
+
+func (s *refreshingTokenSource) Token(ctxt context.Context) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.isValid(s.token) {
+		return s.token, nil
+	}
+	return s.doRefresh(ctxt)
+}
+
+func (s *refreshingTokenSource) Refresh(ctxt context.Context, rejected string) (*Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.AccessToken != rejected && s.isValid(s.token) {
+		// someone else already replaced it
+		return s.token, nil
+	}
+	return s.doRefresh(ctxt)
+}
+
+func (s *refreshingTokenSource) isValid(t *Token) bool {
+	if t.AccessToken == "" {
+		return false
+	}
+	if t.Expiry.IsZero() {
+		return true
+	}
+	return time.Now().Add(s.margin).Before(t.Expiry)
+}
+
+// must be called while holding 's.mu'
+func (s *refreshingTokenSource) doRefresh(ctxt context.Context) (*Token, error) {
+	if s.refresh == nil {
+		return nil, ErrTokenNotRefreshable
+	}
+	t, err := s.refresh(ctxt, s.token)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken == "" {
+		// not every provider rotates refresh tokens
+		t.RefreshToken = s.token.RefreshToken
+	}
+	s.token = t
+	if s.onRotate != nil {
+		s.onRotate(t)
+	}
+	return t, nil
+}
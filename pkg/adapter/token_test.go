@@ -0,0 +1,159 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	log "go.uber.org/zap"
+)
+
+func TestRefreshingTokenSource_RefreshesBeforeExpiry(t *testing.T) {
+	calls := 0
+	var rotated *Token
+	ts := RefreshingTokenSource(
+		&Token{AccessToken: "old", RefreshToken: "r1", Expiry: time.Now().Add(5 * time.Second)},
+		func(_ context.Context, current *Token) (*Token, error) {
+			calls++
+			if current.RefreshToken != "r1" {
+				t.Fatalf("expected refresh token 'r1', got '%s'", current.RefreshToken)
+			}
+			return &Token{AccessToken: "new", RefreshToken: "r2", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+		func(token *Token) { rotated = token },
+	)
+	tok, err := ts.Token(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if tok.AccessToken != "new" || calls != 1 {
+		t.Fatalf("expected refreshed token, got '%s' after %d calls", tok.AccessToken, calls)
+	}
+	if rotated == nil || rotated.RefreshToken != "r2" {
+		t.Fatalf("expected rotated refresh token to be reported")
+	}
+	// still valid, so no further refresh
+	if _, err = ts.Token(context.Background()); err != nil || calls != 1 {
+		t.Fatalf("expected cached token, but refreshed %d times (%v)", calls, err)
+	}
+	// a stale rejection should not trigger another refresh
+	if tok, _ = ts.Refresh(context.Background(), "old"); tok.AccessToken != "new" || calls != 1 {
+		t.Fatalf("expected current token for stale rejection")
+	}
+}
+
+func TestRefreshingTokenSource_WithoutRefreshToken(t *testing.T) {
+	// e.g. client credentials, which are simply requested again
+	calls := 0
+	ts := RefreshingTokenSource(
+		&Token{AccessToken: "old", Expiry: time.Now().Add(-time.Second)},
+		func(_ context.Context, _ *Token) (*Token, error) {
+			calls++
+			return &Token{AccessToken: "new", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+		nil,
+	)
+	tok, err := ts.Token(context.Background())
+	if err != nil || tok.AccessToken != "new" || calls != 1 {
+		t.Fatalf("expected new token after %d calls, got %+v (%v)", calls, tok, err)
+	}
+}
+
+func TestStaticTokenSource_CannotRefresh(t *testing.T) {
+	ts := StaticTokenSource("abc")
+	if _, err := ts.Refresh(context.Background(), "abc"); !errors.Is(err, ErrTokenNotRefreshable) {
+		t.Fatalf("expected ErrTokenNotRefreshable, got %v", err)
+	}
+}
+
+func TestConnect_RetriesOnceOn401(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(b))
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	ts := RefreshingTokenSource(
+		&Token{AccessToken: "revoked", RefreshToken: "r"},
+		func(_ context.Context, _ *Token) (*Token, error) {
+			return &Token{AccessToken: "fresh"}, nil
+		},
+		nil,
+	)
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithTokenSource(ts))
+	body := `{"a":1}`
+	pyld, err := adpt.Post(context.Background(), "/1/things", strings.NewReader(body), int64(len(body)), nil, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if string(pyld.AsBytes()) != `{"ok":true}` {
+		t.Fatalf("unexpected reply '%s'", pyld.AsBytes())
+	}
+	if len(bodies) != 2 || bodies[1] != body {
+		t.Fatalf("expected request body to be replayed, got %v", bodies)
+	}
+	if adpt.GetConnectionContext().AccessToken != "fresh" {
+		t.Fatalf("expected connection context to hold refreshed token")
+	}
+}
+
+func TestConnect_ConcurrentRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ts := RefreshingTokenSource(
+		&Token{AccessToken: "revoked", RefreshToken: "r", Expiry: time.Now().Add(time.Hour)},
+		func(_ context.Context, _ *Token) (*Token, error) {
+			return &Token{AccessToken: "fresh", Expiry: time.Now().Add(time.Hour)}, nil
+		},
+		nil,
+	)
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithTokenSource(ts))
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctxt, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if _, err := adpt.Get(ctxt, "/1/things", log.NewNop()); err != nil {
+				t.Errorf("unexpected error - %v", err)
+			}
+			_ = adpt.GetConnectionContext().AccessToken
+		}()
+	}
+	wg.Wait()
+}
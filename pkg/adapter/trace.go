@@ -0,0 +1,371 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const HARVersion = "1.2"
+
+// MaxTraceBodySize limits the size of each request and response body kept
+// in a trace. Larger bodies (e.g. artifact uploads) are truncated.
+const MaxTraceBodySize = 1 << 20
+
+// Trace records all traffic passing through its RoundTripper as a HAR 1.2
+// file, which can be loaded into the network panel of browser devtools.
+// Like cassettes, credentials are redacted before anything is written to disk.
+type Trace struct {
+	path    string
+	creator harCreator
+	file    *os.File
+	offset  int64 // where the closing 'traceFooter' starts
+	count   int
+	mu      sync.Mutex
+}
+
+// Closes the entries list and the HAR document. Each new entry overwrites it
+// and appends it again, so the file is always valid.
+const traceFooter = "\n    ]\n  }\n}\n"
+
+// NewTrace returns a trace writing to `path`. Each completed request is
+// appended to the file, so it survives the process exiting early.
+func NewTrace(path string, creator string, version string) *Trace {
+	return &Trace{
+		path:    path,
+		creator: harCreator{Name: creator, Version: version},
+	}
+}
+
+// WithTrace records all traffic of the adapter into trace `t`. It needs to
+// come after any `WithHttpClient`, `WithTransport` or `WithCassette` option.
+func WithTrace(t *Trace) Option {
+	return func(adpr *restAdapter) {
+		adpr.client.Transport = t.RoundTripper(adpr.client.Transport)
+	}
+}
+
+// RoundTripper returns a transport recording the traffic passed on to
+// `next` (nil for the default transport).
+func (t *Trace) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &traceTransport{trace: t, next: next}
+}
+
+// Save makes sure the trace file exists, even without any traffic, and
+// closes it.
+func (t *Trace) Save() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.open(); err != nil {
+		return err
+	}
+	err := t.file.Close()
+	t.file = nil
+	return err
+}
+
+// Creates the trace file with an empty entries list, or reopens it after
+// `Save`. Must be called while holding 't.mu'.
+func (t *Trace) open() error {
+	if t.file != nil {
+		return nil
+	}
+	if t.offset > 0 {
+		f, err := os.OpenFile(t.path, os.O_WRONLY, 0600)
+		t.file = f
+		return err
+	}
+	creator, err := json.Marshal(t.creator)
+	if err != nil {
+		return err
+	}
+	header := fmt.Sprintf("{\n  \"log\": {\n    \"version\": %q,\n    \"creator\": %s,\n    \"entries\": [", HARVersion, creator)
+	f, err := os.OpenFile(t.path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.WriteString(header + traceFooter); err != nil {
+		_ = f.Close()
+		return err
+	}
+	t.file, t.offset = f, int64(len(header))
+	return nil
+}
+
+// Appends `e` to the trace file. Entries appear in the order their requests
+// completed, which devtools re-sort by start time.
+func (t *Trace) add(e *harEntry) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	data, err := json.MarshalIndent(e, "      ", "  ")
+	if err != nil || t.open() != nil {
+		return // best effort
+	}
+	sep := ",\n      "
+	if t.count == 0 {
+		sep = "\n      "
+	}
+	entry := append([]byte(sep), data...)
+	if _, err = t.file.WriteAt(append(entry, traceFooter...), t.offset); err != nil {
+		return
+	}
+	t.offset += int64(len(entry))
+	t.count++
+}
+
+// Returns the text to record for `body`, with secrets removed, and its
+// encoding. A body which should be redacted but can't be parsed, as it got
+// truncated, is left out rather than recorded with its secrets.
+func traceBodyText(contentType string, body []byte, truncated bool) (string, string) {
+	text, ok := redactBody(contentType, body)
+	switch {
+	case !ok && truncated:
+		return fmt.Sprintf("(%d bytes left out, the truncated body cannot be redacted)", len(body)), ""
+	case !utf8.Valid(text):
+		return base64.StdEncoding.EncodeToString(text), "base64"
+	}
+	return string(text), ""
+}
+
+type traceTransport struct {
+	trace *Trace
+	next  http.RoundTripper
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	e := &harEntry{
+		StartedDateTime: started.Format(time.RFC3339Nano),
+		Request:         harRequestFor(req),
+		Cache:           struct{}{},
+	}
+	var reqBody []byte
+	var reqTruncated bool
+	var reqBodyMu sync.Mutex
+	if req.Body != nil && req.Body != http.NoBody {
+		// capture the body while the transport sends it
+		req = req.Clone(req.Context())
+		rb := &recordingBody{ReadCloser: req.Body, limit: MaxTraceBodySize}
+		rb.onDone = func(data []byte) {
+			reqBodyMu.Lock()
+			defer reqBodyMu.Unlock()
+			reqBody, reqTruncated = append([]byte{}, data...), rb.truncated
+		}
+		req.Body = rb
+	}
+	setPostData := func() {
+		reqBodyMu.Lock()
+		defer reqBodyMu.Unlock()
+		if reqBody != nil {
+			ct := req.Header.Get("Content-Type")
+			text, encoding := traceBodyText(ct, reqBody, reqTruncated)
+			e.Request.PostData = &harPostData{MimeType: ct, Text: text}
+			if encoding != "" {
+				e.Request.PostData.Comment = "base64 encoded"
+			}
+		}
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	headersAt := time.Now()
+	if err != nil {
+		setPostData()
+		e.Response = harResponse{
+			Headers:     []harNameValue{},
+			Cookies:     []harNameValue{},
+			Content:     harContent{},
+			HeadersSize: -1,
+			BodySize:    -1,
+			Error:       err.Error(),
+		}
+		e.Timings = harTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1, Wait: ms(headersAt.Sub(started))}
+		e.Time = e.Timings.Wait
+		t.trace.add(e)
+		return nil, err
+	}
+	e.Response = harResponseFor(resp)
+	// Capture the body while the caller reads it, so streams (SSE) are
+	// recorded as far as they got consumed.
+	body := &recordingBody{ReadCloser: resp.Body, limit: MaxTraceBodySize}
+	body.onDone = func(data []byte) {
+		done := time.Now()
+		setPostData()
+		ct := resp.Header.Get("Content-Type")
+		c := &e.Response.Content
+		c.Text, c.Encoding = traceBodyText(ct, data, body.truncated)
+		c.Size = int64(len(data))
+		if body.truncated {
+			c.Comment = fmt.Sprintf("truncated to %d bytes", MaxTraceBodySize)
+			c.Size = max(c.Size, resp.ContentLength)
+		}
+		e.Response.BodySize = c.Size
+		e.Timings = harTimings{
+			Blocked: -1, DNS: -1, Connect: -1, SSL: -1,
+			Wait:    ms(headersAt.Sub(started)),
+			Receive: ms(done.Sub(headersAt)),
+		}
+		e.Time = e.Timings.Wait + e.Timings.Receive
+		t.trace.add(e)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+func harRequestFor(req *http.Request) harRequest {
+	u, _ := neturl.Parse(RedactURL(req.URL))
+	qs := []harNameValue{}
+	if u != nil {
+		qs = harNameValues(u.Query())
+	}
+	headers := RedactHeaders(req.Header)
+	if req.Host != "" {
+		headers.Set("Host", req.Host)
+	}
+	return harRequest{
+		Method:      req.Method,
+		URL:         RedactURL(req.URL),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     []harNameValue{},
+		Headers:     harNameValues(headers),
+		QueryString: qs,
+		HeadersSize: -1,
+		BodySize:    req.ContentLength,
+	}
+}
+
+func harResponseFor(resp *http.Response) harResponse {
+	loc := ""
+	if l, err := resp.Location(); err == nil {
+		loc = l.String()
+	}
+	return harResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     []harNameValue{},
+		Headers:     harNameValues(RedactHeaders(resp.Header)),
+		Content:     harContent{MimeType: resp.Header.Get("Content-Type")},
+		RedirectURL: loc,
+		HeadersSize: -1,
+	}
+}
+
+func harNameValues(m map[string][]string) []harNameValue {
+	nvs := []harNameValue{}
+	for name, values := range m {
+		for _, v := range values {
+			nvs = append(nvs, harNameValue{Name: name, Value: v})
+		}
+	}
+	sort.SliceStable(nvs, func(i, j int) bool { return nvs[i].Name < nvs[j].Name })
+	return nvs
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// The subset of HAR 1.2 (http://www.softwareishard.com/blog/har-12-spec/)
+// required by devtools. Fields starting with '_' are custom ones.
+
+type harFile struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string      `json:"version"`
+	Creator harCreator  `json:"creator"`
+	Entries []*harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int64          `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+	Error       string         `json:"_error,omitempty"` // transport error, no response received
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type harTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	SSL     float64 `json:"ssl"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/r3labs/sse/v2"
+	log "go.uber.org/zap"
+)
+
+func TestTrace_WritesHAR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/token":
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token":"secret-at","expires_in":60}`))
+		case "/1/events":
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte("id: 1\ndata: {\"a\":1}\n\n"))
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `"}`))
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	trace := NewTrace(path, "ivcap-cli", "test")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, AccessToken: "my-token", TimeoutSec: 5}),
+		WithRetryPolicy(RetryPolicy{}), WithTrace(trace))
+	logger := log.NewNop()
+	ctxt := context.Background()
+
+	if _, err := adpt.Get(ctxt, "/1/services2?limit=2", logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	form := neturl.Values{"refresh_token": {"secret-rt"}, "grant_type": {"refresh_token"}}
+	if _, err := adpt.PostForm(ctxt, "/1/token", form, nil, logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if err := adpt.GetSSE(ctxt, "/1/events", nil, func(*sse.Event) {}, nil, logger); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if err := trace.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("trace not written - %v", err)
+	}
+	for _, secret := range []string{"my-token", "secret-rt", "secret-at"} {
+		if strings.Contains(string(data), secret) {
+			t.Fatalf("trace contains unredacted secret '%s'", secret)
+		}
+	}
+	var har harFile
+	if err = json.Unmarshal(data, &har); err != nil {
+		t.Fatalf("cannot parse trace - %v", err)
+	}
+	if har.Log.Version != HARVersion || har.Log.Creator.Name != "ivcap-cli" {
+		t.Errorf("unexpected log header %+v", har.Log)
+	}
+	if len(har.Log.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(har.Log.Entries))
+	}
+	get, post, events := har.Log.Entries[0], har.Log.Entries[1], har.Log.Entries[2]
+	if get.Request.Method != http.MethodGet || get.Response.Status != http.StatusOK ||
+		get.Response.Content.Text != `{"path":"/1/services2"}` {
+		t.Errorf("unexpected GET entry %+v", get)
+	}
+	if len(get.Request.QueryString) != 1 || get.Request.QueryString[0] != (harNameValue{"limit", "2"}) {
+		t.Errorf("unexpected query string %v", get.Request.QueryString)
+	}
+	if post.Request.PostData == nil || !strings.Contains(post.Request.PostData.Text, "grant_type=refresh_token") {
+		t.Errorf("expected form body to be recorded, got %+v", post.Request.PostData)
+	}
+	if !strings.Contains(events.Response.Content.Text, `data: {"a":1}`) {
+		t.Errorf("expected event stream to be recorded, got %q", events.Response.Content.Text)
+	}
+}
+
+func TestTrace_RecordsFailedRequests(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	trace := NewTrace(path, "ivcap-cli", "test")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: url, TimeoutSec: 5}),
+		WithRetryPolicy(RetryPolicy{}), WithTrace(trace))
+	if _, err := adpt.Get(context.Background(), "/1/things", log.NewNop()); err == nil {
+		t.Fatal("expected request to fail")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("trace not written - %v", err)
+	}
+	var har harFile
+	if err = json.Unmarshal(data, &har); err != nil || len(har.Log.Entries) != 1 {
+		t.Fatalf("expected a single entry (%v):\n%s", err, data)
+	}
+	if e := har.Log.Entries[0]; e.Response.Status != 0 || e.Response.Error == "" {
+		t.Errorf("expected entry to record the error, got %+v", e.Response)
+	}
+}
+
+func TestTrace_AppendsEntries(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	path := filepath.Join(t.TempDir(), "trace.har")
+	trace := NewTrace(path, "ivcap-cli", "test")
+	adpt := RestAdapter(WithConnContext(&ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}), WithTrace(trace))
+	entries := func() int {
+		t.Helper()
+		data, _ := os.ReadFile(path)
+		var har harFile
+		if err := json.Unmarshal(data, &har); err != nil {
+			t.Fatalf("cannot parse trace - %v:\n%s", err, data)
+		}
+		return len(har.Log.Entries)
+	}
+	if err := trace.Save(); err != nil || entries() != 0 {
+		t.Fatalf("expected empty trace (%v)", err)
+	}
+	for i := 1; i <= 3; i++ {
+		if _, err := adpt.Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+			t.Fatal(err)
+		}
+		if n := entries(); n != i {
+			t.Fatalf("expected %d entries after request %d, got %d", i, i, n)
+		}
+	}
+	if err := trace.Save(); err != nil || entries() != 3 {
+		t.Fatalf("expected all entries to be kept on save (%v)", err)
+	}
+}
+
+func TestTrace_TruncatedBodiesAreNotLeaked(t *testing.T) {
+	for _, tc := range []struct {
+		contentType, body string
+		truncated         bool
+		kept              bool // whether "secret" is recorded
+	}{
+		{"application/json", `{"refresh_token":"secret","name":"caf` + "\xc3", true, false},
+		{"application/x-www-form-urlencoded", "client_secret=secret&x=%4", true, false},
+		{"application/json", `{"access_token":"secret"}`, false, false},
+		{"text/plain", "secret", true, true},
+	} {
+		text, _ := traceBodyText(tc.contentType, []byte(tc.body), tc.truncated)
+		if kept := strings.Contains(text, "secret"); kept != tc.kept {
+			t.Errorf("expected kept=%v for %s body %q, got %q", tc.kept, tc.contentType, tc.body, text)
+		}
+	}
+}
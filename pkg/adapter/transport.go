@@ -0,0 +1,126 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TransportConfig holds the TLS and proxy settings for connecting to a
+// deployment. The zero value uses the system's CAs and the proxy
+// configured through the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+// variables.
+type TransportConfig struct {
+	// CAFile is a PEM file with additional CAs to trust
+	CAFile string
+	// ClientCert and ClientKey are PEM files for mutual TLS
+	ClientCert string
+	ClientKey  string
+	// InsecureSkipVerify disables verification of the server's certificate
+	InsecureSkipVerify bool
+	// ProxyURL is used for all requests, except for hosts matching NoProxy
+	ProxyURL string
+	// NoProxy is a comma separated list of hosts, domains and CIDRs,
+	// in the same format as the NO_PROXY environment variable
+	NoProxy string
+}
+
+// NewTransport returns a transport applying `cfg` on top of the defaults
+// of `http.DefaultTransport`.
+func NewTransport(cfg *TransportConfig) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg == nil {
+		return t, nil
+	}
+	tlsCfg, err := cfg.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	t.TLSClientConfig = tlsCfg
+	if cfg.ProxyURL != "" || cfg.NoProxy != "" {
+		proxy, err := cfg.proxy()
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = proxy
+	}
+	return t, nil
+}
+
+// WithTransport sets the transport of the adapter's HTTP client. Like
+// `WithHttpClient`, it needs to come before `WithCassette`.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(adpr *restAdapter) {
+		adpr.client.Transport = rt
+	}
+}
+
+func (cfg *TransportConfig) tlsConfig() (*tls.Config, error) {
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, // #nosec G402 -- explicitly requested by the user
+	}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(filepath.Clean(cfg.CAFile))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file '%s'", cfg.CAFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	if (cfg.ClientCert == "") != (cfg.ClientKey == "") {
+		return nil, fmt.Errorf("client certificate and key need to be set together")
+	}
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("cannot load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
+
+func (cfg *TransportConfig) proxy() (func(*http.Request) (*url.URL, error), error) {
+	env := httpproxy.FromEnvironment()
+	if cfg.ProxyURL != "" {
+		if u, err := url.Parse(cfg.ProxyURL); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL '%s'", cfg.ProxyURL)
+		}
+		env.HTTPProxy = cfg.ProxyURL
+		env.HTTPSProxy = cfg.ProxyURL
+	}
+	if cfg.NoProxy != "" {
+		env.NoProxy = cfg.NoProxy
+	}
+	proxyFor := env.ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyFor(req.URL)
+	}, nil
+}
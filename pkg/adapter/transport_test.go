@@ -0,0 +1,158 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package adapter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	log "go.uber.org/zap"
+)
+
+func okHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+}
+
+func writePEM(t *testing.T, name string, blockType string, der []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func adapterWith(t *testing.T, url string, cfg *TransportConfig) Adapter {
+	t.Helper()
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	return RestAdapter(WithConnContext(&ConnectionCtxt{URL: url, TimeoutSec: 5}),
+		WithRetryPolicy(RetryPolicy{}), WithTransport(transport))
+}
+
+func TestTransport_TrustsCAFile(t *testing.T) {
+	srv := httptest.NewTLSServer(okHandler())
+	t.Cleanup(srv.Close)
+
+	if _, err := adapterWith(t, srv.URL, &TransportConfig{}).Get(context.Background(), "/1/things", log.NewNop()); err == nil {
+		t.Fatalf("expected unknown CA to be rejected")
+	}
+	caFile := writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw)
+	if _, err := adapterWith(t, srv.URL, &TransportConfig{CAFile: caFile}).Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if _, err := adapterWith(t, srv.URL, &TransportConfig{InsecureSkipVerify: true}).Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+}
+
+func TestTransport_PresentsClientCert(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ivcap-cli test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientCert, _ := x509.ParseCertificate(der)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(okHandler())
+	pool := x509.NewCertPool()
+	pool.AddCert(clientCert)
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	cfg := &TransportConfig{
+		CAFile:     writePEM(t, "ca.pem", "CERTIFICATE", srv.Certificate().Raw),
+		ClientCert: writePEM(t, "client.pem", "CERTIFICATE", der),
+		ClientKey:  writePEM(t, "client-key.pem", "EC PRIVATE KEY", keyDer),
+	}
+	if _, err := adapterWith(t, srv.URL, cfg).Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	cfg.ClientCert, cfg.ClientKey = "", ""
+	if _, err := adapterWith(t, srv.URL, cfg).Get(context.Background(), "/1/things", log.NewNop()); err == nil {
+		t.Fatalf("expected request without client certificate to be rejected")
+	}
+}
+
+func TestTransport_UsesProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		okHandler().ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	adpt := adapterWith(t, "http://ivcap.example.com", &TransportConfig{ProxyURL: proxy.URL})
+	if _, err := adpt.Get(context.Background(), "/1/things", log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://ivcap.example.com/1/things" {
+		t.Fatalf("expected request to go through proxy, got %v", proxied)
+	}
+
+	transport, err := NewTransport(&TransportConfig{ProxyURL: proxy.URL, NoProxy: ".internal.example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, "https://api.internal.example.com/1/things", nil)
+	if u, err := transport.Proxy(req); err != nil || u != nil {
+		t.Fatalf("expected 'no-proxy' host to be connected to directly, got %v (%v)", u, err)
+	}
+}
+
+func TestNewTransport_RejectsInvalidConfig(t *testing.T) {
+	for _, cfg := range []*TransportConfig{
+		{CAFile: "/does/not/exist.pem"},
+		{ClientCert: "client.pem"},
+		{ProxyURL: "not a url"},
+	} {
+		if _, err := NewTransport(cfg); err == nil {
+			t.Errorf("expected %+v to be rejected", cfg)
+		}
+	}
+}
@@ -30,6 +30,8 @@ import (
 
 	"github.com/ivcap-works/ivcap-cli/pkg/adapter"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	log "go.uber.org/zap"
 )
 
@@ -137,8 +139,9 @@ func UploadArtifact(
 			"Upload-Offset": fmt.Sprintf("%d", off),
 			"Tus-Resumable": "1.0.0",
 		}
-		// var pyld adapter.Payload
-		_, err = (*adpt).Patch(context.Background(), path, r, psize, &h, logger)
+		cctxt, span := startChunkSpan(ctxt, off, psize)
+		_, err = (*adpt).Patch(cctxt, path, r, psize, &h, logger)
+		span.End()
 		if err != nil {
 			if !silent {
 				fmt.Printf("\n") // To move past progress bar
@@ -178,13 +181,15 @@ func uploadUnknownSize(
 			}
 			// need to inform about size
 			h["Upload-Length"] = fmt.Sprintf("%d", off)
-			_, err = (*adpt).Patch(context.Background(), path, nil, 0, &h, logger)
+			_, err = (*adpt).Patch(ctxt, path, nil, 0, &h, logger)
 			return
 		}
 		r := bytes.NewReader(p[:n])
 		h["Upload-Defer-Length"] = "1"
 		var pyld adapter.Payload
-		pyld, err = (*adpt).Patch(context.Background(), path, r, int64(n), &h, logger)
+		cctxt, span := startChunkSpan(ctxt, off, int64(n))
+		pyld, err = (*adpt).Patch(cctxt, path, r, int64(n), &h, logger)
+		span.End()
 		if err != nil {
 			return
 		}
@@ -203,6 +208,16 @@ func uploadUnknownSize(
 	}
 }
 
+// startChunkSpan starts the span for uploading `size` bytes at `offset`,
+// with the tracer of the span in `ctxt`, if any.
+func startChunkSpan(ctxt context.Context, offset int64, size int64) (context.Context, trace.Span) {
+	tracer := trace.SpanFromContext(ctxt).TracerProvider().Tracer(adapter.TracerName)
+	return tracer.Start(ctxt, "upload chunk", trace.WithAttributes(
+		attribute.Int64("ivcap.upload.offset", offset),
+		attribute.Int64("ivcap.upload.size", size),
+	))
+}
+
 func AddProgressBar(description string, size int64, reader io.Reader) io.Reader {
 	bar := GetProgressBar(description, size)
 	return io.TeeReader(reader, bar)
@@ -0,0 +1,305 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+
+	api "github.com/ivcap-works/ivcap-core-api/http/artifact"
+)
+
+const (
+	ArtifactPending = "pending"
+	ArtifactReady   = "ready"
+)
+
+// Artifact is a stored artifact. `Size` is -1 while the final size of an
+// upload isn't known yet.
+type Artifact struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name,omitempty"`
+	MimeType    string   `json:"mime-type,omitempty"`
+	Size        int64    `json:"size"`
+	Data        []byte   `json:"data,omitempty"`
+	Policy      string   `json:"policy,omitempty"`
+	Account     string   `json:"account,omitempty"`
+	Collections []string `json:"collections,omitempty"`
+	CreatedAt   string   `json:"created-at,omitempty"`
+}
+
+// Status returns 'ready' once all the data has been uploaded.
+func (a *Artifact) Status() string {
+	if a.Size >= 0 && int64(len(a.Data)) >= a.Size {
+		return ArtifactReady
+	}
+	return ArtifactPending
+}
+
+// AddArtifact stores `a` and returns its ID. If `a.Size` is zero, it is
+// set to the length of `a.Data`.
+func (s *Server) AddArtifact(a Artifact) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addArtifact(&a)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addArtifact(a *Artifact) string {
+	if a.ID == "" {
+		a.ID = s.newID("artifact")
+	}
+	if a.Size == 0 {
+		a.Size = int64(len(a.Data))
+	}
+	if a.CreatedAt == "" {
+		a.CreatedAt = now()
+	}
+	s.artifacts = append(s.artifacts, a)
+	return a.ID
+}
+
+// ArtifactData returns a copy of the data uploaded so far to artifact `id`.
+func (s *Server) ArtifactData(id string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(id)
+	if !ok {
+		return nil, false
+	}
+	return slices.Clone(a.Data), true
+}
+
+// must be called while holding 's.mu'
+func (s *Server) artifact(id string) (*Artifact, bool) {
+	return find(s.artifacts, func(a *Artifact) bool { return a.ID == id })
+}
+
+func (s *Server) dataHref(a *Artifact) string {
+	return fmt.Sprintf("%s/1/artifacts/%s/blob", s.URL, a.ID)
+}
+
+func (s *Server) artifactReply(a *Artifact) *api.ReadResponseBody {
+	return &api.ReadResponseBody{
+		ID:             ptr(a.ID),
+		Name:           optional(a.Name),
+		Status:         ptr(a.Status()),
+		MimeType:       optional(a.MimeType),
+		Size:           ptr(a.Size),
+		CreatedAt:      optional(a.CreatedAt),
+		LastModifiedAt: optional(a.CreatedAt),
+		Policy:         optional(a.Policy),
+		Account:        optional(a.Account),
+		DataHref:       ptr(s.dataHref(a)),
+	}
+}
+
+func (s *Server) listArtifacts(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*api.ArtifactListItemResponseBody, len(s.artifacts))
+	for i, a := range s.artifacts {
+		items[i] = &api.ArtifactListItemResponseBody{
+			ID:        ptr(a.ID),
+			Name:      optional(a.Name),
+			Status:    ptr(a.Status()),
+			Size:      ptr(a.Size),
+			MimeType:  optional(a.MimeType),
+			CreatedAt: optional(a.CreatedAt),
+			Href:      ptr(s.URL + "/1/artifacts/" + a.ID),
+		}
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+// createArtifact either registers an artifact whose content is uploaded
+// later through the TUS endpoints, or directly stores the request body.
+func (s *Server) createArtifact(w http.ResponseWriter, r *http.Request) {
+	a := &Artifact{
+		Policy: r.Header.Get("X-Policy"),
+	}
+	if name := r.Header.Get("X-Name"); name != "" {
+		n, err := base64.StdEncoding.DecodeString(name)
+		if err != nil {
+			replyError(w, http.StatusBadRequest, "'X-Name' is not base64 encoded")
+			return
+		}
+		a.Name = string(n)
+	}
+	if c := r.Header.Get("X-Collection"); c != "" {
+		a.Collections = []string{c}
+	}
+	var err error
+	if r.Header.Get("Upload-Length") != "" {
+		a.MimeType = r.Header.Get("Content-Type")
+		a.Size, err = strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+		if err == nil {
+			a.Data, err = io.ReadAll(r.Body)
+		}
+	} else {
+		a.MimeType = r.Header.Get("X-Content-Type")
+		a.Size, err = strconv.ParseInt(r.Header.Get("X-Content-Length"), 10, 64)
+	}
+	if err != nil {
+		replyError(w, http.StatusBadRequest, "cannot determine artifact size - %v", err)
+		return
+	}
+	if a.Size <= 0 {
+		a.Size = -1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addArtifact(a)
+	body := api.UploadResponseBody(*s.artifactReply(a))
+	w.Header().Set("Location", s.dataHref(a))
+	replyJSON(w, http.StatusCreated, &body)
+}
+
+func (s *Server) readArtifact(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, s.artifactReply(a))
+}
+
+func (s *Server) downloadArtifact(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	if a.MimeType != "" {
+		w.Header().Set("Content-Type", a.MimeType)
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(a.Data)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(a.Data)
+}
+
+// uploadStatus implements the TUS HEAD request
+func (s *Server) uploadStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Tus-Resumable", "1.0.0")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Upload-Offset", strconv.Itoa(len(a.Data)))
+	if a.Size >= 0 {
+		w.Header().Set("Upload-Length", strconv.FormatInt(a.Size, 10))
+	} else {
+		w.Header().Set("Upload-Defer-Length", "1")
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// uploadChunk implements the TUS PATCH request
+func (s *Server) uploadChunk(w http.ResponseWriter, r *http.Request) {
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil {
+		replyError(w, http.StatusBadRequest, "missing or invalid 'Upload-Offset'")
+		return
+	}
+	chunk, err := io.ReadAll(r.Body)
+	if err != nil {
+		replyError(w, http.StatusBadRequest, "cannot read chunk - %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	if offset != int64(len(a.Data)) {
+		replyError(w, http.StatusConflict, "expected offset %d but got %d", len(a.Data), offset)
+		return
+	}
+	if a.Size >= 0 && offset+int64(len(chunk)) > a.Size {
+		replyError(w, http.StatusRequestEntityTooLarge, "upload exceeds declared length %d", a.Size)
+		return
+	}
+	a.Data = append(a.Data, chunk...)
+	if l := r.Header.Get("Upload-Length"); l != "" {
+		if a.Size, err = strconv.ParseInt(l, 10, 64); err != nil {
+			replyError(w, http.StatusBadRequest, "invalid 'Upload-Length'")
+			return
+		}
+	}
+	w.Header().Set("Tus-Resumable", "1.0.0")
+	w.Header().Set("Upload-Offset", strconv.Itoa(len(a.Data)))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) addToCollection(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	if name := r.PathValue("name"); !slices.Contains(a.Collections, name) {
+		a.Collections = append(a.Collections, name)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) removeFromCollection(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	name := r.PathValue("name")
+	a.Collections = slices.DeleteFunc(a.Collections, func(c string) bool { return c == name })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// addArtifactMeta attaches the request body as aspect to the artifact
+func (s *Server) addArtifactMeta(w http.ResponseWriter, r *http.Request) {
+	var content any
+	if !decodeBody(w, r, &content) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.artifact(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "artifact not found")
+		return
+	}
+	id := s.addAspect(&Aspect{Entity: a.ID, Schema: r.PathValue("schema"), Content: content})
+	replyJSON(w, http.StatusOK, map[string]string{"id": id})
+}
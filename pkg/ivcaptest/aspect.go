@@ -0,0 +1,181 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"net/http"
+	"strings"
+
+	api "github.com/ivcap-works/ivcap-core-api/http/aspect"
+)
+
+// Aspect is a stored aspect record. It is retracted once `ValidTo` is set.
+type Aspect struct {
+	ID        string `json:"id,omitempty"`
+	Entity    string `json:"entity"`
+	Schema    string `json:"schema"`
+	Content   any    `json:"content"`
+	Policy    string `json:"policy,omitempty"`
+	Account   string `json:"account,omitempty"`
+	Asserter  string `json:"asserter,omitempty"`
+	Replaces  string `json:"replaces,omitempty"`
+	ValidFrom string `json:"valid-from,omitempty"`
+	ValidTo   string `json:"valid-to,omitempty"`
+}
+
+// AddAspect stores `a` and returns its record ID.
+func (s *Server) AddAspect(a Aspect) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addAspect(&a)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addAspect(a *Aspect) string {
+	if a.ID == "" {
+		a.ID = s.newID("record")
+	}
+	if a.ValidFrom == "" {
+		a.ValidFrom = now()
+	}
+	s.aspects = append(s.aspects, a)
+	return a.ID
+}
+
+// must be called while holding 's.mu'
+func (s *Server) aspect(id string) (*Aspect, bool) {
+	return find(s.aspects, func(a *Aspect) bool { return a.ID == id })
+}
+
+// listAspects supports filtering by 'entity' and 'schema' (prefix, with an
+// optional trailing '%'). The 'aspect-path' filter is ignored.
+func (s *Server) listAspects(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	entity := q.Get("entity")
+	schema := strings.TrimSuffix(q.Get("schema"), "%")
+	includeContent := q.Get("include-content") == "true"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []*api.AspectListItemRTResponseBody{}
+	for _, a := range s.aspects {
+		if a.ValidTo != "" ||
+			(entity != "" && a.Entity != entity) ||
+			!strings.HasPrefix(a.Schema, schema) {
+			continue
+		}
+		item := &api.AspectListItemRTResponseBody{
+			ID:          ptr(a.ID),
+			Entity:      ptr(a.Entity),
+			Schema:      ptr(a.Schema),
+			ContentType: ptr("application/json"),
+			ValidFrom:   optional(a.ValidFrom),
+		}
+		if includeContent {
+			item.Content = a.Content
+		}
+		items = append(items, item)
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+func (s *Server) createAspect(w http.ResponseWriter, r *http.Request) {
+	a, ok := aspectFromRequest(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := s.addAspect(a)
+	replyJSON(w, http.StatusOK, &api.CreateResponseBody{ID: &id})
+}
+
+// updateAspect retracts the current aspect for the same entity and
+// schema and replaces it with the request's content.
+func (s *Server) updateAspect(w http.ResponseWriter, r *http.Request) {
+	a, ok := aspectFromRequest(w, r)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var current []*Aspect
+	for _, c := range s.aspects {
+		if c.ValidTo == "" && c.Entity == a.Entity && c.Schema == a.Schema {
+			current = append(current, c)
+		}
+	}
+	if len(current) > 1 {
+		replyError(w, http.StatusConflict, "more than one aspect for entity '%s' and schema '%s'", a.Entity, a.Schema)
+		return
+	}
+	if len(current) == 1 {
+		current[0].ValidTo = now()
+		a.Replaces = current[0].ID
+	}
+	id := s.addAspect(a)
+	replyJSON(w, http.StatusOK, &api.UpdateResponseBody{ID: &id})
+}
+
+func aspectFromRequest(w http.ResponseWriter, r *http.Request) (*Aspect, bool) {
+	q := r.URL.Query()
+	a := &Aspect{
+		Entity: q.Get("entity"),
+		Schema: q.Get("schema"),
+		Policy: q.Get("policy"),
+	}
+	if a.Entity == "" || a.Schema == "" {
+		replyError(w, http.StatusBadRequest, "missing 'entity' or 'schema'")
+		return nil, false
+	}
+	return a, decodeBody(w, r, &a.Content)
+}
+
+func (s *Server) readAspect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.aspect(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "aspect not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, &api.ReadResponseBody{
+		ID:          ptr(a.ID),
+		Entity:      ptr(a.Entity),
+		Schema:      ptr(a.Schema),
+		Content:     a.Content,
+		ContentType: ptr("application/json"),
+		ValidFrom:   optional(a.ValidFrom),
+		ValidTo:     optional(a.ValidTo),
+		Asserter:    optional(a.Asserter),
+		Replaces:    optional(a.Replaces),
+		Account:     optional(a.Account),
+		Policy:      optional(a.Policy),
+	})
+}
+
+func (s *Server) retractAspect(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.aspect(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "aspect not found")
+		return
+	}
+	if a.ValidTo == "" {
+		a.ValidTo = now()
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Fixtures describe the initial state of a fake deployment. Records without
+// an ID are assigned one when seeded. In JSON, artifact data is base64
+// encoded.
+type Fixtures struct {
+	Artifacts []*Artifact `json:"artifacts,omitempty"`
+	Aspects   []*Aspect   `json:"aspects,omitempty"`
+	Services  []*Service  `json:"services,omitempty"`
+	Jobs      []*Job      `json:"jobs,omitempty"`
+	Queues    []*Queue    `json:"queues,omitempty"`
+	Orders    []*Order    `json:"orders,omitempty"`
+	Secrets   []*Secret   `json:"secrets,omitempty"`
+	Packages  []string    `json:"packages,omitempty"`
+	AuthInfo  *AuthInfo   `json:"auth-info,omitempty"`
+}
+
+// LoadFixtures reads fixtures from a JSON file.
+func LoadFixtures(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	var f Fixtures
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("cannot parse fixtures '%s' - %w", path, err)
+	}
+	return &f, nil
+}
+
+// Seed adds copies of all records in `f` to the server's state.
+func (s *Server) Seed(f *Fixtures) {
+	if f == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range f.Artifacts {
+		c := *a
+		s.addArtifact(&c)
+	}
+	for _, a := range f.Aspects {
+		c := *a
+		s.addAspect(&c)
+	}
+	for _, svc := range f.Services {
+		c := *svc
+		s.addService(&c)
+	}
+	for _, j := range f.Jobs {
+		c := *j
+		s.addJob(&c)
+	}
+	for _, q := range f.Queues {
+		c := *q
+		s.addQueue(&c)
+	}
+	for _, o := range f.Orders {
+		c := *o
+		s.addOrder(&c)
+	}
+	for _, secret := range f.Secrets {
+		c := *secret
+		s.addSecret(&c)
+	}
+	for _, p := range f.Packages {
+		if !slices.Contains(s.packages, p) {
+			s.packages = append(s.packages, p)
+		}
+	}
+	if f.AuthInfo != nil {
+		s.authInfo = *f.AuthInfo
+	}
+}
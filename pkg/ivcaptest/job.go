@@ -0,0 +1,305 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+)
+
+const (
+	JobScheduled = "scheduled"
+	JobExecuting = "executing"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobSchema is the schema of the aspect recorded for every job. The CLI
+// uses it to find the service a job belongs to.
+const JobSchema = "urn:ivcap:schema:job.2"
+
+// Job is a stored service job. `Request` and `Result` hold the parsed JSON
+// content, or a string for any other content type.
+type Job struct {
+	ID                 string      `json:"id,omitempty"`
+	ServiceID          string      `json:"service-id"`
+	Name               string      `json:"name,omitempty"`
+	Status             string      `json:"status,omitempty"`
+	RequestContentType string      `json:"request-content-type,omitempty"`
+	Request            any         `json:"request,omitempty"`
+	ResultContentType  string      `json:"result-content-type,omitempty"`
+	Result             any         `json:"result,omitempty"`
+	ErrorMessage       string      `json:"error-message,omitempty"`
+	Events             []*JobEvent `json:"events,omitempty"`
+	RequestedAt        string      `json:"requested-at,omitempty"`
+	StartedAt          string      `json:"started-at,omitempty"`
+	FinishedAt         string      `json:"finished-at,omitempty"`
+
+	aspect *Aspect
+}
+
+// JobEvent is an event streamed by the job's 'events' endpoint. It
+// serialises like `sdk.JobEventsResponseBody`.
+type JobEvent struct {
+	SeqID     string `json:"SeqID"`
+	EventID   string `json:"eventID,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Schema    string `json:"schema,omitempty"`
+	Source    string `json:"source,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Data      any    `json:"data,omitempty"`
+}
+
+// IsTerminal returns true once the job will not change anymore.
+func (j *Job) IsTerminal() bool {
+	return j.Status != "" && j.Status != JobScheduled && j.Status != JobExecuting
+}
+
+// AddEvent appends an event with the next sequence ID.
+func (j *Job) AddEvent(eventType string, data any) *JobEvent {
+	ev := &JobEvent{
+		SeqID:     strconv.Itoa(len(j.Events) + 1),
+		Type:      eventType,
+		Source:    j.ID,
+		Timestamp: now(),
+		Data:      data,
+	}
+	ev.EventID = fmt.Sprintf("%s#%s", j.ID, ev.SeqID)
+	j.Events = append(j.Events, ev)
+	return ev
+}
+
+// JobHandler is called for every newly created job. It can set the job's
+// status, result and events, or leave it 'scheduled' to be progressed
+// later through `Server.UpdateJob`.
+type JobHandler func(job *Job)
+
+// DefaultJobHandler immediately completes a job, returning its request as result.
+func DefaultJobHandler(job *Job) {
+	job.AddEvent("job-started", nil)
+	job.Status = JobSucceeded
+	job.StartedAt = now()
+	job.FinishedAt = job.StartedAt
+	job.ResultContentType = job.RequestContentType
+	job.Result = job.Request
+	job.AddEvent("job-finished", map[string]string{"status": job.Status})
+}
+
+// AddJob stores `job` and returns its ID.
+func (s *Server) AddJob(job Job) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addJob(&job)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addJob(job *Job) string {
+	if job.ID == "" {
+		job.ID = s.newID("job")
+	}
+	if job.Status == "" {
+		job.Status = JobScheduled
+	}
+	if job.RequestedAt == "" {
+		job.RequestedAt = now()
+	}
+	job.aspect = &Aspect{Entity: job.ID, Schema: JobSchema}
+	s.updateJobAspect(job)
+	s.addAspect(job.aspect)
+	s.jobs = append(s.jobs, job)
+	return job.ID
+}
+
+// UpdateJob calls `update` with job `id` and notifies anyone streaming its
+// events. Returns false if there is no such job.
+func (s *Server) UpdateJob(id string, update func(job *Job)) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := find(s.jobs, func(j *Job) bool { return j.ID == id })
+	if !ok {
+		return false
+	}
+	update(job)
+	s.updateJobAspect(job)
+	s.notifyChanged()
+	return true
+}
+
+// must be called while holding 's.mu'
+func (s *Server) updateJobAspect(job *Job) {
+	job.aspect.Content = map[string]any{
+		"id":           job.ID,
+		"service-id":   job.ServiceID,
+		"status":       job.Status,
+		"requested-at": job.RequestedAt,
+	}
+}
+
+// must be called while holding 's.mu'
+func (s *Server) job(serviceID, id string) (*Job, bool) {
+	return find(s.jobs, func(j *Job) bool { return j.ID == id && j.ServiceID == serviceID })
+}
+
+func (s *Server) jobReply(job *Job) *sdk.JobReadResponseBody {
+	return &sdk.JobReadResponseBody{
+		ID:                 ptr(job.ID),
+		Status:             ptr(job.Status),
+		Name:               optional(job.Name),
+		Service:            ptr(job.ServiceID),
+		RequestContentType: optional(job.RequestContentType),
+		RequestContent:     job.Request,
+		ResultContentType:  optional(job.ResultContentType),
+		ResultContent:      job.Result,
+		ErrorMessage:       optional(job.ErrorMessage),
+		RequestedAt:        optional(job.RequestedAt),
+		StartedAt:          optional(job.StartedAt),
+		FinishedAt:         optional(job.FinishedAt),
+	}
+}
+
+func (s *Server) listJobs(w http.ResponseWriter, r *http.Request) {
+	serviceID := r.PathValue("id")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []*sdk.JobListItemResponseBody{}
+	for _, j := range s.jobs {
+		if j.ServiceID != serviceID {
+			continue
+		}
+		items = append(items, &sdk.JobListItemResponseBody{
+			ID:         ptr(j.ID),
+			Name:       optional(j.Name),
+			Status:     ptr(j.Status),
+			StartedAt:  optional(j.StartedAt),
+			FinishedAt: optional(j.FinishedAt),
+			Service:    ptr(j.ServiceID),
+			Href:       ptr(fmt.Sprintf("%s/1/services2/%s/jobs/%s", s.URL, serviceID, j.ID)),
+		})
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+// createJob always accepts jobs asynchronously, independent of the
+// request's 'Timeout' header.
+func (s *Server) createJob(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		replyError(w, http.StatusBadRequest, "cannot read request - %v", err)
+		return
+	}
+	job := &Job{
+		ServiceID:          r.PathValue("id"),
+		RequestContentType: r.Header.Get("Content-Type"),
+		Request:            string(body),
+	}
+	if strings.Contains(job.RequestContentType, "json") {
+		if err := json.Unmarshal(body, &job.Request); err != nil {
+			replyError(w, http.StatusBadRequest, "cannot parse request - %v", err)
+			return
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.service(job.ServiceID); !ok {
+		replyError(w, http.StatusNotFound, "service not found")
+		return
+	}
+	job.ID = s.newID("job")
+	job.Status = JobScheduled
+	if s.JobHandler != nil {
+		s.JobHandler(job)
+	}
+	s.addJob(job)
+	s.notifyChanged()
+	replyJSON(w, http.StatusAccepted, &sdk.JobCreateT{
+		JobID:      job.ID,
+		ServiceID:  job.ServiceID,
+		RetryLater: 1,
+	})
+}
+
+func (s *Server) readJob(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	job, ok := s.job(r.PathValue("id"), r.PathValue("job"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, s.jobReply(job))
+}
+
+// jobEvents streams the job's events as server-sent events, starting after
+// the one identified by 'Last-Event-ID'. The stream stays open, picking up
+// events added through `UpdateJob`, until the job is terminal.
+func (s *Server) jobEvents(w http.ResponseWriter, r *http.Request) {
+	serviceID, jobID := r.PathValue("id"), r.PathValue("job")
+	lastID := r.Header.Get("Last-Event-ID")
+
+	s.mu.Lock()
+	_, ok := s.job(serviceID, jobID)
+	s.mu.Unlock()
+	if !ok {
+		replyError(w, http.StatusNotFound, "job not found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	for {
+		s.mu.Lock()
+		job, _ := s.job(serviceID, jobID)
+		pending := job.Events
+		for i, ev := range job.Events {
+			if ev.SeqID == lastID {
+				pending = job.Events[i+1:]
+			}
+		}
+		done := job.IsTerminal()
+		changed := s.changed
+		s.mu.Unlock()
+
+		for _, ev := range pending {
+			data, _ := json.Marshal(ev)
+			if _, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", ev.SeqID, ev.Type, data); err != nil {
+				return
+			}
+			lastID = ev.SeqID
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if done {
+			return
+		}
+		select {
+		case <-changed:
+		case <-s.closing:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
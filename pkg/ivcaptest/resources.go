@@ -0,0 +1,529 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	orderapi "github.com/ivcap-works/ivcap-core-api/http/order"
+	pkgapi "github.com/ivcap-works/ivcap-core-api/http/package_"
+	queueapi "github.com/ivcap-works/ivcap-core-api/http/queue"
+	secretapi "github.com/ivcap-works/ivcap-core-api/http/secret"
+	yaml "gopkg.in/yaml.v3"
+)
+
+/**** QUEUES ****/
+
+type Queue struct {
+	ID            string          `json:"id,omitempty"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description,omitempty"`
+	Policy        string          `json:"policy,omitempty"`
+	Account       string          `json:"account,omitempty"`
+	Messages      []*QueueMessage `json:"messages,omitempty"`
+	TotalMessages uint64          `json:"total-messages,omitempty"`
+	CreatedAt     string          `json:"created-at,omitempty"`
+}
+
+type QueueMessage struct {
+	ID          string `json:"id,omitempty"`
+	Schema      string `json:"schema,omitempty"`
+	ContentType string `json:"content-type,omitempty"`
+	Content     any    `json:"content"`
+}
+
+// AddQueue stores `q` and returns its ID.
+func (s *Server) AddQueue(q Queue) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addQueue(&q)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addQueue(q *Queue) string {
+	if q.ID == "" {
+		q.ID = s.newID("queue")
+	}
+	if q.CreatedAt == "" {
+		q.CreatedAt = now()
+	}
+	for _, m := range q.Messages {
+		s.initMessage(m)
+	}
+	q.TotalMessages = max(q.TotalMessages, uint64(len(q.Messages)))
+	s.queues = append(s.queues, q)
+	return q.ID
+}
+
+// must be called while holding 's.mu'
+func (s *Server) initMessage(m *QueueMessage) {
+	if m.ID == "" {
+		m.ID = s.newID("message")
+	}
+	if m.ContentType == "" {
+		m.ContentType = "application/json"
+	}
+}
+
+// must be called while holding 's.mu'
+func (s *Server) queue(id string) (*Queue, bool) {
+	return find(s.queues, func(q *Queue) bool { return q.ID == id })
+}
+
+func (s *Server) listQueues(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*queueapi.QueueListItemResponseBody, len(s.queues))
+	for i, q := range s.queues {
+		items[i] = &queueapi.QueueListItemResponseBody{
+			ID:          ptr(q.ID),
+			Name:        ptr(q.Name),
+			Description: optional(q.Description),
+			Account:     optional(q.Account),
+			Href:        ptr(s.URL + "/1/queues/" + q.ID),
+		}
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+func (s *Server) createQueue(w http.ResponseWriter, r *http.Request) {
+	var req queueapi.CreateRequestBody
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	q := &Queue{Name: req.Name}
+	if req.Description != nil {
+		q.Description = *req.Description
+	}
+	if req.Policy != nil {
+		q.Policy = *req.Policy
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := find(s.queues, func(c *Queue) bool { return c.Name == q.Name }); exists {
+		replyError(w, http.StatusConflict, "queue '%s' already exists", q.Name)
+		return
+	}
+	s.addQueue(q)
+	replyJSON(w, http.StatusCreated, &queueapi.CreateResponseBody{
+		ID:          ptr(q.ID),
+		Name:        ptr(q.Name),
+		Description: optional(q.Description),
+		Account:     optional(q.Account),
+	})
+}
+
+func (s *Server) readQueue(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queue(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "queue not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, &queueapi.ReadResponseBody{
+		ID:            ptr(q.ID),
+		Name:          ptr(q.Name),
+		Description:   optional(q.Description),
+		TotalMessages: ptr(q.TotalMessages),
+		ConsumerCount: ptr(0),
+		CreatedAt:     optional(q.CreatedAt),
+	})
+}
+
+func (s *Server) deleteQueue(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.queue(id); !ok {
+		replyError(w, http.StatusNotFound, "queue not found")
+		return
+	}
+	s.queues = slices.DeleteFunc(s.queues, func(q *Queue) bool { return q.ID == id })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) enqueue(w http.ResponseWriter, r *http.Request) {
+	m := &QueueMessage{Schema: r.URL.Query().Get("schema")}
+	if !decodeBody(w, r, &m.Content) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queue(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "queue not found")
+		return
+	}
+	s.initMessage(m)
+	q.Messages = append(q.Messages, m)
+	q.TotalMessages++
+	replyJSON(w, http.StatusOK, &queueapi.EnqueueResponseBody{ID: ptr(m.ID)})
+}
+
+// dequeue removes and returns up to 'limit' messages
+func (s *Server) dequeue(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit < 1 {
+		limit = 1
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	q, ok := s.queue(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "queue not found")
+		return
+	}
+	n := min(limit, len(q.Messages))
+	reply := &queueapi.DequeueResponseBody{
+		Messages: make([]*queueapi.PublishedmessageResponseBody, n),
+		AtTime:   ptr(now()),
+	}
+	for i, m := range q.Messages[:n] {
+		reply.Messages[i] = &queueapi.PublishedmessageResponseBody{
+			ID:          ptr(m.ID),
+			Content:     m.Content,
+			Schema:      optional(m.Schema),
+			ContentType: ptr(m.ContentType),
+		}
+	}
+	q.Messages = q.Messages[n:]
+	replyJSON(w, http.StatusOK, reply)
+}
+
+/**** ORDERS ****/
+
+type Order struct {
+	ID         string            `json:"id,omitempty"`
+	Name       string            `json:"name,omitempty"`
+	Status     string            `json:"status,omitempty"`
+	ServiceID  string            `json:"service"`
+	Account    string            `json:"account,omitempty"`
+	Tags       []string          `json:"tags,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+	Logs       []string          `json:"logs,omitempty"`
+	OrderedAt  string            `json:"ordered-at,omitempty"`
+	StartedAt  string            `json:"started-at,omitempty"`
+	FinishedAt string            `json:"finished-at,omitempty"`
+}
+
+// AddOrder stores `o` and returns its ID.
+func (s *Server) AddOrder(o Order) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addOrder(&o)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addOrder(o *Order) string {
+	if o.ID == "" {
+		o.ID = s.newID("order")
+	}
+	if o.Status == "" {
+		o.Status = "pending"
+	}
+	if o.OrderedAt == "" {
+		o.OrderedAt = now()
+	}
+	s.orders = append(s.orders, o)
+	return o.ID
+}
+
+// must be called while holding 's.mu'
+func (s *Server) order(id string) (*Order, bool) {
+	return find(s.orders, func(o *Order) bool { return o.ID == id })
+}
+
+func (s *Server) orderReply(o *Order) *orderapi.ReadResponseBody {
+	reply := &orderapi.ReadResponseBody{
+		ID:         ptr(o.ID),
+		Status:     ptr(o.Status),
+		OrderedAt:  optional(o.OrderedAt),
+		StartedAt:  optional(o.StartedAt),
+		FinishedAt: optional(o.FinishedAt),
+		Service:    ptr(o.ServiceID),
+		Account:    optional(o.Account),
+		Name:       optional(o.Name),
+		Tags:       o.Tags,
+		Products:   &orderapi.PartialProductListTResponseBody{Items: []*orderapi.ProductListItemTResponseBody{}},
+	}
+	for _, name := range slices.Sorted(maps.Keys(o.Parameters)) {
+		reply.Parameters = append(reply.Parameters, &orderapi.ParameterTResponseBody{
+			Name:  ptr(name),
+			Value: ptr(o.Parameters[name]),
+		})
+	}
+	return reply
+}
+
+func (s *Server) listOrders(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]*orderapi.OrderListItemResponseBody, len(s.orders))
+	for i, o := range s.orders {
+		items[i] = &orderapi.OrderListItemResponseBody{
+			ID:         ptr(o.ID),
+			Name:       optional(o.Name),
+			Status:     ptr(o.Status),
+			OrderedAt:  optional(o.OrderedAt),
+			StartedAt:  optional(o.StartedAt),
+			FinishedAt: optional(o.FinishedAt),
+			Service:    ptr(o.ServiceID),
+			Account:    optional(o.Account),
+			Href:       ptr(s.URL + "/1/orders/" + o.ID),
+		}
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+func (s *Server) createOrder(w http.ResponseWriter, r *http.Request) {
+	var req orderapi.CreateRequestBody
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	o := &Order{ServiceID: req.Service, Tags: req.Tags, Parameters: map[string]string{}}
+	if req.Name != nil {
+		o.Name = *req.Name
+	}
+	for _, p := range req.Parameters {
+		if p.Name != nil && p.Value != nil {
+			o.Parameters[*p.Name] = *p.Value
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.service(o.ServiceID); !ok {
+		replyError(w, http.StatusNotFound, "service '%s' not found", o.ServiceID)
+		return
+	}
+	s.addOrder(o)
+	body := orderapi.CreateResponseBody(*s.orderReply(o))
+	replyJSON(w, http.StatusOK, &body)
+}
+
+func (s *Server) readOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.order(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, s.orderReply(o))
+}
+
+// orderLogs returns the order's log lines, ignoring the 'from' and 'to' parameters
+func (s *Server) orderLogs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	o, ok := s.order(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	for _, l := range o.Logs {
+		_, _ = fmt.Fprintln(w, l)
+	}
+}
+
+// orderTop doesn't track any resource usage and therefore always returns an empty list
+func (s *Server) orderTop(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.order(r.PathValue("id")); !ok {
+		replyError(w, http.StatusNotFound, "order not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, orderapi.TopResponseBody{})
+}
+
+/**** SECRETS ****/
+
+type Secret struct {
+	Name       string `json:"secret-name"`
+	Type       string `json:"secret-type,omitempty"`
+	Value      string `json:"secret-value"`
+	ExpiryTime int64  `json:"expiry-time,omitempty"`
+}
+
+// AddSecret stores `secret`, replacing any secret with the same name and type.
+func (s *Server) AddSecret(secret Secret) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addSecret(&secret)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addSecret(secret *Secret) {
+	s.secrets = slices.DeleteFunc(s.secrets, func(c *Secret) bool {
+		return c.Name == secret.Name && c.Type == secret.Type
+	})
+	s.secrets = append(s.secrets, secret)
+}
+
+// listSecrets supports a 'filter' on the secret's name
+func (s *Server) listSecrets(w http.ResponseWriter, r *http.Request) {
+	filter := r.URL.Query().Get("filter")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []*secretapi.SecretListItemResponseBody{}
+	for _, secret := range s.secrets {
+		if !strings.Contains(secret.Name, filter) {
+			continue
+		}
+		items = append(items, &secretapi.SecretListItemResponseBody{
+			SecretName: ptr(secret.Name),
+			ExpiryTime: ptr(secret.ExpiryTime),
+		})
+	}
+	reply := paginate(s, r, items)
+	replyJSON(w, http.StatusOK, &listReply[*secretapi.SecretListItemResponseBody]{Items: reply.Items, Links: reply.Links})
+}
+
+func (s *Server) getSecret(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name, typ := q.Get("secret-name"), q.Get("secret-type")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secret, ok := find(s.secrets, func(c *Secret) bool { return c.Name == name && c.Type == typ })
+	if !ok {
+		replyError(w, http.StatusNotFound, "secret not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, &secretapi.GetResponseBody{
+		SecretName:  ptr(secret.Name),
+		SecretValue: ptr(secret.Value),
+		ExpiryTime:  ptr(secret.ExpiryTime),
+	})
+}
+
+func (s *Server) setSecret(w http.ResponseWriter, r *http.Request) {
+	var req secretapi.SetRequestBody
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	if req.SecretName == "" {
+		replyError(w, http.StatusBadRequest, "missing 'secret-name'")
+		return
+	}
+	secret := &Secret{Name: req.SecretName, Value: req.SecretValue, ExpiryTime: req.ExpiryTime}
+	if req.SecretType != nil {
+		secret.Type = *req.SecretType
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.addSecret(secret)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+/**** PACKAGES ****/
+
+// AddPackage registers the docker image `tag`, e.g. 'my-service:1.0'.
+func (s *Server) AddPackage(tag string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.packages, tag) {
+		s.packages = append(s.packages, tag)
+	}
+}
+
+// listPackages returns all tags of the repository referenced by the 'tag' parameter
+func (s *Server) listPackages(w http.ResponseWriter, r *http.Request) {
+	repo := repository(r.URL.Query().Get("tag"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []string{}
+	for _, p := range s.packages {
+		if repo == "" || repository(p) == repo {
+			items = append(items, p)
+		}
+	}
+	replyJSON(w, http.StatusOK, &pkgapi.ListResponseBody{Items: items})
+}
+
+func (s *Server) removePackage(w http.ResponseWriter, r *http.Request) {
+	tag := r.URL.Query().Get("tag")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !slices.Contains(s.packages, tag) {
+		replyError(w, http.StatusNotFound, "package '%s' not found", tag)
+		return
+	}
+	s.packages = slices.DeleteFunc(s.packages, func(p string) bool { return p == tag })
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// repository strips the tag from an image reference
+func repository(image string) string {
+	if idx := strings.LastIndex(image, ":"); idx > strings.LastIndex(image, "/") {
+		return image[:idx]
+	}
+	return image
+}
+
+/**** AUTH INFO ****/
+
+// AuthInfo is served as '/1/authinfo.yaml'. The fake doesn't implement
+// any of the OAuth endpoints itself, but they can be pointed at another
+// test server.
+type AuthInfo struct {
+	DefaultProviderID string                  `json:"default-provider-id" yaml:"default-provider-id"`
+	Providers         map[string]AuthProvider `json:"providers" yaml:"providers"`
+}
+
+type AuthProvider struct {
+	ID       string `json:"id,omitempty" yaml:"id,omitempty"`
+	LoginURL string `json:"login-url" yaml:"login-url"`
+	TokenURL string `json:"token-url" yaml:"token-url"`
+	CodeURL  string `json:"code-url" yaml:"code-url"`
+	JwksURL  string `json:"jwks-url" yaml:"jwks-url"`
+	ClientID string `json:"client-id" yaml:"client-id"`
+	Audience string `json:"audience" yaml:"audience"`
+}
+
+// SetAuthInfo replaces the content of '/1/authinfo.yaml'.
+func (s *Server) SetAuthInfo(info AuthInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authInfo = info
+}
+
+func (s *Server) getAuthInfo(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	data, err := yaml.Marshal(s.authInfo)
+	s.mu.Unlock()
+	if err != nil {
+		replyError(w, http.StatusInternalServerError, "%v", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ivcaptest provides an in-process fake IVCAP deployment for
+// testing code built on top of the CLI's SDK (package `pkg`) without
+// access to a real cluster.
+//
+//	srv := ivcaptest.NewServer(ivcaptest.WithFixtures(fixtures))
+//	defer srv.Close()
+//	list, _, err := sdk.ListAspect(ctx, selector, srv.Adapter(), logger)
+//
+// All state is held in memory and can be seeded from fixtures (see
+// `Fixtures`). The fake implements the endpoints the CLI calls, with just
+// enough semantics to make round trips (create, list, read, upload, ...)
+// behave plausibly. It is not a reference implementation of the API.
+package ivcaptest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ivcap-works/ivcap-cli/pkg/adapter"
+)
+
+// DefaultPageSize is used by list endpoints if the request doesn't specify a limit
+const DefaultPageSize = 10
+
+// Server is a fake IVCAP deployment listening on a local address.
+type Server struct {
+	*httptest.Server
+
+	// AccessToken, if set, needs to be presented as bearer token on every
+	// request. Requests without it are rejected with 401.
+	AccessToken string
+	// JobHandler decides the fate of every newly created job
+	JobHandler JobHandler
+
+	mu        sync.Mutex
+	seq       int
+	changed   chan struct{} // closed and replaced whenever a job changes
+	closing   chan struct{}
+	artifacts []*Artifact
+	aspects   []*Aspect
+	services  []*Service
+	jobs      []*Job
+	queues    []*Queue
+	orders    []*Order
+	secrets   []*Secret
+	packages  []string
+	authInfo  AuthInfo
+}
+
+type Option func(s *Server)
+
+// WithAccessToken requires all requests to carry `token`.
+func WithAccessToken(token string) Option {
+	return func(s *Server) {
+		s.AccessToken = token
+	}
+}
+
+// WithJobHandler replaces the DefaultJobHandler.
+func WithJobHandler(handler JobHandler) Option {
+	return func(s *Server) {
+		s.JobHandler = handler
+	}
+}
+
+// WithFixtures seeds the server with the content of `f`.
+func WithFixtures(f *Fixtures) Option {
+	return func(s *Server) {
+		s.Seed(f)
+	}
+}
+
+// NewServer starts a new fake deployment. It needs to be closed by the caller.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		JobHandler: DefaultJobHandler,
+		changed:    make(chan struct{}),
+		closing:    make(chan struct{}),
+	}
+	s.Server = httptest.NewServer(s.routes())
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Close ends all open event streams and shuts the server down.
+func (s *Server) Close() {
+	close(s.closing)
+	s.Server.Close()
+}
+
+// Adapter returns an adapter connected to this server, ready to be passed
+// to the SDK functions.
+func (s *Server) Adapter(opts ...adapter.Option) *adapter.Adapter {
+	connCtxt := &adapter.ConnectionCtxt{
+		URL:         s.URL,
+		AccessToken: s.AccessToken,
+		TimeoutSec:  10,
+	}
+	opts = append([]adapter.Option{adapter.WithConnContext(connCtxt)}, opts...)
+	adpt := adapter.RestAdapter(opts...)
+	return &adpt
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /1/authinfo.yaml", s.getAuthInfo)
+
+	mux.HandleFunc("GET /1/artifacts", s.listArtifacts)
+	mux.HandleFunc("POST /1/artifacts", s.createArtifact)
+	mux.HandleFunc("GET /1/artifacts/{id}", s.readArtifact)
+	mux.HandleFunc("GET /1/artifacts/{id}/blob", s.downloadArtifact)
+	mux.HandleFunc("HEAD /1/artifacts/{id}/blob", s.uploadStatus)
+	mux.HandleFunc("PATCH /1/artifacts/{id}/blob", s.uploadChunk)
+	mux.HandleFunc("PUT /1/artifacts/{id}/.collections/{name}", s.addToCollection)
+	mux.HandleFunc("DELETE /1/artifacts/{id}/.collections/{name}", s.removeFromCollection)
+	mux.HandleFunc("PUT /1/artifacts/{id}/.metadata/{schema}", s.addArtifactMeta)
+
+	mux.HandleFunc("GET /1/aspects", s.listAspects)
+	mux.HandleFunc("POST /1/aspects", s.createAspect)
+	mux.HandleFunc("PUT /1/aspects", s.updateAspect)
+	mux.HandleFunc("GET /1/aspects/{id}", s.readAspect)
+	mux.HandleFunc("DELETE /1/aspects/{id}", s.retractAspect)
+
+	mux.HandleFunc("GET /1/services2", s.listServices)
+	mux.HandleFunc("POST /1/services2", s.createService)
+	mux.HandleFunc("GET /1/services2/{id}", s.readService)
+	mux.HandleFunc("PUT /1/services2/{id}", s.updateService)
+	mux.HandleFunc("GET /1/services2/{id}/jobs", s.listJobs)
+	mux.HandleFunc("POST /1/services2/{id}/jobs", s.createJob)
+	mux.HandleFunc("GET /1/services2/{id}/jobs/{job}", s.readJob)
+	mux.HandleFunc("GET /1/services2/{id}/jobs/{job}/events", s.jobEvents)
+
+	mux.HandleFunc("GET /1/queues", s.listQueues)
+	mux.HandleFunc("POST /1/queues", s.createQueue)
+	mux.HandleFunc("GET /1/queues/{id}", s.readQueue)
+	mux.HandleFunc("DELETE /1/queues/{id}", s.deleteQueue)
+	mux.HandleFunc("POST /1/queues/{id}/messages", s.enqueue)
+	mux.HandleFunc("GET /1/queues/{id}/messages", s.dequeue)
+
+	mux.HandleFunc("GET /1/orders", s.listOrders)
+	mux.HandleFunc("POST /1/orders", s.createOrder)
+	mux.HandleFunc("GET /1/orders/{id}", s.readOrder)
+	mux.HandleFunc("GET /1/orders/{id}/logs", s.orderLogs)
+	mux.HandleFunc("GET /1/orders/{id}/top", s.orderTop)
+
+	mux.HandleFunc("GET /1/secrets/list", s.listSecrets)
+	mux.HandleFunc("GET /1/secrets", s.getSecret)
+	mux.HandleFunc("POST /1/secrets", s.setSecret)
+
+	mux.HandleFunc("GET /1/packages/list", s.listPackages)
+	mux.HandleFunc("DELETE /1/packages/remove", s.removePackage)
+
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.AccessToken != "" && r.URL.Path != "/1/authinfo.yaml" &&
+			r.Header.Get("Authorization") != "Bearer "+s.AccessToken {
+			replyError(w, http.StatusUnauthorized, "missing or invalid access token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newID returns a deterministic URN for `kind`. Must be called while holding 's.mu'
+func (s *Server) newID(kind string) string {
+	s.seq++
+	return fmt.Sprintf("urn:ivcap:%s:00000000-0000-4000-8000-%012d", kind, s.seq)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) notifyChanged() {
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+/**** REPLY HELPERS ****/
+
+type link struct {
+	Rel  string `json:"rel"`
+	Type string `json:"type,omitempty"`
+	Href string `json:"href"`
+}
+
+type listReply[T any] struct {
+	Items  []T     `json:"items"`
+	AtTime string  `json:"at-time,omitempty"`
+	Links  []*link `json:"links,omitempty"`
+}
+
+// paginate returns the page of `items` selected by the 'limit' and 'page'
+// query parameters of `r`, together with 'self' and 'next' links.
+func paginate[T any](s *Server, r *http.Request, items []T) *listReply[T] {
+	q := r.URL.Query()
+	limit := DefaultPageSize
+	if l, err := strconv.Atoi(q.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	offset := 0
+	if p, err := strconv.Atoi(q.Get("page")); err == nil && p > 0 {
+		offset = min(p, len(items))
+	}
+	end := min(offset+limit, len(items))
+	reply := &listReply[T]{
+		Items:  items[offset:end],
+		AtTime: now(),
+		Links:  []*link{{Rel: "self", Href: s.URL + r.URL.RequestURI()}},
+	}
+	if end < len(items) {
+		q.Set("page", strconv.Itoa(end))
+		q.Set("limit", strconv.Itoa(limit))
+		next := url.URL{Path: r.URL.Path, RawQuery: q.Encode()}
+		reply.Links = append(reply.Links, &link{Rel: "next", Href: s.URL + next.String()})
+	}
+	return reply
+}
+
+func replyJSON(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// replyError returns an error body shaped like the API's 'bad-request' responses
+func replyError(w http.ResponseWriter, statusCode int, format string, args ...any) {
+	replyJSON(w, statusCode, map[string]string{"message": fmt.Sprintf(format, args...)})
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		replyError(w, http.StatusBadRequest, "cannot parse request body - %v", err)
+		return false
+	}
+	return true
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+func find[T any](items []T, match func(T) bool) (T, bool) {
+	for _, i := range items {
+		if match(i) {
+			return i, true
+		}
+	}
+	var none T
+	return none, false
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// optional returns nil for empty strings so they get omitted in replies
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
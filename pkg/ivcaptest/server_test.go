@@ -0,0 +1,133 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/r3labs/sse/v2"
+	log "go.uber.org/zap"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	"github.com/ivcap-works/ivcap-cli/pkg/ivcaptest"
+)
+
+const echoService = "urn:ivcap:service:00000000-0000-0000-0000-000000000001"
+
+func newServer(t *testing.T, opts ...ivcaptest.Option) *ivcaptest.Server {
+	f, err := ivcaptest.LoadFixtures("test_data/fixtures.json")
+	if err != nil {
+		t.Fatalf("loading fixtures - %v", err)
+	}
+	srv := ivcaptest.NewServer(append([]ivcaptest.Option{ivcaptest.WithFixtures(f)}, opts...)...)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestListAspect_FiltersBySchemaPrefix(t *testing.T) {
+	srv := newServer(t)
+	selector := sdk.AspectSelector{
+		ListRequest:    sdk.ListRequest{Limit: 5},
+		SchemaPrefix:   "urn:sd-core:schema.ai-tool.",
+		IncludeContent: true,
+	}
+	list, _, err := sdk.ListAspect(context.Background(), selector, srv.Adapter(), log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(list.Items) != 1 || *list.Items[0].Entity != echoService {
+		t.Fatalf("expected the echo tool aspect, got %d items", len(list.Items))
+	}
+	if c, ok := list.Items[0].Content.(map[string]any); !ok || c["name"] != "echo" {
+		t.Fatalf("expected aspect content, got %v", list.Items[0].Content)
+	}
+}
+
+func TestCreateServiceJob_ReadAndStreamEvents(t *testing.T) {
+	srv := newServer(t)
+	adpt := srv.Adapter()
+	ctxt := context.Background()
+
+	pyld, err := a.LoadPayloadFromBytes([]byte(`{"msg":"hi"}`), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, jobCreate, err := sdk.CreateServiceJobRaw(ctxt, echoService, pyld, 0, adpt, log.NewNop())
+	if err != nil || jobCreate == nil {
+		t.Fatalf("expected job to be accepted - %v", err)
+	}
+	job, _, err := sdk.ReadServiceJob(ctxt, &sdk.ReadServiceJobRequest{ServiceId: echoService, JobId: jobCreate.JobID}, adpt, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if *job.Status != ivcaptest.JobSucceeded {
+		t.Fatalf("expected job to have succeeded, got '%s'", *job.Status)
+	}
+	if r, _ := json.Marshal(job.ResultContent); string(r) != `{"msg":"hi"}` {
+		t.Fatalf("expected request to be echoed, got '%s'", r)
+	}
+
+	var seen []string
+	onEvent := func(ev *sse.Event) { seen = append(seen, string(ev.ID)) }
+	if err = sdk.GetJobEvents(ctxt, echoService, jobCreate.JobID, nil, onEvent, adpt, log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(seen) != 2 {
+		t.Fatalf("expected two events, got %v", seen)
+	}
+	last := "1"
+	seen = nil
+	if err = sdk.GetJobEvents(ctxt, echoService, jobCreate.JobID, &last, onEvent, adpt, log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if len(seen) != 1 || seen[0] != "2" {
+		t.Fatalf("expected only events after '1', got %v", seen)
+	}
+}
+
+func TestUploadArtifact_Chunked(t *testing.T) {
+	srv := newServer(t, ivcaptest.WithAccessToken("secret"))
+	adpt := srv.Adapter()
+	ctxt := context.Background()
+	data := bytes.Repeat([]byte("0123456789"), 10)
+
+	req := &sdk.CreateArtifactRequest{Name: "numbers.txt"}
+	artifact, err := sdk.CreateArtifact(ctxt, req, "text/plain", int64(len(data)), nil, adpt, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	path, err := (*adpt).GetPath(*artifact.DataHref)
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	err = sdk.UploadArtifact(ctxt, bytes.NewReader(data), int64(len(data)), 0, 30, path, adpt, true, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	read, err := sdk.ReadArtifact(ctxt, &sdk.ReadArtifactRequest{Id: *artifact.ID}, adpt, log.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if *read.Status != ivcaptest.ArtifactReady || *read.Name != "numbers.txt" {
+		t.Fatalf("unexpected artifact state '%s'", *read.Status)
+	}
+	if stored, _ := srv.ArtifactData(*artifact.ID); !bytes.Equal(stored, data) {
+		t.Fatalf("uploaded data doesn't match")
+	}
+}
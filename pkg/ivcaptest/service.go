@@ -0,0 +1,161 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ivcaptest
+
+import (
+	"net/http"
+	"strings"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+)
+
+// Service is a stored service definition. It is returned as-is by the
+// read endpoint as its fields mirror `sdk.ServiceReadResponseBody`.
+type Service struct {
+	ID               string               `json:"id,omitempty"`
+	Name             string               `json:"name,omitempty"`
+	Description      string               `json:"description,omitempty"`
+	Tags             []string             `json:"tags,omitempty"`
+	Status           string               `json:"status,omitempty"`
+	ControllerSchema string               `json:"controller-schema,omitempty"`
+	Controller       any                  `json:"controller,omitempty"`
+	Policy           string               `json:"policy,omitempty"`
+	Account          string               `json:"account,omitempty"`
+	Parameters       []*sdk.ParameterDefT `json:"parameters,omitempty"`
+	ValidFrom        string               `json:"valid-from,omitempty"`
+}
+
+// AddService stores `svc` and returns its ID.
+func (s *Server) AddService(svc Service) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addService(&svc)
+}
+
+// must be called while holding 's.mu'
+func (s *Server) addService(svc *Service) string {
+	if svc.ID == "" {
+		svc.ID = s.newID("service")
+	}
+	if svc.Status == "" {
+		svc.Status = "active"
+	}
+	if svc.ValidFrom == "" {
+		svc.ValidFrom = now()
+	}
+	s.services = append(s.services, svc)
+	return svc.ID
+}
+
+// must be called while holding 's.mu'
+func (s *Server) service(id string) (*Service, bool) {
+	return find(s.services, func(svc *Service) bool { return svc.ID == id })
+}
+
+// listServices supports a case insensitive 'search' on name and description
+func (s *Server) listServices(w http.ResponseWriter, r *http.Request) {
+	search := strings.ToLower(r.URL.Query().Get("search"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := []*sdk.ServiceListItemTResponseBody{}
+	for _, svc := range s.services {
+		if search != "" &&
+			!strings.Contains(strings.ToLower(svc.Name), search) &&
+			!strings.Contains(strings.ToLower(svc.Description), search) {
+			continue
+		}
+		items = append(items, &sdk.ServiceListItemTResponseBody{
+			ID:               ptr(svc.ID),
+			Name:             optional(svc.Name),
+			Description:      optional(svc.Description),
+			Tags:             svc.Tags,
+			ControllerSchema: optional(svc.ControllerSchema),
+			ValidFrom:        optional(svc.ValidFrom),
+			Href:             ptr(s.URL + "/1/services2/" + svc.ID),
+		})
+	}
+	replyJSON(w, http.StatusOK, paginate(s, r, items))
+}
+
+func (s *Server) createService(w http.ResponseWriter, r *http.Request) {
+	var req sdk.ServiceCreateRequestBody
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.service(req.ID); exists {
+		replyError(w, http.StatusConflict, "service '%s' already exists", req.ID)
+		return
+	}
+	svc := serviceFromRequest(sdk.ServiceUpdateRequestBody(req))
+	s.addService(svc)
+	replyJSON(w, http.StatusCreated, svc)
+}
+
+// updateService replaces an existing service, or creates it if
+// 'force-create' is set.
+func (s *Server) updateService(w http.ResponseWriter, r *http.Request) {
+	var req sdk.ServiceUpdateRequestBody
+	if !decodeBody(w, r, &req) {
+		return
+	}
+	req.ID = r.PathValue("id")
+	svc := serviceFromRequest(req)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, ok := s.service(req.ID); ok {
+		svc.Status = current.Status
+		svc.ValidFrom = current.ValidFrom
+		*current = *svc
+		replyJSON(w, http.StatusOK, current)
+		return
+	}
+	if r.URL.Query().Get("force-create") != "true" {
+		replyError(w, http.StatusNotFound, "service not found")
+		return
+	}
+	s.addService(svc)
+	replyJSON(w, http.StatusOK, svc)
+}
+
+func serviceFromRequest(req sdk.ServiceUpdateRequestBody) *Service {
+	svc := &Service{
+		ID:               req.ID,
+		Description:      req.Description,
+		Tags:             req.Tags,
+		ControllerSchema: req.ControllerSchema,
+		Controller:       req.Controller,
+		Policy:           req.Policy,
+		Parameters:       req.Parameters,
+	}
+	if req.Name != nil {
+		svc.Name = *req.Name
+	}
+	return svc
+}
+
+func (s *Server) readService(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	svc, ok := s.service(r.PathValue("id"))
+	if !ok {
+		replyError(w, http.StatusNotFound, "service not found")
+		return
+	}
+	replyJSON(w, http.StatusOK, svc)
+}
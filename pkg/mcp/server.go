@@ -536,7 +536,11 @@ func waitForServiceJob(
 	wait := 2
 
 	for tries := 0; tries < maxChecks; tries++ {
-		time.Sleep(time.Duration(wait) * time.Second)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Duration(wait) * time.Second):
+		}
 		job, pyld, err := sdk.ReadServiceJob(ctx, &sdk.ReadServiceJobRequest{ServiceId: serviceID, JobId: jobID}, adpt, srvCfg.Logger)
 		if err != nil {
 			if isAuthFailure(err) {
@@ -572,6 +576,10 @@ func isAuthFailure(err error) bool {
 	if errors.As(err, &unauth) {
 		return true
 	}
+	var tokenErr *a.TokenSourceError
+	if errors.As(err, &tokenErr) {
+		return true
+	}
 	var apiErr *a.ApiError
 	if errors.As(err, &apiErr) {
 		return apiErr.StatusCode == 401 || apiErr.StatusCode == 403
@@ -98,6 +98,35 @@ type ToolHeader struct {
 	FnSchema map[string]any `yaml:"fn-schema" json:"fn-schema"`
 }
 
+// UnmarshalYAML converts the nested maps of 'fn-schema' to string keyed maps,
+// as yaml.v2 decodes them into map[any]any which is not a valid JSON schema.
+func (h *ToolHeader) UnmarshalYAML(unmarshal func(any) error) error {
+	type plain ToolHeader
+	if err := unmarshal((*plain)(h)); err != nil {
+		return err
+	}
+	for k, v := range h.FnSchema {
+		h.FnSchema[k] = stringKeys(v)
+	}
+	return nil
+}
+
+func stringKeys(v any) any {
+	switch t := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = stringKeys(e)
+		}
+		return m
+	case []any:
+		for i, e := range t {
+			t[i] = stringKeys(e)
+		}
+	}
+	return v
+}
+
 // --- ivcap.yaml (simplified) parsing ------------------------------------------------
 
 type SimpleToolHeader struct {
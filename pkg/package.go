@@ -164,7 +164,12 @@ func PullPackage(ctxt context.Context, tag string, adpt adapter.Adapter, logger
 }
 
 func getDockerRegistryHost(adpt adapter.Adapter) (string, error) {
-	apiSrvAddr := adpt.GetConnectionContext().URL
+	return DockerRegistryHost(adpt.GetConnectionContext().URL)
+}
+
+// DockerRegistryHost returns the host of the docker registry packages are
+// pushed to and pulled from for the deployment at `apiSrvAddr`.
+func DockerRegistryHost(apiSrvAddr string) (string, error) {
 	u, err := url.Parse(apiSrvAddr)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse URL: %s, %w", apiSrvAddr, err)
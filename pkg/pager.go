@@ -0,0 +1,117 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+
+	"github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	log "go.uber.org/zap"
+)
+
+// Page is a single page of a list reply, decoded into `L` (e.g. an
+// 'api.ListResponseBody').
+type Page[L any] struct {
+	List    *L
+	Payload adapter.Payload
+	// Next is the 'next' link of this page, or "" if it is the last one
+	Next string
+}
+
+// PageFetcher returns the first page of a list, e.g.
+//
+//	func(ctxt context.Context) (adapter.Payload, error) {
+//		return ListArtifactsRaw(ctxt, req, adpt, logger)
+//	}
+type PageFetcher func(ctxt context.Context) (adapter.Payload, error)
+
+// ListPages iterates over all pages of a list. It starts with the page
+// returned by `first` (which applies the `ListRequest` or `AspectSelector`)
+// and then follows each page's 'next' link until there is none left.
+// Only one page is held at a time. Iteration stops after the first error.
+//
+//	for page, err := range ListPages[api.ListResponseBody](ctxt, first, adpt, logger) {
+//		...
+//	}
+func ListPages[L any](
+	ctxt context.Context,
+	first PageFetcher,
+	adpt *adapter.Adapter,
+	logger *log.Logger,
+) iter.Seq2[*Page[L], error] {
+	return func(yield func(*Page[L], error) bool) {
+		pyld, err := first(ctxt)
+		for {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			page, err := toPage[L](pyld)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !yield(page, nil) || page.Next == "" {
+				return
+			}
+			path, err := nextPagePath(page.Next)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			pyld, err = (*adpt).Get(ctxt, path, logger)
+		}
+	}
+}
+
+// NextPage returns the 'next' link of a list reply, or "" if there is none.
+func NextPage(pyld adapter.Payload) string {
+	var reply struct {
+		Links []struct {
+			Rel  string `json:"rel"`
+			Href string `json:"href"`
+		} `json:"links"`
+	}
+	if err := pyld.AsType(&reply); err != nil {
+		return ""
+	}
+	for _, l := range reply.Links {
+		if l.Rel == "next" {
+			return l.Href
+		}
+	}
+	return ""
+}
+
+func toPage[L any](pyld adapter.Payload) (*Page[L], error) {
+	var list L
+	if err := pyld.AsType(&list); err != nil {
+		return nil, fmt.Errorf("failed to parse list response body: %w", err)
+	}
+	return &Page[L]{List: &list, Payload: pyld, Next: NextPage(pyld)}, nil
+}
+
+// nextPagePath drops scheme and host from a 'next' link, so it is fetched
+// through the adapter's deployment URL (and Host header) like the first page.
+func nextPagePath(next string) (string, error) {
+	u, err := url.Parse(next)
+	if err != nil {
+		return "", fmt.Errorf("cannot parse 'next' link '%s': %w", next, err)
+	}
+	return u.RequestURI(), nil
+}
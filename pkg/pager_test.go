@@ -0,0 +1,80 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	api "github.com/ivcap-works/ivcap-core-api/http/artifact"
+	log "go.uber.org/zap"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+	"github.com/ivcap-works/ivcap-cli/pkg/ivcaptest"
+)
+
+func listArtifacts(adpt *a.Adapter, limit int) sdk.PageFetcher {
+	return func(ctxt context.Context) (a.Payload, error) {
+		return sdk.ListArtifactsRaw(ctxt, &sdk.ListRequest{Limit: limit}, adpt, log.NewNop())
+	}
+}
+
+func TestListPages_FollowsNextLinks(t *testing.T) {
+	srv := ivcaptest.NewServer()
+	t.Cleanup(srv.Close)
+	for i := range 7 {
+		srv.AddArtifact(ivcaptest.Artifact{Name: fmt.Sprintf("a%d", i), Data: []byte("x")})
+	}
+	adpt := srv.Adapter()
+
+	var pages, items int
+	for page, err := range sdk.ListPages[api.ListResponseBody](context.Background(), listArtifacts(adpt, 3), adpt, log.NewNop()) {
+		if err != nil {
+			t.Fatalf("unexpected error - %v", err)
+		}
+		pages++
+		items += len(page.List.Items)
+		if (page.Next == "") != (pages == 3) {
+			t.Fatalf("unexpected 'next' link '%s' on page %d", page.Next, pages)
+		}
+	}
+	if pages != 3 || items != 7 {
+		t.Fatalf("expected 7 items on 3 pages, got %d on %d", items, pages)
+	}
+}
+
+func TestListPages_StopsEarly(t *testing.T) {
+	srv := ivcaptest.NewServer()
+	t.Cleanup(srv.Close)
+	for i := range 4 {
+		srv.AddArtifact(ivcaptest.Artifact{Name: fmt.Sprintf("a%d", i), Data: []byte("x")})
+	}
+	adpt := srv.Adapter()
+
+	pages := 0
+	for _, err := range sdk.ListPages[api.ListResponseBody](context.Background(), listArtifacts(adpt, 1), adpt, log.NewNop()) {
+		if err != nil {
+			t.Fatalf("unexpected error - %v", err)
+		}
+		if pages++; pages == 2 {
+			break
+		}
+	}
+	if pages != 2 {
+		t.Fatalf("expected to stop after 2 pages, got %d", pages)
+	}
+}
@@ -0,0 +1,441 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package printer renders API replies in the formats selected with
+// '--output' and optionally filters them with a jq-style '--query' first.
+//
+// Replies are decoded into plain JSON values (maps, slices, strings, ...).
+// Record based formats ('ndjson', 'csv', 'tsv', 'ids') print one line per
+// record, where the records of a list reply are its 'items'.
+package printer
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/itchyny/gojq"
+	"github.com/ohler55/ojg/jp"
+	yaml "gopkg.in/yaml.v2"
+)
+
+const (
+	Table      = ""
+	Wide       = "wide"
+	JSON       = "json"
+	YAML       = "yaml"
+	NDJSON     = "ndjson"
+	CSV        = "csv"
+	TSV        = "tsv"
+	IDs        = "ids"
+	GoTemplate = "go-template"
+	JSONPath   = "jsonpath"
+
+	// TableName selects the table explicitly, e.g. to override a default
+	// output format set elsewhere
+	TableName = "table"
+)
+
+// Formats lists all supported output formats
+var Formats = []string{TableName, JSON, YAML, NDJSON, CSV, TSV, IDs, Wide, GoTemplate + "=...", JSONPath + "=..."}
+
+// IDFunc returns the ID of a record for the 'ids' format
+type IDFunc func(record any) string
+
+// Printer prints replies in a single output format. A printer is created
+// once per command. Lists which are fetched page by page are printed with
+// `Stream` followed by `End`.
+type Printer struct {
+	// Format is the name of the output format, without any argument
+	Format string
+	// IDFunc overrides how the 'ids' format finds a record's ID. The
+	// default is the record's 'id' field.
+	IDFunc IDFunc
+
+	query    *gojq.Code
+	tmpl     *template.Template
+	path     jp.Expr
+	columns  []string
+	streamed int
+}
+
+// New returns a printer for `output` (e.g. 'json' or 'go-template={{.id}}'),
+// applying the jq expression `query` to every reply, if not empty.
+func New(output string, query string) (*Printer, error) {
+	if output == TableName {
+		output = Table
+	}
+	format, arg, hasArg := strings.Cut(output, "=")
+	p := &Printer{Format: format}
+	switch format {
+	case Table, Wide, JSON, YAML, NDJSON, CSV, TSV, IDs:
+		if hasArg {
+			return nil, fmt.Errorf("output format '%s' doesn't take an argument", format)
+		}
+	case GoTemplate, JSONPath:
+		if arg == "" {
+			return nil, fmt.Errorf("output format '%s' requires an argument, e.g. '%s=...'", format, format)
+		}
+	default:
+		return nil, fmt.Errorf("unknown output format '%s', expected one of %s", output, strings.Join(Formats, ", "))
+	}
+	switch format {
+	case GoTemplate:
+		t, err := template.New("output").Funcs(template.FuncMap{"json": toJSON}).Parse(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid go-template: %w", err)
+		}
+		p.tmpl = t
+	case JSONPath:
+		x, err := jp.ParseString(jsonPathExpr(arg))
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonpath: %w", err)
+		}
+		p.path = x
+	}
+	if query != "" {
+		q, err := gojq.Parse(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+		if p.query, err = gojq.Compile(q); err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// IsTable returns true if the command should print its own table
+// ('--output' not set, or 'wide') and there is no query to apply.
+func (p *Printer) IsTable() bool {
+	return (p.Format == Table || p.Format == Wide) && p.query == nil
+}
+
+// IsWide returns true if tables should show all columns
+func (p *Printer) IsWide() bool {
+	return p.Format == Wide
+}
+
+// Print prints `reply`, which is either a JSON value or a JSON
+// encoded `[]byte`.
+func (p *Printer) Print(w io.Writer, reply any) error {
+	v, err := p.apply(reply)
+	if err != nil {
+		return err
+	}
+	switch p.Format {
+	case JSON, Table, Wide:
+		return printJSON(w, v, true)
+	case YAML:
+		return printYAML(w, v)
+	case GoTemplate, JSONPath:
+		return p.printTemplate(w, v)
+	}
+	if err = p.printRecords(w, records(v)); err != nil {
+		return err
+	}
+	p.columns = nil
+	return nil
+}
+
+// Stream prints one page of a list. Unlike `Print`, the 'json' and 'yaml'
+// formats combine the records of all pages into a single array, which is
+// closed by `End`. Templates and jsonpath expressions are applied to each
+// page.
+func (p *Printer) Stream(w io.Writer, page any) error {
+	v, err := p.apply(page)
+	if err != nil {
+		return err
+	}
+	switch p.Format {
+	case GoTemplate, JSONPath:
+		return p.printTemplate(w, v)
+	}
+	return p.printRecords(w, records(v))
+}
+
+// End finishes a list printed with `Stream`
+func (p *Printer) End(w io.Writer) error {
+	if p.Format != JSON && p.Format != Table && p.Format != Wide {
+		return nil
+	}
+	var err error
+	if p.streamed == 0 {
+		_, err = fmt.Fprintln(w, "[]")
+	} else {
+		_, err = fmt.Fprintln(w, "\n]")
+	}
+	p.streamed = 0
+	return err
+}
+
+// apply decodes `reply` into a JSON value and runs the query on it. A
+// query returning more than one value yields an array of them.
+func (p *Printer) apply(reply any) (any, error) {
+	v, err := decode(reply)
+	if err != nil || p.query == nil {
+		return v, err
+	}
+	var results []any
+	iter := p.query.RunWithContext(context.Background(), v)
+	for {
+		r, ok := iter.Next()
+		if !ok {
+			break
+		}
+		if err, ok := r.(error); ok {
+			if herr, ok := err.(*gojq.HaltError); ok && herr.Value() == nil {
+				break
+			}
+			return nil, fmt.Errorf("query failed: %w", err)
+		}
+		results = append(results, r)
+	}
+	if len(results) == 1 {
+		return results[0], nil
+	}
+	if results == nil {
+		results = []any{}
+	}
+	return results, nil
+}
+
+func (p *Printer) printRecords(w io.Writer, recs []any) error {
+	switch p.Format {
+	case JSON, Table, Wide:
+		for _, r := range recs {
+			s, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				return err
+			}
+			sep := ",\n"
+			if p.streamed == 0 {
+				sep = "[\n"
+			}
+			if _, err = fmt.Fprint(w, sep+string(s)); err != nil {
+				return err
+			}
+			p.streamed++
+		}
+		return nil
+	case YAML:
+		if len(recs) == 0 {
+			return nil
+		}
+		return printYAML(w, recs)
+	case NDJSON:
+		for _, r := range recs {
+			if err := printJSON(w, r, false); err != nil {
+				return err
+			}
+		}
+		return nil
+	case IDs:
+		idf := p.IDFunc
+		if idf == nil {
+			idf = DefaultID
+		}
+		for _, r := range recs {
+			if id := idf(r); id != "" {
+				if _, err := fmt.Fprintln(w, id); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	case CSV, TSV:
+		return p.printCSV(w, recs)
+	}
+	return fmt.Errorf("unsupported output format '%s'", p.Format)
+}
+
+// printCSV prints `recs` as rows. The columns are the sorted fields of the
+// first page of records ('id' first), and the header is only written once.
+// Nested values are printed as JSON.
+func (p *Printer) printCSV(w io.Writer, recs []any) error {
+	cw := csv.NewWriter(w)
+	if p.Format == TSV {
+		cw.Comma = '\t'
+	}
+	if p.columns == nil {
+		p.columns = columns(recs)
+		if err := cw.Write(p.columns); err != nil {
+			return err
+		}
+	}
+	for _, r := range recs {
+		row := make([]string, len(p.columns))
+		obj, ok := r.(map[string]any)
+		if !ok {
+			obj = map[string]any{"value": r}
+		}
+		for i, c := range p.columns {
+			row[i] = cell(obj[c])
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (p *Printer) printTemplate(w io.Writer, v any) error {
+	if p.tmpl != nil {
+		if err := p.tmpl.Execute(w, v); err != nil {
+			return fmt.Errorf("executing go-template: %w", err)
+		}
+		return nil
+	}
+	for _, r := range p.path.Get(v) {
+		if _, err := fmt.Fprintln(w, cell(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultID returns the 'id' field of a record
+func DefaultID(record any) string {
+	if obj, ok := record.(map[string]any); ok {
+		if id, ok := obj["id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
+
+// records returns the 'items' of a list reply, the elements of an array,
+// or otherwise `v` itself as the only record.
+func records(v any) []any {
+	switch x := v.(type) {
+	case []any:
+		return x
+	case map[string]any:
+		if items, ok := x["items"].([]any); ok {
+			return items
+		}
+	}
+	return []any{v}
+}
+
+func columns(recs []any) []string {
+	var cols []string
+	for _, r := range recs {
+		obj, ok := r.(map[string]any)
+		if !ok {
+			obj = map[string]any{"value": r}
+		}
+		for k := range obj {
+			if !slices.Contains(cols, k) {
+				cols = append(cols, k)
+			}
+		}
+	}
+	slices.SortFunc(cols, func(a, b string) int {
+		switch {
+		case a == b:
+			return 0
+		case a == "id":
+			return -1
+		case b == "id":
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+	return cols
+}
+
+// cell prints scalars as is and anything else as compact JSON
+func cell(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	}
+	s, _ := toJSON(v)
+	return s
+}
+
+// jsonPathExpr accepts both '$.items[*].id' and kubectl's '{.items[*].id}'
+func jsonPathExpr(expr string) string {
+	expr = strings.TrimSpace(expr)
+	if strings.HasPrefix(expr, "{") && strings.HasSuffix(expr, "}") {
+		expr = expr[1 : len(expr)-1]
+	}
+	if !strings.HasPrefix(expr, "$") {
+		expr = "$" + expr
+	}
+	return expr
+}
+
+func decode(reply any) (any, error) {
+	var b []byte
+	switch x := reply.(type) {
+	case []byte:
+		b = x
+	case json.RawMessage:
+		b = x
+	default:
+		var err error
+		if b, err = json.Marshal(reply); err != nil {
+			return nil, err
+		}
+	}
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, fmt.Errorf("cannot decode reply: %w", err)
+	}
+	return v, nil
+}
+
+func toJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func printJSON(w io.Writer, v any, indent bool) error {
+	var b []byte
+	var err error
+	if indent {
+		b, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		b, err = json.Marshal(v)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(b))
+	return err
+}
+
+func printYAML(w io.Writer, v any) error {
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprint(w, string(b))
+	return err
+}
@@ -0,0 +1,123 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+const listReply = `{
+	"items": [
+		{"id": "urn:ivcap:artifact:1", "name": "one", "size": 10000000, "tags": ["a"]},
+		{"id": "urn:ivcap:artifact:2", "name": "two, too", "status": "ready"}
+	],
+	"links": [{"rel": "next", "href": "https://example.com/1/artifacts?page=abc"}]
+}`
+
+func printed(t *testing.T, output, query string) string {
+	t.Helper()
+	p, err := New(output, query)
+	if err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	var w bytes.Buffer
+	if err = p.Print(&w, []byte(listReply)); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	return w.String()
+}
+
+func TestPrint_Formats(t *testing.T) {
+	cases := []struct {
+		output string
+		query  string
+		expect string
+	}{
+		{"ndjson", "", `{"id":"urn:ivcap:artifact:1","name":"one","size":10000000,"tags":["a"]}` + "\n" +
+			`{"id":"urn:ivcap:artifact:2","name":"two, too","status":"ready"}` + "\n"},
+		{"csv", "", "id,name,size,status,tags\n" +
+			"urn:ivcap:artifact:1,one,10000000,,\"[\"\"a\"\"]\"\n" +
+			"urn:ivcap:artifact:2,\"two, too\",,ready,\n"},
+		{"tsv", ".items[] | {id, name}", "id\tname\n" +
+			"urn:ivcap:artifact:1\tone\n" +
+			"urn:ivcap:artifact:2\ttwo, too\n"},
+		{"ids", "", "urn:ivcap:artifact:1\nurn:ivcap:artifact:2\n"},
+		{"go-template={{range .items}}{{.name}};{{end}}", "", "one;two, too;"},
+		{"jsonpath={.items[*].name}", "", "one\ntwo, too\n"},
+		{"jsonpath=$.links[0].rel", "", "next\n"},
+		{"json", ".items | length", "2\n"},
+	}
+	for _, c := range cases {
+		if s := printed(t, c.output, c.query); s != c.expect {
+			t.Errorf("'%s' (query '%s'): expected\n%s\ngot\n%s", c.output, c.query, c.expect, s)
+		}
+	}
+}
+
+func TestStream_JoinsPages(t *testing.T) {
+	p, err := New("json", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	for range 2 {
+		if err = p.Stream(&w, []byte(listReply)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = p.End(&w); err != nil {
+		t.Fatal(err)
+	}
+	var items []map[string]any
+	if err = json.Unmarshal(w.Bytes(), &items); err != nil || len(items) != 4 {
+		t.Fatalf("expected a JSON array of 4 items, got %d (%v):\n%s", len(items), err, w.String())
+	}
+}
+
+func TestStream_WritesCSVHeaderOnce(t *testing.T) {
+	p, err := New("csv", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	for range 2 {
+		if err = p.Stream(&w, []byte(listReply)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := bytes.Count(w.Bytes(), []byte("id,name")); n != 1 {
+		t.Fatalf("expected a single header row, got %d:\n%s", n, w.String())
+	}
+}
+
+func TestNew_RejectsInvalidFormats(t *testing.T) {
+	for _, o := range []string{"xml", "json=x", "go-template=", "go-template={{.id", "jsonpath=$[", "jsonpath"} {
+		if _, err := New(o, ""); err == nil {
+			t.Errorf("expected '%s' to be rejected", o)
+		}
+	}
+	if _, err := New("json", ".items["); err == nil {
+		t.Errorf("expected invalid query to be rejected")
+	}
+}
+
+func TestNew_TableName(t *testing.T) {
+	p, err := New(TableName, "")
+	if err != nil || !p.IsTable() {
+		t.Fatalf("expected '%s' to select the table, got %+v (%v)", TableName, p, err)
+	}
+}
@@ -16,10 +16,15 @@ package client
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/cenkalti/backoff/v4"
 	log "go.uber.org/zap"
 
 	"github.com/ivcap-works/ivcap-cli/pkg/adapter"
@@ -219,8 +224,111 @@ func GetJobEvents(ctxt context.Context, serviceId string, jobId string, lastEven
 	return (*adpt).GetSSE(ctxt, path, lastEventID, onEvent, nil, logger)
 }
 
+// IsJobTerminal returns true if a job with `status` will not change anymore.
+func IsJobTerminal(status *string) bool {
+	return status != nil && *status != "" && *status != "scheduled" && *status != "executing"
+}
+
+// StreamJobEvents calls `onEvent` for each event of job `jobId` after the
+// one with sequence ID `since` (nil for all events), until the job is
+// terminal. Dropped or closed streams are resumed from the last received
+// event, and events at or before the last one received (or `since`) are
+// skipped, in case the server replays them.
+func StreamJobEvents(
+	ctxt context.Context,
+	serviceId string,
+	jobId string,
+	since *string,
+	onEvent func(*JobEventsResponseBody),
+	adpt *adapter.Adapter,
+	logger *log.Logger,
+) error {
+	lastID := since
+	received := 0
+	// IDs received on the current connection, for servers whose sequence IDs
+	// can't be ordered. It is reset on every reconnect to not grow forever.
+	seen := map[string]bool{}
+	handler := func(msg *sse.Event) {
+		var ev JobEventsResponseBody
+		if err := json.Unmarshal(msg.Data, &ev); err != nil {
+			logger.Warn("cannot parse job event", log.ByteString("data", msg.Data), log.Error(err))
+			return
+		}
+		if ev.SeqID == nil && len(msg.ID) > 0 {
+			id := string(msg.ID)
+			ev.SeqID = &id
+		}
+		if ev.SeqID != nil {
+			if lastID != nil {
+				if c, ok := compareSeqIDs(*ev.SeqID, *lastID); (ok && c <= 0) || (!ok && *ev.SeqID == *lastID) {
+					return
+				}
+			}
+			if seen[*ev.SeqID] {
+				return
+			}
+			seen[*ev.SeqID] = true
+			lastID = ev.SeqID
+		}
+		received++
+		onEvent(&ev)
+	}
+	delays := backoff.WithContext(backoff.NewExponentialBackOff(backoff.WithMaxElapsedTime(0)), ctxt)
+	for {
+		before := received
+		seen = map[string]bool{}
+		// transient errors are already retried by the adapter
+		if err := GetJobEvents(ctxt, serviceId, jobId, lastID, handler, adpt, logger); err != nil {
+			return err
+		}
+		job, _, err := ReadServiceJob(ctxt, &ReadServiceJobRequest{ServiceId: serviceId, JobId: jobId}, adpt, logger)
+		if err != nil {
+			return err
+		}
+		if IsJobTerminal(job.Status) {
+			return nil
+		}
+		if received > before {
+			delays.Reset()
+		}
+		wait := delays.NextBackOff()
+		if wait == backoff.Stop {
+			return ctxt.Err()
+		}
+		logger.Debug("event stream closed, reconnecting", log.Stringp("last-event-id", lastID), log.Duration("wait", wait))
+		t := time.NewTimer(wait)
+		select {
+		case <-ctxt.Done():
+			t.Stop()
+			return ctxt.Err()
+		case <-t.C:
+		}
+	}
+}
+
 /**** UTILS ****/
 
+// compareSeqIDs compares the sequence IDs of two job events, which are
+// either numbers or '-' separated numbers (e.g. '1700000000000-3'). The
+// result is false if they can't be compared.
+func compareSeqIDs(a, b string) (int, bool) {
+	pa, pb := strings.Split(a, "-"), strings.Split(b, "-")
+	if len(pa) != len(pb) {
+		return 0, false
+	}
+	for i := range pa {
+		na, erra := strconv.ParseUint(pa[i], 10, 64)
+		nb, errb := strconv.ParseUint(pb[i], 10, 64)
+		if erra != nil || errb != nil {
+			return 0, false
+		}
+		if na != nb {
+			return cmp.Compare(na, nb), true
+		}
+	}
+	return 0, true
+}
+
 func servicePath(id *string) string {
 	path := "/1/services2"
 	if id != nil {
@@ -0,0 +1,131 @@
+// Copyright 2026 Commonwealth Scientific and Industrial Research Organisation (CSIRO) ABN 41 687 119 230
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	log "go.uber.org/zap"
+
+	sdk "github.com/ivcap-works/ivcap-cli/pkg"
+	a "github.com/ivcap-works/ivcap-cli/pkg/adapter"
+)
+
+func TestStreamJobEvents_ResumesClosedStream(t *testing.T) {
+	var lastEventIDs []string
+	connections := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/services2/s1/jobs/j1/events":
+			connections++
+			lastEventIDs = append(lastEventIDs, r.Header.Get("Last-Event-ID"))
+			w.Header().Set("Content-Type", "text/event-stream")
+			// the second connection replays the last event before continuing
+			for _, seq := range [][]string{{"1", "2"}, {"2", "3"}}[connections-1] {
+				fmt.Fprintf(w, "id: %s\ndata: {\"SeqID\":\"%s\",\"type\":\"step\"}\n\n", seq, seq)
+			}
+		case "/1/services2/s1/jobs/j1":
+			status := "executing"
+			if connections > 1 {
+				status = "succeeded"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"j1","status":"%s"}`, status)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	adpt := a.RestAdapter(a.WithConnContext(&a.ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}))
+
+	var seen []string
+	onEvent := func(ev *sdk.JobEventsResponseBody) { seen = append(seen, *ev.SeqID) }
+	if err := sdk.StreamJobEvents(context.Background(), "s1", "j1", nil, onEvent, &adpt, log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if !slices.Equal(seen, []string{"1", "2", "3"}) {
+		t.Errorf("expected each event once, got %v", seen)
+	}
+	if !slices.Equal(lastEventIDs, []string{"", "2"}) {
+		t.Errorf("expected stream to resume after the last event, got %v", lastEventIDs)
+	}
+}
+
+func TestStreamJobEvents_Since(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/services2/s1/jobs/j1/events":
+			if r.Header.Get("Last-Event-ID") != "1" {
+				t.Errorf("expected 'Last-Event-ID: 1', got '%s'", r.Header.Get("Last-Event-ID"))
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "id: 2\ndata: {\"SeqID\":\"2\"}\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"j1","status":"failed"}`)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	adpt := a.RestAdapter(a.WithConnContext(&a.ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}))
+
+	since := "1"
+	count := 0
+	onEvent := func(*sdk.JobEventsResponseBody) { count++ }
+	if err := sdk.StreamJobEvents(context.Background(), "s1", "j1", &since, onEvent, &adpt, log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if count != 1 {
+		t.Errorf("expected a single event, got %d", count)
+	}
+}
+
+func TestStreamJobEvents_ServerIgnoresLastEventID(t *testing.T) {
+	connections := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/1/services2/s1/jobs/j1/events":
+			connections++
+			w.Header().Set("Content-Type", "text/event-stream")
+			// always starts from the beginning
+			for seq := 1; seq <= connections+2; seq++ {
+				fmt.Fprintf(w, "id: %d\ndata: {\"SeqID\":\"%d\"}\n\n", seq, seq)
+			}
+		default:
+			status := "executing"
+			if connections > 1 {
+				status = "succeeded"
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"id":"j1","status":"%s"}`, status)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	adpt := a.RestAdapter(a.WithConnContext(&a.ConnectionCtxt{URL: srv.URL, TimeoutSec: 5}))
+
+	since := "1"
+	var seen []string
+	onEvent := func(ev *sdk.JobEventsResponseBody) { seen = append(seen, *ev.SeqID) }
+	if err := sdk.StreamJobEvents(context.Background(), "s1", "j1", &since, onEvent, &adpt, log.NewNop()); err != nil {
+		t.Fatalf("unexpected error - %v", err)
+	}
+	if !slices.Equal(seen, []string{"2", "3", "4"}) {
+		t.Errorf("expected only events after '1' and each once, got %v", seen)
+	}
+}